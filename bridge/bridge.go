@@ -0,0 +1,297 @@
+// Package bridge is an HTTP server that receives incident payloads pushed by a CAD system and upserts them into First
+// Due as NFIRS notifications.
+//
+// Each payload must be signed with HMAC-SHA256 using a secret shared with the sender.  The sender puts the current Unix
+// time in the timestamp header and the hex digest of the timestamp, a ".", and the body, optionally prefixed with
+// "sha256=", in the signature header; see Sign.  A payload whose timestamp is more than the tolerance away from the
+// server's clock is rejected, and so is a signature that has already been accepted within the tolerance, so a
+// captured request cannot be replayed.  The payload is converted into a notification by a mapping (see the mapping
+// package) and then created or updated by its dispatch number.
+//
+// The server has these endpoints:
+//
+//	POST /incidents   receive an incident payload
+//	GET  /healthz     the process is alive
+//	GET  /readyz      the First Due API is reachable with the configured credentials
+//	GET  /metrics     metrics in the Prometheus text format
+package bridge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+	"github.com/tekkamanendless/firstdue/mapping"
+)
+
+// Defaults for the configuration.
+const (
+	DefaultSignatureHeader   = "X-Signature-256"
+	DefaultTimestampHeader   = "X-Signature-Timestamp"
+	DefaultTolerance         = 5 * time.Minute
+	DefaultMaxBodyBytes      = 1 << 20
+	DefaultReadinessInterval = 15 * time.Second
+	DefaultUpsertTimeout     = 30 * time.Second
+)
+
+// Config configures a server.
+type Config struct {
+	Client            *firstdue.Client // The client used to upsert the notifications; required.
+	Mapping           *mapping.Mapping // The mapping from the payloads to the notifications; required.
+	Secret            []byte           // The HMAC-SHA256 key shared with the sender; required.
+	SignatureHeader   string           // The header with the signature; defaults to DefaultSignatureHeader.
+	TimestampHeader   string           // The header with the signing time; defaults to DefaultTimestampHeader.
+	Tolerance         time.Duration    // How far the signing time may be from the server's clock; defaults to DefaultTolerance.
+	MaxBodyBytes      int64            // The largest payload accepted; defaults to DefaultMaxBodyBytes.
+	ReadinessInterval time.Duration    // How long a readiness check is reused; defaults to DefaultReadinessInterval.
+	UpsertTimeout     time.Duration    // How long an upsert may take; defaults to DefaultUpsertTimeout.
+	Logger            *slog.Logger     // Where to log each payload; nil disables logging.
+}
+
+// Server is the bridge's HTTP handler.
+type Server struct {
+	config  Config
+	mux     *http.ServeMux
+	metrics *metrics
+
+	seenMutex sync.Mutex
+	seen      map[string]time.Time // The accepted signatures, by the time they can be forgotten.
+
+	readyMutex   sync.Mutex
+	readyChecked time.Time
+	readyErr     error
+}
+
+// Response is the body of the response to an incident payload.
+type Response struct {
+	DispatchNumber string   `json:"dispatch_number,omitempty"`
+	Result         string   `json:"result,omitempty"` // One of the firstdue.Upsert* constants.
+	Errors         []string `json:"errors,omitempty"`
+}
+
+// NewServer returns a new server.
+func NewServer(config Config) (*Server, error) {
+	if config.Client == nil {
+		return nil, fmt.Errorf("a client is required")
+	}
+	if config.Mapping == nil {
+		return nil, fmt.Errorf("a mapping is required")
+	}
+	if len(config.Secret) == 0 {
+		return nil, fmt.Errorf("a secret is required")
+	}
+	if config.SignatureHeader == "" {
+		config.SignatureHeader = DefaultSignatureHeader
+	}
+	if config.TimestampHeader == "" {
+		config.TimestampHeader = DefaultTimestampHeader
+	}
+	if config.Tolerance <= 0 {
+		config.Tolerance = DefaultTolerance
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if config.ReadinessInterval <= 0 {
+		config.ReadinessInterval = DefaultReadinessInterval
+	}
+	if config.UpsertTimeout <= 0 {
+		config.UpsertTimeout = DefaultUpsertTimeout
+	}
+
+	s := &Server{
+		config:  config,
+		mux:     http.NewServeMux(),
+		metrics: newMetrics(),
+		seen:    map[string]time.Time{},
+	}
+	s.mux.HandleFunc("POST /incidents", s.handleIncident)
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+	s.mux.HandleFunc("GET /readyz", s.handleReady)
+	s.mux.HandleFunc("GET /metrics", s.handleMetrics)
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Sign returns the signature header value for a payload signed at the given time, as a sender would compute it.
+//
+// The timestamp header must be set to the same time as Unix seconds, as in strconv.FormatInt(timestamp.Unix(), 10).
+func Sign(secret []byte, timestamp time.Time, payload []byte) string {
+	return "sha256=" + hex.EncodeToString(digest(secret, strconv.FormatInt(timestamp.Unix(), 10), payload))
+}
+
+// digest returns the HMAC-SHA256 of the timestamp, a ".", and the payload.
+func digest(secret []byte, timestamp string, payload []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// verify checks the signature and the timestamp of a payload and remembers the signature so that it cannot be
+// replayed.
+func (s *Server) verify(r *http.Request, payload []byte) error {
+	timestamp := strings.TrimSpace(r.Header.Get(s.config.TimestampHeader))
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid timestamp")
+	}
+	signature := strings.TrimPrefix(strings.TrimSpace(r.Header.Get(s.config.SignatureHeader)), "sha256=")
+	given, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(given, digest(s.config.Secret, timestamp, payload)) {
+		return fmt.Errorf("invalid signature")
+	}
+	signedAt := time.Unix(seconds, 0)
+	now := time.Now()
+	if signedAt.Before(now.Add(-s.config.Tolerance)) || signedAt.After(now.Add(s.config.Tolerance)) {
+		return fmt.Errorf("the timestamp is more than %s from the server's clock", s.config.Tolerance)
+	}
+
+	// A signature only needs to be remembered until its timestamp falls out of the tolerance.
+	s.seenMutex.Lock()
+	defer s.seenMutex.Unlock()
+	for key, expires := range s.seen {
+		if now.After(expires) {
+			delete(s.seen, key)
+		}
+	}
+	key := hex.EncodeToString(given)
+	if _, ok := s.seen[key]; ok {
+		return fmt.Errorf("the payload has already been received")
+	}
+	s.seen[key] = signedAt.Add(s.config.Tolerance)
+	return nil
+}
+
+// forget allows a signature to be received again, after a failure that the sender may retry.
+func (s *Server) forget(r *http.Request) {
+	signature := strings.TrimPrefix(strings.TrimSpace(r.Header.Get(s.config.SignatureHeader)), "sha256=")
+	s.seenMutex.Lock()
+	defer s.seenMutex.Unlock()
+	delete(s.seen, strings.ToLower(signature))
+}
+
+func (s *Server) handleIncident(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.config.MaxBodyBytes))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			s.respond(w, http.StatusRequestEntityTooLarge, Response{Errors: []string{fmt.Sprintf("the payload is larger than %d bytes", s.config.MaxBodyBytes)}})
+			return
+		}
+		s.respond(w, http.StatusBadRequest, Response{Errors: []string{"error reading the payload: " + err.Error()}})
+		return
+	}
+
+	err = s.verify(r, payload)
+	if err != nil {
+		s.log(slog.LevelWarn, "Rejected a payload.", "remote", r.RemoteAddr, "error", err)
+		s.respond(w, http.StatusUnauthorized, Response{Errors: []string{err.Error()}})
+		return
+	}
+
+	record, err := s.config.Mapping.Apply(payload)
+	if err != nil {
+		s.log(slog.LevelWarn, "Rejected a payload.", "dispatch", record.Notification.DispatchNumber, "error", err)
+		s.respond(w, http.StatusUnprocessableEntity, Response{
+			DispatchNumber: record.Notification.DispatchNumber,
+			Errors:         mapping.Problems(err),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.UpsertTimeout)
+	defer cancel()
+	start := time.Now()
+	result, err := s.config.Client.UpsertNfirsNotification(ctx, record)
+	s.metrics.observeUpsert(time.Since(start))
+	if err != nil {
+		s.metrics.countUpsert("failed")
+		s.forget(r)
+		s.log(slog.LevelError, "Could not upsert the dispatch.", "dispatch", record.Notification.DispatchNumber, "error", err)
+		s.respond(w, http.StatusBadGateway, Response{
+			DispatchNumber: record.Notification.DispatchNumber,
+			Errors:         []string{err.Error()},
+		})
+		return
+	}
+	s.metrics.countUpsert(result)
+	s.log(slog.LevelInfo, "Upserted the dispatch.", "dispatch", record.Notification.DispatchNumber, "result", result, "apparatuses", len(record.Apparatuses))
+
+	status := http.StatusOK
+	if result == firstdue.UpsertCreated {
+		status = http.StatusCreated
+	}
+	s.respond(w, status, Response{DispatchNumber: record.Notification.DispatchNumber, Result: result})
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.WriteString(w, "ok\n")
+}
+
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	err := s.ready(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "not ready: %v\n", err)
+		return
+	}
+	io.WriteString(w, "ok\n")
+}
+
+// ready checks that the First Due API is reachable and accepts the credentials.
+//
+// The result is reused for the readiness interval so that frequent probes do not turn into API traffic.
+func (s *Server) ready(ctx context.Context) error {
+	s.readyMutex.Lock()
+	defer s.readyMutex.Unlock()
+
+	if !s.readyChecked.IsZero() && time.Since(s.readyChecked) < s.config.ReadinessInterval {
+		return s.readyErr
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	_, err := s.config.Client.GetLogsSettings(ctx, firstdue.GetLogsSettingsRequest{})
+	s.readyChecked = time.Now()
+	s.readyErr = err
+	s.metrics.setReady(err == nil)
+	return err
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	s.metrics.write(w)
+}
+
+// respond writes a JSON response and counts it.
+func (s *Server) respond(w http.ResponseWriter, status int, response Response) {
+	s.metrics.countRequest(status)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) log(level slog.Level, message string, args ...any) {
+	if s.config.Logger != nil {
+		s.config.Logger.Log(context.Background(), level, message, args...)
+	}
+}
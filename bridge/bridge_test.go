@@ -0,0 +1,92 @@
+package bridge_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+	"github.com/tekkamanendless/firstdue/bridge"
+	"github.com/tekkamanendless/firstdue/mapping"
+	"github.com/tekkamanendless/firstdue/mock"
+)
+
+var secret = []byte("secret")
+
+const payload = `{"number": "B26-0001", "alarm": "2026-10-19T08:30:00Z", "units": [{"id": "E1"}]}`
+
+func newServer(t *testing.T) *bridge.Server {
+	t.Helper()
+	api := httptest.NewServer(mock.NewServer(mock.Config{Fixtures: mock.DefaultFixtures(), Seed: 1}))
+	t.Cleanup(api.Close)
+	client := firstdue.NewClient(firstdue.WithBaseURL(api.URL), firstdue.WithToken("x"))
+
+	m, err := mapping.Parse([]byte("notification:\n  dispatch_number: number\n  alarm_at: alarm\napparatuses:\n  path: units\n  fields:\n    unit_code: id\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := bridge.NewServer(bridge.Config{Client: client, Mapping: m, Secret: secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server
+}
+
+// post sends the payload with the given headers and returns the status.
+func post(server *bridge.Server, timestamp string, signature string) int {
+	r := httptest.NewRequest(http.MethodPost, "/incidents", strings.NewReader(payload))
+	if timestamp != "" {
+		r.Header.Set(bridge.DefaultTimestampHeader, timestamp)
+	}
+	if signature != "" {
+		r.Header.Set(bridge.DefaultSignatureHeader, signature)
+	}
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, r)
+	return w.Code
+}
+
+func TestSignedPayload(t *testing.T) {
+	server := newServer(t)
+	now := time.Now()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := bridge.Sign(secret, now, []byte(payload))
+
+	if status := post(server, timestamp, signature); status != http.StatusCreated {
+		t.Fatalf("got status %d, want %d", status, http.StatusCreated)
+	}
+	if status := post(server, timestamp, signature); status != http.StatusUnauthorized {
+		t.Errorf("replay: got status %d, want %d", status, http.StatusUnauthorized)
+	}
+
+	// Re-signing the same payload is a new delivery.
+	later := now.Add(time.Second)
+	if status := post(server, strconv.FormatInt(later.Unix(), 10), bridge.Sign(secret, later, []byte(payload))); status != http.StatusOK {
+		t.Errorf("re-signed: got status %d, want %d", status, http.StatusOK)
+	}
+}
+
+func TestRejectedSignatures(t *testing.T) {
+	server := newServer(t)
+	now := time.Now()
+	stale := now.Add(-bridge.DefaultTolerance - time.Minute)
+	future := now.Add(bridge.DefaultTolerance + time.Minute)
+
+	for name, headers := range map[string][2]string{
+		"no timestamp":      {"", bridge.Sign(secret, now, []byte(payload))},
+		"no signature":      {strconv.FormatInt(now.Unix(), 10), ""},
+		"wrong secret":      {strconv.FormatInt(now.Unix(), 10), bridge.Sign([]byte("other"), now, []byte(payload))},
+		"changed timestamp": {strconv.FormatInt(now.Unix()+1, 10), bridge.Sign(secret, now, []byte(payload))},
+		"stale":             {strconv.FormatInt(stale.Unix(), 10), bridge.Sign(secret, stale, []byte(payload))},
+		"future":            {strconv.FormatInt(future.Unix(), 10), bridge.Sign(secret, future, []byte(payload))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if status := post(server, headers[0], headers[1]); status != http.StatusUnauthorized {
+				t.Errorf("got status %d, want %d", status, http.StatusUnauthorized)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package bridge
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// metrics holds the server's counters, written in the Prometheus text exposition format.
+type metrics struct {
+	mutex          sync.Mutex
+	requests       map[int]uint64    // By HTTP status.
+	upserts        map[string]uint64 // By result.
+	upsertSeconds  float64
+	upsertCount    uint64
+	ready          bool
+	readyKnown     bool
+	startTimestamp time.Time
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:       map[int]uint64{},
+		upserts:        map[string]uint64{},
+		startTimestamp: time.Now(),
+	}
+}
+
+func (m *metrics) countRequest(status int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.requests[status]++
+}
+
+func (m *metrics) countUpsert(result string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.upserts[result]++
+}
+
+func (m *metrics) observeUpsert(duration time.Duration) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.upsertSeconds += duration.Seconds()
+	m.upsertCount++
+}
+
+func (m *metrics) setReady(ready bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.ready = ready
+	m.readyKnown = true
+}
+
+func (m *metrics) write(w io.Writer) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	fmt.Fprintf(w, "# HELP firstdue_bridge_requests_total Incident payloads received, by HTTP response status.\n")
+	fmt.Fprintf(w, "# TYPE firstdue_bridge_requests_total counter\n")
+	statuses := make([]int, 0, len(m.requests))
+	for status := range m.requests {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	for _, status := range statuses {
+		fmt.Fprintf(w, "firstdue_bridge_requests_total{status=%q} %d\n", strconv.Itoa(status), m.requests[status])
+	}
+
+	fmt.Fprintf(w, "# HELP firstdue_bridge_upserts_total Notification upserts, by result (created, updated, or failed).\n")
+	fmt.Fprintf(w, "# TYPE firstdue_bridge_upserts_total counter\n")
+	results := make([]string, 0, len(m.upserts))
+	for result := range m.upserts {
+		results = append(results, result)
+	}
+	sort.Strings(results)
+	for _, result := range results {
+		fmt.Fprintf(w, "firstdue_bridge_upserts_total{result=%q} %d\n", result, m.upserts[result])
+	}
+
+	fmt.Fprintf(w, "# HELP firstdue_bridge_upsert_duration_seconds Time spent upserting notifications.\n")
+	fmt.Fprintf(w, "# TYPE firstdue_bridge_upsert_duration_seconds summary\n")
+	fmt.Fprintf(w, "firstdue_bridge_upsert_duration_seconds_sum %s\n", strconv.FormatFloat(m.upsertSeconds, 'g', -1, 64))
+	fmt.Fprintf(w, "firstdue_bridge_upsert_duration_seconds_count %d\n", m.upsertCount)
+
+	if m.readyKnown {
+		ready := 0
+		if m.ready {
+			ready = 1
+		}
+		fmt.Fprintf(w, "# HELP firstdue_bridge_ready Whether the last readiness check succeeded.\n")
+		fmt.Fprintf(w, "# TYPE firstdue_bridge_ready gauge\n")
+		fmt.Fprintf(w, "firstdue_bridge_ready %d\n", ready)
+	}
+
+	fmt.Fprintf(w, "# HELP firstdue_bridge_start_time_seconds When the server started, in Unix time.\n")
+	fmt.Fprintf(w, "# TYPE firstdue_bridge_start_time_seconds gauge\n")
+	fmt.Fprintf(w, "firstdue_bridge_start_time_seconds %d\n", m.startTimestamp.Unix())
+}
@@ -0,0 +1,385 @@
+package firstdue
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTLs are the cache lifetimes for the reference endpoints whose data changes slowly.
+var DefaultCacheTTLs = map[string]time.Duration{
+	"/v1/stations":      1 * time.Hour,
+	"/v1/apparatuses":   1 * time.Hour,
+	"/v1/logs/settings": 15 * time.Minute,
+}
+
+// CacheConfig configures response caching for a Client.
+//
+// Only GET requests to the endpoints listed in TTLs are cached.
+type CacheConfig struct {
+	Store                CacheStore               // Where to keep the cached responses; if nil, an in-memory store will be used.
+	TTLs                 map[string]time.Duration // Maps an endpoint path (without the query string) to how long its responses stay fresh; if nil, DefaultCacheTTLs will be used.
+	Namespace            string                   // Distinguishes the entries of different accounts that share a store; if empty, a hash of the user name given to Authenticate, or else of the token, is used.
+	StaleWhileRevalidate bool                     // If true, expired entries are returned at once while they are refreshed in the background; an entry whose refresh fails is kept and returned again.
+	StaleIfError         bool                     // If true, an expired entry is returned when refreshing it fails, instead of the error.
+}
+
+// CacheEntry is a single cached response body.
+type CacheEntry struct {
+	Key      string          `json:"key"`
+	StoredAt time.Time       `json:"stored_at"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// CacheStore stores cached responses.
+//
+// Implementations must be safe for concurrent use.
+type CacheStore interface {
+	// Get returns the entry for the key, if there is one.
+	Get(key string) (entry CacheEntry, found bool, err error)
+	// Set stores the entry, replacing any existing entry for its key.
+	Set(entry CacheEntry) error
+	// Delete removes the entry for the key; it is not an error if there is no such entry.
+	Delete(key string) error
+	// Keys returns the keys of all of the entries.
+	Keys() ([]string, error)
+}
+
+// WithCache enables response caching for the reference endpoints.
+func WithCache(cacheConfig CacheConfig) ClientOption {
+	return func(c *ClientConfig) {
+		c.Cache = &cacheConfig
+	}
+}
+
+// MemoryCacheStore is a CacheStore that keeps entries in memory.
+type MemoryCacheStore struct {
+	mutex   sync.Mutex
+	entries map[string]CacheEntry
+}
+
+var _ CacheStore = (*MemoryCacheStore)(nil)
+
+// NewMemoryCacheStore returns a new, empty in-memory cache store.
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{
+		entries: map[string]CacheEntry{},
+	}
+}
+
+func (s *MemoryCacheStore) Get(key string) (CacheEntry, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entry, found := s.entries[key]
+	return entry, found, nil
+}
+
+func (s *MemoryCacheStore) Set(entry CacheEntry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries[entry.Key] = entry
+	return nil
+}
+
+func (s *MemoryCacheStore) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryCacheStore) Keys() ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var keys []string
+	for key := range s.entries {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// FileCacheStore is a CacheStore that keeps each entry in its own file in a directory.
+//
+// This allows the cache to be shared between runs of a program.
+type FileCacheStore struct {
+	directory string
+}
+
+var _ CacheStore = (*FileCacheStore)(nil)
+
+// NewFileCacheStore returns a cache store that uses the given directory, creating it if necessary.
+func NewFileCacheStore(directory string) (*FileCacheStore, error) {
+	err := os.MkdirAll(directory, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cache directory: %w", err)
+	}
+	s := &FileCacheStore{
+		directory: directory,
+	}
+	return s, nil
+}
+
+func (s *FileCacheStore) filename(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(s.directory, hex.EncodeToString(hash[:])+".json")
+}
+
+func (s *FileCacheStore) Get(key string) (CacheEntry, bool, error) {
+	var entry CacheEntry
+	contents, err := os.ReadFile(s.filename(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entry, false, nil
+		}
+		return entry, false, err
+	}
+	err = json.Unmarshal(contents, &entry)
+	if err != nil {
+		// A corrupt entry is the same as a missing one.
+		return entry, false, nil
+	}
+	if entry.Key != key {
+		return entry, false, nil
+	}
+	return entry, true, nil
+}
+
+func (s *FileCacheStore) Set(entry CacheEntry) error {
+	contents, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so that a concurrent reader never sees a partial entry.
+	file, err := os.CreateTemp(s.directory, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(contents)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	err = os.Rename(file.Name(), s.filename(entry.Key))
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
+
+func (s *FileCacheStore) Delete(key string) error {
+	err := os.Remove(s.filename(key))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileCacheStore) Keys() ([]string, error) {
+	files, err := os.ReadDir(s.directory)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		contents, err := os.ReadFile(filepath.Join(s.directory, file.Name()))
+		if err != nil {
+			continue
+		}
+		var entry CacheEntry
+		if err := json.Unmarshal(contents, &entry); err != nil {
+			continue
+		}
+		keys = append(keys, entry.Key)
+	}
+	return keys, nil
+}
+
+// responseCache is the run-time state of the response cache for a Client.
+type responseCache struct {
+	config CacheConfig
+
+	mutex    sync.Mutex
+	inflight map[string]*cacheCall // Requests that are currently being made, by key.
+}
+
+// cacheCall is a single request that concurrent callers with the same key can wait on.
+type cacheCall struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// newResponseCache returns the cache for a client.
+func newResponseCache(config CacheConfig) *responseCache {
+	if config.Store == nil {
+		config.Store = NewMemoryCacheStore()
+	}
+	if config.TTLs == nil {
+		config.TTLs = DefaultCacheTTLs
+	}
+	return &responseCache{
+		config:   config,
+		inflight: map[string]*cacheCall{},
+	}
+}
+
+// ttl returns how long responses for the path stay fresh, and whether the path is cached at all.
+func (rc *responseCache) ttl(path string) (time.Duration, bool) {
+	endpoint, _, _ := strings.Cut(path, "?")
+	endpoint = "/" + strings.Trim(endpoint, "/")
+	ttl, ok := rc.config.TTLs[endpoint]
+	return ttl, ok
+}
+
+// key returns the store key for the given request.
+func (rc *responseCache) key(namespace string, baseURL string, path string) string {
+	return namespace + " " + strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// cacheKey returns the store key for a request made by the client.
+//
+// The namespace is worked out for each request, since the credentials can change after the client is created, such
+// as when Authenticate is called.
+func (c *Client) cacheKey(path string) string {
+	return c.cache.key(c.cacheNamespace(), c.config.BaseURL, path)
+}
+
+// cacheNamespace returns the namespace that keeps the entries of different accounts apart when they share a store.
+//
+// The user name is preferred to the token so that the entries survive a token refresh.  Neither is written to the
+// store as is.
+func (c *Client) cacheNamespace() string {
+	switch {
+	case c.cache.config.Namespace != "":
+		return c.cache.config.Namespace
+	case c.username != "":
+		return "user:" + shortHash(strings.ToLower(c.username))
+	case c.config.Token != "":
+		return "token:" + shortHash(c.config.Token)
+	}
+	return ""
+}
+
+func shortHash(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:8])
+}
+
+// get returns the cached response body for the key, calling fetch if there is no fresh entry.
+func (rc *responseCache) get(ctx context.Context, key string, ttl time.Duration, fetch func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	entry, found, err := rc.config.Store.Get(key)
+	if err != nil {
+		slog.WarnContext(ctx, "Could not read from the cache.", "key", key, "error", err)
+		found = false
+	}
+	if found && time.Since(entry.StoredAt) < ttl {
+		return entry.Data, nil
+	}
+
+	if found && rc.config.StaleWhileRevalidate {
+		go func() {
+			_, err := rc.fetch(context.WithoutCancel(ctx), key, fetch)
+			if err != nil {
+				slog.WarnContext(ctx, "Could not revalidate the cache entry.", "key", key, "error", err)
+			}
+		}()
+		return entry.Data, nil
+	}
+
+	data, err := rc.fetch(ctx, key, fetch)
+	if err != nil {
+		if found && rc.config.StaleIfError && ctx.Err() == nil {
+			slog.WarnContext(ctx, "Could not refresh the cache entry; using the expired one.", "key", key, "error", err)
+			return entry.Data, nil
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// fetch calls the fetch function and stores the result, sharing a single call between concurrent callers.
+func (rc *responseCache) fetch(ctx context.Context, key string, fetch func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	rc.mutex.Lock()
+	if call, ok := rc.inflight[key]; ok {
+		rc.mutex.Unlock()
+		select {
+		case <-call.done:
+			return call.data, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &cacheCall{
+		done: make(chan struct{}),
+	}
+	rc.inflight[key] = call
+	rc.mutex.Unlock()
+
+	call.data, call.err = fetch(ctx)
+	if call.err == nil {
+		err := rc.config.Store.Set(CacheEntry{Key: key, StoredAt: time.Now(), Data: call.data})
+		if err != nil {
+			slog.WarnContext(ctx, "Could not write to the cache.", "key", key, "error", err)
+		}
+	}
+
+	rc.mutex.Lock()
+	delete(rc.inflight, key)
+	rc.mutex.Unlock()
+	close(call.done)
+
+	return call.data, call.err
+}
+
+// InvalidateCache removes cached responses.
+//
+// Each path is an endpoint path, such as "/v1/stations"; all of the cached responses for that endpoint are removed,
+// regardless of their query strings.  If no paths are given, the entire cache for this client is cleared.
+//
+// It is not an error to call this on a client without a cache.
+func (c *Client) InvalidateCache(paths ...string) error {
+	if c.cache == nil {
+		return nil
+	}
+	keys, err := c.cache.config.Store.Keys()
+	if err != nil {
+		return fmt.Errorf("invalidatecache: %w", err)
+	}
+	prefix := c.cacheKey("")
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		matches := len(paths) == 0
+		for _, path := range paths {
+			endpointKey := c.cacheKey(path)
+			if key == endpointKey || strings.HasPrefix(key, endpointKey+"?") {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+		err := c.cache.config.Store.Delete(key)
+		if err != nil {
+			return fmt.Errorf("invalidatecache: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package firstdue_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+	"github.com/tekkamanendless/firstdue/mock"
+)
+
+// newCacheServer returns a mock server and a counter of its station requests.
+func newCacheServer(t *testing.T) (*mock.Server, string, *atomic.Int32) {
+	t.Helper()
+	server := mock.NewServer(mock.Config{Fixtures: mock.DefaultFixtures(), Seed: 1})
+	var requests atomic.Int32
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/v1/stations") {
+			requests.Add(1)
+		}
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(httpServer.Close)
+	return server, httpServer.URL, &requests
+}
+
+func TestCacheStaleIfError(t *testing.T) {
+	ctx := context.Background()
+	server, baseURL, requests := newCacheServer(t)
+
+	for _, staleIfError := range []bool{false, true} {
+		client := firstdue.NewClient(firstdue.WithBaseURL(baseURL), firstdue.WithToken("test"), firstdue.WithCache(firstdue.CacheConfig{
+			TTLs:         map[string]time.Duration{"/v1/stations": time.Millisecond},
+			StaleIfError: staleIfError,
+		}))
+		if _, err := client.GetStations(ctx, firstdue.GetStationsRequest{}); err != nil {
+			t.Fatalf("GetStations: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		// The entry has expired, and refreshing it fails.
+		server.AddFault(mock.Fault{PathPrefix: "/v1/stations", Status: http.StatusServiceUnavailable, Count: 1})
+		output, err := client.GetStations(ctx, firstdue.GetStationsRequest{})
+		if staleIfError {
+			if err != nil || len(output.List) != 2 {
+				t.Errorf("expected the expired entry, got %+v: %v", output, err)
+			}
+		} else if err == nil {
+			t.Errorf("expected an error without StaleIfError")
+		}
+	}
+	if got := requests.Load(); got != 4 {
+		t.Errorf("got %d station requests, want 4", got)
+	}
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	server, baseURL, requests := newCacheServer(t)
+	client := firstdue.NewClient(firstdue.WithBaseURL(baseURL), firstdue.WithToken("test"), firstdue.WithCache(firstdue.CacheConfig{
+		TTLs:                 map[string]time.Duration{"/v1/stations": time.Millisecond},
+		StaleWhileRevalidate: true,
+	}))
+	if _, err := client.GetStations(ctx, firstdue.GetStationsRequest{}); err != nil {
+		t.Fatalf("GetStations: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// The expired entry is returned at once, even though the refresh in the background fails.
+	server.AddFault(mock.Fault{PathPrefix: "/v1/stations", Status: http.StatusServiceUnavailable, Count: 1})
+	output, err := client.GetStations(ctx, firstdue.GetStationsRequest{})
+	if err != nil || len(output.List) != 2 {
+		t.Fatalf("expected the expired entry, got %+v: %v", output, err)
+	}
+	for deadline := time.Now().Add(time.Second); requests.Load() < 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("got %d station requests, want 2", got)
+	}
+}
+
+func TestCacheKeyIncludesCredentials(t *testing.T) {
+	ctx := context.Background()
+	_, baseURL, requests := newCacheServer(t)
+	store := firstdue.NewMemoryCacheStore()
+	newClient := func(token string) *firstdue.Client {
+		return firstdue.NewClient(firstdue.WithBaseURL(baseURL), firstdue.WithToken(token), firstdue.WithCache(firstdue.CacheConfig{Store: store}))
+	}
+
+	// Clients that share a store but not a token do not share entries.
+	for _, token := range []string{"first", "second", "first"} {
+		if _, err := newClient(token).GetStations(ctx, firstdue.GetStationsRequest{}); err != nil {
+			t.Fatalf("GetStations: %v", err)
+		}
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("got %d station requests, want 2", got)
+	}
+	keys, err := store.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if strings.Contains(key, "first") || strings.Contains(key, "second") {
+			t.Errorf("the key %q contains the token", key)
+		}
+	}
+
+	// Invalidating the cache of one client leaves the other's alone.
+	if err := newClient("first").InvalidateCache(); err != nil {
+		t.Fatalf("InvalidateCache: %v", err)
+	}
+	if keys, _ := store.Keys(); len(keys) != 1 {
+		t.Errorf("got %d keys, want 1", len(keys))
+	}
+}
+
+func TestCacheKeyIncludesAuthenticatedUser(t *testing.T) {
+	ctx := context.Background()
+	_, baseURL, requests := newCacheServer(t)
+	store := firstdue.NewMemoryCacheStore()
+	newClient := func(username string) *firstdue.Client {
+		client := firstdue.NewClient(firstdue.WithBaseURL(baseURL), firstdue.WithCache(firstdue.CacheConfig{Store: store}))
+		if err := client.Authenticate(ctx, username, "password"); err != nil {
+			t.Fatalf("Authenticate: %v", err)
+		}
+		return client
+	}
+
+	// The cache is created before Authenticate sets the token, and each login gets a new token; the entries are still
+	// kept apart by user, and a new login as the same user reuses them.
+	for _, username := range []string{"first@example.com", "second@example.com", "First@example.com"} {
+		if _, err := newClient(username).GetStations(ctx, firstdue.GetStationsRequest{}); err != nil {
+			t.Fatalf("GetStations: %v", err)
+		}
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("got %d station requests, want 2", got)
+	}
+	keys, err := store.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range keys {
+		if strings.Contains(key, "example.com") {
+			t.Errorf("the key %q contains the user name", key)
+		}
+	}
+}
@@ -0,0 +1,390 @@
+// Package cadtext extracts dispatches from the "rip-and-run" text pages and emails that CAD systems send, such as:
+//
+//	CALL: STRUCTURE FIRE ADDR: 123 MAIN ST X: OAK AVE/ELM ST UNITS: E1,L2 TIME: 10/19/2026 08:30:12
+//
+// Each sender's format is described by a template.  A template finds a field either by its keywords (the labels that
+// come before the value, which runs until the next label) or by a regular expression.  Templates are usually written
+// in YAML:
+//
+//	templates:
+//	  - name: county
+//	    match: "^COUNTY CAD"
+//	    keywords:
+//	      incident_type: ["CALL:", "NATURE:"]
+//	      address: ["ADDR:", "LOC:"]
+//	      units: ["UNITS:"]
+//	    patterns:
+//	      dispatch_number: "Inc#\\s*(\\d{4}-\\d+)"
+//	    time_layouts: ["01/02/2006 15:04:05"]
+//	    time_zone: America/Chicago
+//
+// The fields are the Field* constants.  Parse tries the templates in order and uses the first one that matches and
+// finds an address or an incident type.
+package cadtext
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fields that a template can extract.
+const (
+	FieldDispatchNumber   = "dispatch_number"
+	FieldIncidentType     = "incident_type"
+	FieldIncidentTypeCode = "incident_type_code"
+	FieldAddress          = "address"
+	FieldCrossStreets     = "cross_streets"
+	FieldCity             = "city"
+	FieldStateCode        = "state_code"
+	FieldPlaceName        = "place_name"
+	FieldUnits            = "units"
+	FieldTime             = "time"
+	FieldNarrative        = "narrative"
+	FieldLatitude         = "latitude"
+	FieldLongitude        = "longitude"
+)
+
+var fields = map[string]bool{
+	FieldDispatchNumber:   true,
+	FieldIncidentType:     true,
+	FieldIncidentTypeCode: true,
+	FieldAddress:          true,
+	FieldCrossStreets:     true,
+	FieldCity:             true,
+	FieldStateCode:        true,
+	FieldPlaceName:        true,
+	FieldUnits:            true,
+	FieldTime:             true,
+	FieldNarrative:        true,
+	FieldLatitude:         true,
+	FieldLongitude:        true,
+}
+
+// ErrNoMatch is returned when no template can extract a dispatch from the text.
+var ErrNoMatch = errors.New("no template matched the text")
+
+// Config is a list of templates.
+type Config struct {
+	Templates []Template `yaml:"templates" json:"templates"`
+}
+
+// Template describes one sender's format.
+type Template struct {
+	Name          string              `yaml:"name" json:"name"`
+	Match         string              `yaml:"match,omitempty" json:"match,omitempty"`                   // A regular expression that the text must match for the template to be used.
+	Keywords      map[string][]string `yaml:"keywords,omitempty" json:"keywords,omitempty"`             // The labels of each field, matched without regard to case.
+	Patterns      map[string]string   `yaml:"patterns,omitempty" json:"patterns,omitempty"`             // A regular expression for each field; the value is the group named "value", or else the first group.  A pattern is used before the keywords.
+	UnitSeparator string              `yaml:"unit_separator,omitempty" json:"unit_separator,omitempty"` // A regular expression that separates the units; defaults to commas, semicolons, slashes, and whitespace.
+	TimeLayouts   []string            `yaml:"time_layouts,omitempty" json:"time_layouts,omitempty"`     // Go time layouts to try; defaults to DefaultTimeLayouts.
+	TimeZone      string              `yaml:"time_zone,omitempty" json:"time_zone,omitempty"`           // The IANA time zone of times without an offset; defaults to the local time zone.
+	StateCode     string              `yaml:"state_code,omitempty" json:"state_code,omitempty"`         // The state code to use when the text does not have one.
+}
+
+// DefaultTimeLayouts are the time layouts tried when a template does not list its own.
+var DefaultTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"01/02/2006 15:04:05",
+	"01/02/2006 15:04",
+	"1/2/2006 15:04:05",
+	"1/2/2006 15:04",
+	"01/02/06 15:04:05",
+	"01/02/06 15:04",
+	"15:04:05 01/02/2006",
+	"15:04 01/02/2006",
+}
+
+// DefaultConfig returns a single keyword template that understands the labels most CAD pages use.
+func DefaultConfig() Config {
+	return Config{
+		Templates: []Template{
+			{
+				Name: "default",
+				Keywords: map[string][]string{
+					FieldDispatchNumber:   {"INC#:", "INC #:", "INCIDENT #:", "INCIDENT:", "CALL #:", "CALL#:", "CFS:", "EVENT:", "RUN #:"},
+					FieldIncidentType:     {"CALL:", "CALL TYPE:", "NATURE:", "TYPE:", "NATURE OF CALL:"},
+					FieldIncidentTypeCode: {"CODE:", "TYPE CODE:"},
+					FieldAddress:          {"ADDR:", "ADDRESS:", "LOC:", "LOCATION:"},
+					FieldCrossStreets:     {"X:", "XST:", "X-ST:", "CROSS:", "CROSS STREETS:", "CROSS STS:"},
+					FieldCity:             {"CITY:", "TOWN:", "MUN:"},
+					FieldPlaceName:        {"PLACE:", "BUSINESS:", "COMMON:", "CN:"},
+					FieldUnits:            {"UNITS:", "UNIT:", "UNITS ASSIGNED:", "RESP:"},
+					FieldTime:             {"TIME:", "DATE/TIME:", "DISP TIME:", "RCVD:"},
+					FieldNarrative:        {"NARR:", "NARRATIVE:", "COMMENTS:", "NOTES:", "REMARKS:", "INFO:"},
+					FieldLatitude:         {"LAT:", "LATITUDE:"},
+					FieldLongitude:        {"LON:", "LONG:", "LNG:", "LONGITUDE:"},
+				},
+			},
+		},
+	}
+}
+
+// LoadConfig reads templates from a YAML or JSON file.
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("error reading templates: %w", err)
+	}
+	err = yaml.Unmarshal(contents, &config)
+	if err != nil {
+		return config, fmt.Errorf("error parsing templates: %w", err)
+	}
+	return config, nil
+}
+
+// Parser extracts dispatches using a list of templates.
+type Parser struct {
+	templates []*compiledTemplate
+}
+
+type compiledTemplate struct {
+	Template
+	match         *regexp.Regexp
+	labels        *regexp.Regexp    // Matches any label of any field.
+	labelFields   map[string]string // The field of each label, by its upper-case form.
+	patterns      map[string]*regexp.Regexp
+	unitSeparator *regexp.Regexp
+	location      *time.Location
+}
+
+// NewParser compiles the templates.
+func NewParser(config Config) (*Parser, error) {
+	if len(config.Templates) == 0 {
+		return nil, fmt.Errorf("at least one template is required")
+	}
+	p := &Parser{}
+	for i, template := range config.Templates {
+		compiled, err := compileTemplate(template)
+		if err != nil {
+			name := template.Name
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			return nil, fmt.Errorf("template %s: %w", name, err)
+		}
+		p.templates = append(p.templates, compiled)
+	}
+	return p, nil
+}
+
+func compileTemplate(template Template) (*compiledTemplate, error) {
+	c := &compiledTemplate{
+		Template:    template,
+		labelFields: map[string]string{},
+		patterns:    map[string]*regexp.Regexp{},
+		location:    time.Local,
+	}
+	var err error
+	if template.Match != "" {
+		c.match, err = regexp.Compile(template.Match)
+		if err != nil {
+			return nil, fmt.Errorf("match: %w", err)
+		}
+	}
+
+	var labels []string
+	for field, keywords := range template.Keywords {
+		if !fields[field] {
+			return nil, fmt.Errorf("keywords: unknown field %q", field)
+		}
+		for _, keyword := range keywords {
+			keyword = strings.TrimSpace(keyword)
+			if keyword == "" {
+				continue
+			}
+			if other, ok := c.labelFields[strings.ToUpper(keyword)]; ok && other != field {
+				return nil, fmt.Errorf("keywords: %q is a label of both %s and %s", keyword, other, field)
+			}
+			c.labelFields[strings.ToUpper(keyword)] = field
+			labels = append(labels, keyword)
+		}
+	}
+	if len(labels) > 0 {
+		// Longer labels first, so that "CALL TYPE:" wins over "TYPE:".
+		sort.Slice(labels, func(i, j int) bool {
+			if len(labels[i]) != len(labels[j]) {
+				return len(labels[i]) > len(labels[j])
+			}
+			return labels[i] < labels[j]
+		})
+		quoted := make([]string, len(labels))
+		for i, label := range labels {
+			quoted[i] = regexp.QuoteMeta(label)
+		}
+		c.labels = regexp.MustCompile(`(?i)(?:^|[^\pL\pN])(` + strings.Join(quoted, "|") + `)`)
+	}
+
+	for field, pattern := range template.Patterns {
+		if !fields[field] {
+			return nil, fmt.Errorf("patterns: unknown field %q", field)
+		}
+		c.patterns[field], err = regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("patterns: %s: %w", field, err)
+		}
+	}
+	if len(c.labelFields) == 0 && len(c.patterns) == 0 {
+		return nil, fmt.Errorf("at least one keyword or pattern is required")
+	}
+
+	separator := template.UnitSeparator
+	if separator == "" {
+		separator = `[\s,;/]+`
+	}
+	c.unitSeparator, err = regexp.Compile(separator)
+	if err != nil {
+		return nil, fmt.Errorf("unit_separator: %w", err)
+	}
+	if template.TimeZone != "" {
+		c.location, err = time.LoadLocation(template.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("time_zone: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// Parse extracts a dispatch from plain text.
+func (p *Parser) Parse(text string) (*Dispatch, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	for _, template := range p.templates {
+		if template.match != nil && !template.match.MatchString(text) {
+			continue
+		}
+		values := template.extract(text)
+		if values[FieldAddress] == "" && values[FieldIncidentType] == "" {
+			continue
+		}
+		return template.dispatch(values)
+	}
+	return nil, ErrNoMatch
+}
+
+// extract returns the raw value of each field that the template finds.
+func (c *compiledTemplate) extract(text string) map[string]string {
+	values := map[string]string{}
+	if c.labels != nil {
+		matches := c.labels.FindAllStringSubmatchIndex(text, -1)
+		for i, match := range matches {
+			label := text[match[2]:match[3]]
+			field := c.labelFields[strings.ToUpper(label)]
+			end := len(text)
+			if i+1 < len(matches) {
+				end = matches[i+1][2]
+			}
+			value := text[match[3]:end]
+			if field != FieldNarrative {
+				value, _, _ = strings.Cut(strings.TrimSpace(value), "\n")
+			}
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			if values[field] != "" && field == FieldNarrative {
+				value = values[field] + "\n" + value
+			} else if values[field] != "" {
+				continue // The first occurrence wins.
+			}
+			values[field] = value
+		}
+	}
+	for field, pattern := range c.patterns {
+		match := pattern.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		value := match[0]
+		if index := pattern.SubexpIndex("value"); index > 0 {
+			value = match[index]
+		} else if len(match) > 1 {
+			value = match[1]
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			values[field] = value
+		}
+	}
+	return values
+}
+
+// dispatch converts the raw values into a dispatch.
+func (c *compiledTemplate) dispatch(values map[string]string) (*Dispatch, error) {
+	d := &Dispatch{
+		Template:         c.Name,
+		DispatchNumber:   values[FieldDispatchNumber],
+		IncidentType:     collapseSpaces(values[FieldIncidentType]),
+		IncidentTypeCode: values[FieldIncidentTypeCode],
+		Address:          collapseSpaces(values[FieldAddress]),
+		CrossStreets:     collapseSpaces(values[FieldCrossStreets]),
+		City:             collapseSpaces(values[FieldCity]),
+		StateCode:        strings.ToUpper(values[FieldStateCode]),
+		PlaceName:        collapseSpaces(values[FieldPlaceName]),
+		Narrative:        values[FieldNarrative],
+	}
+	if d.StateCode == "" {
+		d.StateCode = c.StateCode
+	}
+
+	seen := map[string]bool{}
+	for _, unit := range c.unitSeparator.Split(values[FieldUnits], -1) {
+		unit = strings.ToUpper(strings.TrimSpace(unit))
+		if unit != "" && !seen[unit] {
+			seen[unit] = true
+			d.Units = append(d.Units, unit)
+		}
+	}
+
+	var errs []error
+	if value := values[FieldTime]; value != "" {
+		layouts := c.TimeLayouts
+		if len(layouts) == 0 {
+			layouts = DefaultTimeLayouts
+		}
+		for _, layout := range layouts {
+			parsed, err := time.ParseInLocation(layout, value, c.location)
+			if err == nil {
+				d.Time = parsed
+				break
+			}
+		}
+		if d.Time.IsZero() {
+			errs = append(errs, fmt.Errorf("%s: unrecognized time %q", FieldTime, value))
+		}
+	}
+	for _, coordinate := range []struct {
+		field  string
+		target **float64
+	}{
+		{FieldLatitude, &d.Latitude},
+		{FieldLongitude, &d.Longitude},
+	} {
+		value := values[coordinate.field]
+		if value == "" {
+			continue
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid number %q", coordinate.field, value))
+			continue
+		}
+		*coordinate.target = &number
+	}
+	if len(errs) > 0 {
+		return d, errors.Join(errs...)
+	}
+	return d, nil
+}
+
+// collapseSpaces replaces each run of whitespace with a single space.
+func collapseSpaces(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,209 @@
+package cadtext
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newParser(t *testing.T, config Config) *Parser {
+	t.Helper()
+	p, err := NewParser(config)
+	if err != nil {
+		t.Fatalf("NewParser: %v", err)
+	}
+	return p
+}
+
+func TestDefaultTemplate(t *testing.T) {
+	p := newParser(t, Config{Templates: []Template{{
+		Name:      "default",
+		Keywords:  DefaultConfig().Templates[0].Keywords,
+		TimeZone:  "UTC",
+		StateCode: "IL",
+	}}})
+	d, err := p.Parse("CALL: STRUCTURE  FIRE ADDR: 123 MAIN ST X: OAK AVE/ELM ST UNITS: e1, L2;E1 TIME: 10/19/2026 08:30:12\n" +
+		"NARR: SMOKE SHOWING\nCALLER ON SCENE\nLAT: 39.78 LON: -89.65")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := &Dispatch{
+		Template:     "default",
+		IncidentType: "STRUCTURE FIRE",
+		Address:      "123 MAIN ST",
+		CrossStreets: "OAK AVE/ELM ST",
+		StateCode:    "IL",
+		Units:        []string{"E1", "L2"},
+		Time:         time.Date(2026, 10, 19, 8, 30, 12, 0, time.UTC),
+		Narrative:    "SMOKE SHOWING\nCALLER ON SCENE",
+	}
+	latitude, longitude := 39.78, -89.65
+	want.Latitude, want.Longitude = &latitude, &longitude
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("got  %+v\nwant %+v", d, want)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	p := newParser(t, Config{Templates: []Template{
+		{
+			Name:     "county",
+			Match:    "^COUNTY CAD",
+			Keywords: map[string][]string{FieldIncidentType: {"NATURE:"}, FieldAddress: {"LOC:"}, FieldUnits: {"RESP:"}},
+			Patterns: map[string]string{
+				FieldDispatchNumber: `Inc#\s*(\d{4}-\d+)`,
+				FieldTime:           `(?P<value>\d{2}:\d{2} \d{2}/\d{2}/\d{4})`,
+			},
+			UnitSeparator: `\s+`,
+			TimeLayouts:   []string{"15:04 01/02/2006"},
+			TimeZone:      "UTC",
+		},
+		{
+			Name:     "fallback",
+			Keywords: map[string][]string{FieldAddress: {"ADDR:"}},
+		},
+	}})
+
+	d, err := p.Parse("COUNTY CAD Inc# 2026-0042 NATURE: MEDICAL LOC: 9 ELM ST 08:15 10/19/2026 RESP: M2 E3")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if d.Template != "county" || d.DispatchNumber != "2026-0042" || d.IncidentType != "MEDICAL" || !reflect.DeepEqual(d.Units, []string{"M2", "E3"}) {
+		t.Errorf("unexpected dispatch: %+v", d)
+	}
+	if want := time.Date(2026, 10, 19, 8, 15, 0, 0, time.UTC); !d.Time.Equal(want) {
+		t.Errorf("got time %s, want %s", d.Time, want)
+	}
+
+	// The first template does not match, so the second one is used.
+	d, err = p.Parse("CITY PAGE ADDR: 1 OAK ST")
+	if err != nil || d.Template != "fallback" || d.Address != "1 OAK ST" {
+		t.Errorf("unexpected dispatch: %+v (%v)", d, err)
+	}
+
+	if _, err := p.Parse("NOTHING TO SEE HERE"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("got %v, want ErrNoMatch", err)
+	}
+}
+
+func TestInvalidValues(t *testing.T) {
+	p := newParser(t, DefaultConfig())
+	d, err := p.Parse("CALL: FIRE TIME: LATER LAT: NORTH")
+	if d == nil || d.IncidentType != "FIRE" {
+		t.Fatalf("expected a partial dispatch, got %+v", d)
+	}
+	if err == nil || !strings.Contains(err.Error(), "unrecognized time") || !strings.Contains(err.Error(), "invalid number") {
+		t.Errorf("got %v", err)
+	}
+
+	for name, template := range map[string]Template{
+		"unknown field":  {Keywords: map[string][]string{"color": {"COLOR:"}}},
+		"shared label":   {Keywords: map[string][]string{FieldCity: {"AT:"}, FieldAddress: {"at:"}}},
+		"bad pattern":    {Patterns: map[string]string{FieldAddress: "("}},
+		"nothing to use": {},
+	} {
+		if _, err := NewParser(Config{Templates: []Template{template}}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestMultipartMessage(t *testing.T) {
+	p := newParser(t, DefaultConfig())
+	message := "From: cad@example.com\r\n" +
+		"Subject: =?UTF-8?Q?CALL:_ALARM?=\r\n" +
+		"Date: Mon, 19 Oct 2026 08:30:00 -0500\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/alternative; boundary=\"b1\"\r\n" +
+		"\r\n" +
+		"--b1\r\n" +
+		"Content-Type: text/html; charset=utf-8\r\n" +
+		"\r\n" +
+		"<p>ADDR: 5 HTML ST</p>\r\n" +
+		"--b1\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"ADDR: 123 MAIN ST UNITS: E1 NARR: CAF=C3=89 ON THE CORNER, SENSOR IN KITCHE=\r\n" +
+		"N\r\n" +
+		"--b1--\r\n"
+	d, err := p.ParseMessage(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+	if d.IncidentType != "ALARM" || d.Address != "123 MAIN ST" || d.Narrative != "CAFÉ ON THE CORNER, SENSOR IN KITCHEN" {
+		t.Errorf("unexpected dispatch: %+v", d)
+	}
+	// The page has no time, so the Date header is used.
+	if want := time.Date(2026, 10, 19, 13, 30, 0, 0, time.UTC); !d.Time.Equal(want) {
+		t.Errorf("got time %s, want %s", d.Time, want)
+	}
+}
+
+func TestHTMLMessage(t *testing.T) {
+	p := newParser(t, DefaultConfig())
+	message := "Subject: page\r\n" +
+		"Content-Type: multipart/mixed; boundary=b1\r\n" +
+		"\r\n" +
+		"--b1\r\n" +
+		"Content-Type: text/html\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		"PGh0bWw+PGhlYWQ+PHRpdGxlPkFERFI6IE5PPC90aXRsZT48L2hlYWQ+PGJvZHk+Q0FMTDogRklSRTxici8+QUREUjogNyBPQUsgU1QgJmFtcDsgRUxNPC9ib2R5PjwvaHRtbD4=\r\n" +
+		"--b1--\r\n"
+	d, err := p.ParseMessage(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+	if d.IncidentType != "FIRE" || d.Address != "7 OAK ST & ELM" {
+		t.Errorf("unexpected dispatch: %+v", d)
+	}
+}
+
+func TestWindows1252Message(t *testing.T) {
+	p := newParser(t, DefaultConfig())
+	message := "Subject: page\r\n" +
+		"Content-Type: text/plain; charset=windows-1252\r\n" +
+		"\r\n" +
+		"CALL: FIRE ADDR: 1 MAIN ST NARR: \x93SMOKE\x94 \x96 \x80100 DAMAGE\r\n"
+	d, err := p.ParseMessage(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("ParseMessage: %v", err)
+	}
+	if want := "“SMOKE” – €100 DAMAGE"; d.Narrative != want {
+		t.Errorf("got %q, want %q", d.Narrative, want)
+	}
+}
+
+func TestNotificationWithoutTime(t *testing.T) {
+	zero := 0.0
+	d := &Dispatch{Address: "1 MAIN ST", Units: []string{"E1"}, Latitude: &zero, Longitude: &zero}
+
+	record := d.Notification()
+	if !record.Apparatuses[0].DispatchAt.IsZero() || !record.Notification.AlarmAt.IsZero() {
+		t.Errorf("unexpected times: %+v", record)
+	}
+	if record.Notification.Latitude != nil || record.Notification.Longitude != nil {
+		t.Errorf("unexpected coordinates: %v, %v", record.Notification.Latitude, record.Notification.Longitude)
+	}
+	request := d.DispatchRequest()
+	if request.Latitude != 0 || request.Longitude != 0 || !request.CreatedAt.IsZero() {
+		t.Errorf("unexpected request: %+v", request)
+	}
+	want := []string{"the page has no dispatch number", "the page has no time", "the page's coordinates are incomplete or zero, so they are left out"}
+	if got := d.Warnings(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got warnings %q, want %q", got, want)
+	}
+
+	d.DispatchNumber = "26-1"
+	d.Time = time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)
+	d.Latitude, d.Longitude = nil, nil
+	if got := d.Warnings(); len(got) != 0 {
+		t.Errorf("unexpected warnings %q", got)
+	}
+	if record := d.Notification(); !time.Time(record.Apparatuses[0].DispatchAt).Equal(d.Time) {
+		t.Errorf("got DispatchAt %v", record.Apparatuses[0].DispatchAt)
+	}
+}
@@ -0,0 +1,129 @@
+package cadtext
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// Dispatch is the information extracted from a page.  Fields that were not found are empty.
+type Dispatch struct {
+	Template         string    `json:"template"` // The name of the template that matched.
+	DispatchNumber   string    `json:"dispatch_number,omitempty"`
+	IncidentType     string    `json:"incident_type,omitempty"`
+	IncidentTypeCode string    `json:"incident_type_code,omitempty"`
+	Address          string    `json:"address,omitempty"`
+	CrossStreets     string    `json:"cross_streets,omitempty"`
+	City             string    `json:"city,omitempty"`
+	StateCode        string    `json:"state_code,omitempty"`
+	PlaceName        string    `json:"place_name,omitempty"`
+	Units            []string  `json:"units,omitempty"`
+	Time             time.Time `json:"time,omitzero"`
+	Narrative        string    `json:"narrative,omitempty"`
+	Latitude         *float64  `json:"latitude,omitempty"`
+	Longitude        *float64  `json:"longitude,omitempty"`
+}
+
+// Warnings returns what is missing from the dispatch that the API will want; see Notification and DispatchRequest.
+func (d *Dispatch) Warnings() []string {
+	var warnings []string
+	if d.DispatchNumber == "" {
+		warnings = append(warnings, "the page has no dispatch number")
+	}
+	if d.Time.IsZero() {
+		warnings = append(warnings, "the page has no time")
+	}
+	if _, _, ok := d.location(); !ok && (d.Latitude != nil || d.Longitude != nil) {
+		warnings = append(warnings, "the page's coordinates are incomplete or zero, so they are left out")
+	}
+	return warnings
+}
+
+// Notification returns a draft notification record with an apparatus for each unit.
+//
+// The alarm and dispatch times are the page's time.  Pages rarely carry a dispatch number and sometimes have no time,
+// so the caller may need to fill them in before the record can be sent; Warnings reports both.
+func (d *Dispatch) Notification() firstdue.NfirsNotificationRecord {
+	var record firstdue.NfirsNotificationRecord
+	n := &record.Notification
+	n.DispatchNumber = d.DispatchNumber
+	n.DispatchType = d.IncidentType
+	n.DispatchIncidentTypeCode = d.IncidentTypeCode
+	n.Address = d.Address
+	n.CrossStreets = d.CrossStreets
+	n.City = d.City
+	n.StateCode = d.StateCode
+	n.PlaceName = optionalString(d.PlaceName)
+	n.Narratives = optionalString(d.Narrative)
+	if !d.Time.IsZero() {
+		n.AlarmAt = firstdue.Timestamp(d.Time)
+		n.DispatchNotifiedAt = firstdue.Timestamp(d.Time)
+	}
+	if latitude, longitude, ok := d.location(); ok {
+		n.Latitude = (*firstdue.StringFloat64)(&latitude)
+		n.Longitude = (*firstdue.StringFloat64)(&longitude)
+	}
+	for _, unit := range d.Units {
+		apparatus := firstdue.NfirsNotificationApparatus{UnitCode: unit}
+		if !d.Time.IsZero() {
+			apparatus.DispatchAt = firstdue.Timestamp(d.Time)
+		}
+		record.Apparatuses = append(record.Apparatuses, apparatus)
+	}
+	return record
+}
+
+// DispatchRequest returns a request that creates the dispatch.
+//
+// Coordinates of 0,0 mean no location to the API, so they are left out, as are incomplete ones; Warnings reports
+// them.
+func (d *Dispatch) DispatchRequest() firstdue.PostDispatchesRequest {
+	request := firstdue.PostDispatchesRequest{
+		Type:             d.IncidentType,
+		Message:          d.message(),
+		Address:          d.Address,
+		Address2:         d.PlaceName,
+		City:             d.City,
+		StateCode:        d.StateCode,
+		UnitCodes:        d.Units,
+		IncidentTypeCode: d.IncidentTypeCode,
+		XrefID:           d.DispatchNumber,
+		CreatedAt:        firstdue.Timestamp(d.Time),
+	}
+	if latitude, longitude, ok := d.location(); ok {
+		request.Latitude = latitude
+		request.Longitude = longitude
+	}
+	return request
+}
+
+// location returns the coordinates if both are present and neither is zero.
+func (d *Dispatch) location() (latitude float64, longitude float64, ok bool) {
+	if d.Latitude == nil || d.Longitude == nil || *d.Latitude == 0 || *d.Longitude == 0 {
+		return 0, 0, false
+	}
+	return *d.Latitude, *d.Longitude, true
+}
+
+// message is the text shown to the responders: the incident type, the cross streets, and the narrative.
+func (d *Dispatch) message() string {
+	var parts []string
+	if d.IncidentType != "" {
+		parts = append(parts, d.IncidentType)
+	}
+	if d.CrossStreets != "" {
+		parts = append(parts, "X: "+d.CrossStreets)
+	}
+	if d.Narrative != "" {
+		parts = append(parts, d.Narrative)
+	}
+	return strings.Join(parts, "\n")
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
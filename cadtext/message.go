@@ -0,0 +1,121 @@
+package cadtext
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/tekkamanendless/firstdue/internal/charset"
+)
+
+// ParseMessage extracts a dispatch from an RFC 822 email message.
+//
+// The text is the subject followed by the body.  For a MIME multipart message, the first text/plain part is used, or
+// else the first text/html part with its markup removed.  If the text has no time, the message's Date header is used.
+func (p *Parser) ParseMessage(r io.Reader) (*Dispatch, error) {
+	message, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading message: %w", err)
+	}
+	body, err := messageText(message.Header.Get("Content-Type"), message.Header.Get("Content-Transfer-Encoding"), message.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading message body: %w", err)
+	}
+
+	subject := message.Header.Get("Subject")
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(subject); err == nil {
+		subject = decoded
+	}
+	text := body
+	if subject != "" {
+		text = subject + "\n" + body
+	}
+
+	d, err := p.Parse(text)
+	if d != nil && d.Time.IsZero() {
+		if date, dateErr := message.Header.Date(); dateErr == nil {
+			d.Time = date
+		}
+	}
+	return d, err
+}
+
+// messageText returns the text of a message body or MIME part.
+func messageText(contentType string, transferEncoding string, body io.Reader) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	switch strings.ToLower(strings.TrimSpace(transferEncoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		var htmlText string
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", err
+			}
+			text, err := messageText(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return "", err
+			}
+			partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+			switch {
+			case partType == "" || partType == "text/plain" || strings.HasPrefix(partType, "multipart/"):
+				if strings.TrimSpace(text) != "" {
+					return text, nil
+				}
+			case partType == "text/html" && htmlText == "":
+				htmlText = text
+			}
+		}
+		return htmlText, nil
+	}
+
+	if name := params["charset"]; name != "" {
+		// Leave a part with an unknown character set as it is; most of a dispatch page is ASCII anyway.
+		if decoded, err := charset.NewReader(name, body); err == nil {
+			body = decoded
+		}
+	}
+	contents, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	text := string(contents)
+	if mediaType == "text/html" {
+		text = htmlToText(text)
+	}
+	return text, nil
+}
+
+var (
+	htmlBreaks = regexp.MustCompile(`(?i)<\s*(br|/p|/div|/tr|/li)\s*/?>`)
+	htmlTags   = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlHidden = regexp.MustCompile(`(?is)<(style|script|head)[^>]*>.*?</(style|script|head)\s*>`)
+)
+
+// htmlToText removes the markup from an HTML page, keeping its line breaks.
+func htmlToText(text string) string {
+	text = htmlHidden.ReplaceAllString(text, "")
+	text = htmlBreaks.ReplaceAllString(text, "\n")
+	text = htmlTags.ReplaceAllString(text, "")
+	return html.UnescapeString(text)
+}
@@ -0,0 +1,170 @@
+// Package cadxml reads incidents from APCO/NENA-style CAD-to-CAD XML exports and converts them into NFIRS
+// notifications.
+//
+// The document is either a single Incident element or any root element containing Incident elements.  Locations use
+// the NENA/PIDF-LO civic address elements (RFC 5139) and a GML point or circle in WGS 84 "latitude longitude" order:
+//
+//	<CADIncidentExchange xmlns="urn:apco:cad-to-cad:1.0"
+//	    xmlns:ca="urn:ietf:params:xml:ns:pidf:geopriv10:civicAddr" xmlns:gml="http://www.opengis.net/gml">
+//	  <Incident>
+//	    <IncidentNumber>26-001234</IncidentNumber>
+//	    <CADEventNumber>E26-5678</CADEventNumber>
+//	    <IncidentType code="111" priority="1">STRUCTURE FIRE</IncidentType>
+//	    <CallTimes>
+//	      <PSAPAnswered>2026-10-19T08:29:41-05:00</PSAPAnswered>
+//	      <Alarm>2026-10-19T08:30:02-05:00</Alarm>
+//	    </CallTimes>
+//	    <Location>
+//	      <ca:civicAddress><ca:A1>IL</ca:A1><ca:A3>Springfield</ca:A3><ca:HNO>123</ca:HNO><ca:RD>Main</ca:RD><ca:STS>St</ca:STS></ca:civicAddress>
+//	      <gml:Point><gml:pos>39.7817 -89.6501</gml:pos></gml:Point>
+//	    </Location>
+//	    <Units>
+//	      <Unit>
+//	        <UnitID>E1</UnitID>
+//	        <StatusTimes><Dispatched>2026-10-19T08:30:15-05:00</Dispatched><OnScene>2026-10-19T08:36:40-05:00</OnScene></StatusTimes>
+//	      </Unit>
+//	    </Units>
+//	  </Incident>
+//	</CADIncidentExchange>
+//
+// Namespaces are not checked, so exports that use other prefixes or none at all are read the same way.  The samples
+// directory has complete documents.
+package cadxml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/tekkamanendless/firstdue/internal/charset"
+)
+
+// Incident is a CAD incident.
+type Incident struct {
+	IncidentNumber     string           `xml:"IncidentNumber"`
+	CADEventNumber     string           `xml:"CADEventNumber"`
+	IncidentType       IncidentType     `xml:"IncidentType"`
+	AlarmLevel         string           `xml:"AlarmLevel"`
+	EMDCardNumber      string           `xml:"EMDCardNumber"`
+	ResponsibleStation string           `xml:"ResponsibleStation"`
+	ResponseZone       string           `xml:"ResponseZone"`
+	CallTimes          CallTimes        `xml:"CallTimes"`
+	Location           Location         `xml:"Location"`
+	Narrative          []NarrativeEntry `xml:"Narrative>Entry"`
+	Units              []Unit           `xml:"Units>Unit"`
+}
+
+// IncidentType is the CAD's incident type, with its code and priority.
+type IncidentType struct {
+	Code     string `xml:"code,attr"`
+	Priority string `xml:"priority,attr"`
+	Text     string `xml:",chardata"`
+}
+
+// CallTimes are the incident-level times.
+type CallTimes struct {
+	PSAPAnswered    string `xml:"PSAPAnswered"`
+	CallReceived    string `xml:"CallReceived"`
+	Alarm           string `xml:"Alarm"`
+	FirstDispatched string `xml:"FirstDispatched"`
+	Controlled      string `xml:"Controlled"`
+	Closed          string `xml:"Closed"`
+}
+
+// Location is where the incident is.
+type Location struct {
+	CivicAddress    CivicAddress `xml:"civicAddress"`
+	Point           string       `xml:"Point>pos"`
+	Circle          string       `xml:"Circle>pos"`
+	CrossStreets    string       `xml:"CrossStreets"`
+	CommonPlaceName string       `xml:"CommonPlaceName"`
+}
+
+// CivicAddress is a NENA/PIDF-LO civic address.
+type CivicAddress struct {
+	Country string `xml:"country"`
+	A1      string `xml:"A1"`   // State.
+	A2      string `xml:"A2"`   // County.
+	A3      string `xml:"A3"`   // City.
+	PRD     string `xml:"PRD"`  // Leading street direction.
+	POD     string `xml:"POD"`  // Trailing street direction.
+	STS     string `xml:"STS"`  // Street suffix (type).
+	HNO     string `xml:"HNO"`  // House number.
+	HNS     string `xml:"HNS"`  // House number suffix.
+	LMK     string `xml:"LMK"`  // Landmark.
+	LOC     string `xml:"LOC"`  // Additional location information.
+	FLR     string `xml:"FLR"`  // Floor.
+	NAM     string `xml:"NAM"`  // Name (residence or business).
+	PC      string `xml:"PC"`   // Postal code.
+	RD      string `xml:"RD"`   // Road.
+	UNIT    string `xml:"UNIT"` // Unit (apartment or suite).
+	ROOM    string `xml:"ROOM"`
+	BLD     string `xml:"BLD"` // Building.
+}
+
+// NarrativeEntry is a line of the CAD narrative.
+type NarrativeEntry struct {
+	Time   string `xml:"time,attr"`
+	Author string `xml:"author,attr"`
+	Text   string `xml:",chardata"`
+}
+
+// Unit is a unit assigned to the incident.
+type Unit struct {
+	UnitID      string      `xml:"UnitID"`
+	Agency      string      `xml:"Agency"`
+	MutualAid   string      `xml:"MutualAid"`
+	StatusTimes StatusTimes `xml:"StatusTimes"`
+}
+
+// StatusTimes are the times of a unit's status changes.  Some exports use the alternate names.
+type StatusTimes struct {
+	Dispatched   string `xml:"Dispatched"`
+	Acknowledged string `xml:"Acknowledged"`
+	Enroute      string `xml:"Enroute"`
+	OnScene      string `xml:"OnScene"`
+	Arrived      string `xml:"Arrived"` // Alternate for OnScene.
+	Cleared      string `xml:"Cleared"`
+	Available    string `xml:"Available"` // Alternate for Cleared.
+	InQuarters   string `xml:"InQuarters"`
+	Cancelled    string `xml:"Cancelled"`
+	Canceled     string `xml:"Canceled"` // Alternate for Cancelled.
+}
+
+// Parse reads the incidents from a document.
+func Parse(r io.Reader) ([]Incident, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charset.NewReader
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("the document has no root element")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing document: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local == "Incident" {
+			var incident Incident
+			err := decoder.DecodeElement(&incident, &start)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing document: %w", err)
+			}
+			return []Incident{incident}, nil
+		}
+		var document struct {
+			Incidents []Incident `xml:"Incident"`
+		}
+		err = decoder.DecodeElement(&document, &start)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing document: %w", err)
+		}
+		if len(document.Incidents) == 0 {
+			return nil, fmt.Errorf("the document has no incidents")
+		}
+		return document.Incidents, nil
+	}
+}
@@ -0,0 +1,155 @@
+package cadxml
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// convertSample parses a sample document and converts its only incident.
+func convertSample(t *testing.T, name string, options Options) firstdue.NfirsNotificationRecord {
+	t.Helper()
+	file, err := os.Open("samples/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	incidents, err := Parse(file)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(incidents) != 1 {
+		t.Fatalf("expected 1 incident, found %d", len(incidents))
+	}
+	record, err := incidents[0].Record(options)
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	return record
+}
+
+// checkTime compares a timestamp with the expected RFC 3339 time, or with the zero time if the expected value is "".
+func checkTime(t *testing.T, name string, got firstdue.Timestamp, want string) {
+	t.Helper()
+	if want == "" {
+		if !got.IsZero() {
+			t.Errorf("%s: got %s, want no time", name, time.Time(got).Format(time.RFC3339))
+		}
+		return
+	}
+	expected, err := time.Parse(time.RFC3339, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !time.Time(got).Equal(expected) {
+		t.Errorf("%s: got %s, want %s", name, time.Time(got).Format(time.RFC3339), want)
+	}
+}
+
+type unitTimes struct {
+	UnitCode   string
+	DispatchAt string
+	EnrouteAt  string
+	ArriveAt   string
+	ClearAt    string
+	CanceledAt string
+}
+
+func checkUnits(t *testing.T, apparatuses []firstdue.NfirsNotificationApparatus, want []unitTimes) {
+	t.Helper()
+	if len(apparatuses) != len(want) {
+		t.Fatalf("expected %d apparatuses, found %d", len(want), len(apparatuses))
+	}
+	for i, apparatus := range apparatuses {
+		if apparatus.UnitCode != want[i].UnitCode {
+			t.Errorf("apparatus %d: got unit %q, want %q", i+1, apparatus.UnitCode, want[i].UnitCode)
+			continue
+		}
+		checkTime(t, apparatus.UnitCode+" DispatchAt", apparatus.DispatchAt, want[i].DispatchAt)
+		checkTime(t, apparatus.UnitCode+" EnrouteAt", apparatus.EnrouteAt, want[i].EnrouteAt)
+		checkTime(t, apparatus.UnitCode+" ArriveAt", apparatus.ArriveAt, want[i].ArriveAt)
+		checkTime(t, apparatus.UnitCode+" ClearAt", apparatus.ClearAt, want[i].ClearAt)
+		checkTime(t, apparatus.UnitCode+" CanceledAt", apparatus.CanceledAt, want[i].CanceledAt)
+	}
+}
+
+func TestStructureFireSample(t *testing.T) {
+	record := convertSample(t, "structure-fire.xml", Options{})
+	n := record.Notification
+
+	if n.DispatchNumber != "E26-5678" || n.IncidentNumber != "26-001234" {
+		t.Errorf("got dispatch number %q and incident number %q", n.DispatchNumber, n.IncidentNumber)
+	}
+	if n.PSAPAnsweredAt == nil {
+		t.Fatalf("PSAPAnsweredAt is missing")
+	}
+	checkTime(t, "PSAPAnsweredAt", *n.PSAPAnsweredAt, "2026-10-19T08:29:41-05:00")
+	checkTime(t, "AlarmAt", n.AlarmAt, "2026-10-19T08:30:02-05:00")
+	checkTime(t, "DispatchNotifiedAt", n.DispatchNotifiedAt, "2026-10-19T08:30:15-05:00")
+	if n.Address != "123 N Main St" || n.City != "Springfield" || n.StateCode != "IL" || n.Alarms != 2 {
+		t.Errorf("unexpected location: %q, %q, %q, %d alarms", n.Address, n.City, n.StateCode, n.Alarms)
+	}
+	if n.Latitude == nil || n.Longitude == nil || *n.Latitude != 39.7817 || *n.Longitude != -89.6501 {
+		t.Errorf("unexpected coordinates: %v, %v", n.Latitude, n.Longitude)
+	}
+
+	checkUnits(t, record.Apparatuses, []unitTimes{
+		{"E1", "2026-10-19T08:30:15-05:00", "2026-10-19T08:31:10-05:00", "2026-10-19T08:36:40-05:00", "2026-10-19T11:20:00-05:00", ""},
+		{"L2", "2026-10-19T08:30:15-05:00", "2026-10-19T08:32:00-05:00", "2026-10-19T08:39:12-05:00", "2026-10-19T11:30:00-05:00", ""},
+		{"CH7E2", "2026-10-19T08:41:00-05:00", "2026-10-19T08:42:30-05:00", "", "", "2026-10-19T08:50:00-05:00"},
+	})
+	if !record.Apparatuses[2].IsAid || record.Apparatuses[0].IsAid {
+		t.Errorf("only CH7E2 should be mutual aid")
+	}
+}
+
+func TestMedicalLocalTimesSample(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+
+	// The times have no offset, so they are read in the given location: Chicago is at -05:00 in October.
+	record := convertSample(t, "medical-local-times.xml", Options{Location: chicago})
+	n := record.Notification
+	if n.DispatchNumber != "E26-5702" || n.IncidentNumber != "" {
+		t.Errorf("got dispatch number %q and incident number %q", n.DispatchNumber, n.IncidentNumber)
+	}
+	if n.PSAPAnsweredAt == nil {
+		t.Fatalf("PSAPAnsweredAt is missing")
+	}
+	checkTime(t, "PSAPAnsweredAt", *n.PSAPAnsweredAt, "2026-10-19T14:02:11-05:00")
+	checkTime(t, "AlarmAt", n.AlarmAt, "2026-10-19T14:02:20-05:00")
+	checkTime(t, "DispatchNotifiedAt", n.DispatchNotifiedAt, "2026-10-19T14:03:00-05:00")
+	if n.Unit == nil || *n.Unit != "Apt 2" || n.Address != "55 1/2 Elm Ave" || n.StateCode != "IL" {
+		t.Errorf("unexpected address: %q, unit %v, state %q", n.Address, n.Unit, n.StateCode)
+	}
+	if n.PlaceName == nil || *n.PlaceName != "Café Apartments" {
+		t.Errorf("unexpected place name: %v", n.PlaceName)
+	}
+	checkUnits(t, record.Apparatuses, []unitTimes{
+		{"M2", "2026-10-19T14:03:00-05:00", "2026-10-19T14:03:40-05:00", "2026-10-19T14:09:05-05:00", "2026-10-19T14:55:00-05:00", ""},
+		{"E3", "2026-10-19T14:03:00-05:00", "", "", "", "2026-10-19T14:06:00-05:00"},
+	})
+
+	// Without a location, the same wall-clock times are read as UTC.
+	record = convertSample(t, "medical-local-times.xml", Options{})
+	checkTime(t, "PSAPAnsweredAt (UTC)", *record.Notification.PSAPAnsweredAt, "2026-10-19T14:02:11Z")
+	checkTime(t, "M2 DispatchAt (UTC)", record.Apparatuses[0].DispatchAt, "2026-10-19T14:03:00Z")
+}
+
+func TestWindows1252(t *testing.T) {
+	document := "<?xml version=\"1.0\" encoding=\"windows-1252\"?>\n" +
+		"<Incident><CADEventNumber>E1</CADEventNumber><IncidentType>\x93FIRE\x94 \x96 CAF\xc9</IncidentType>" +
+		"<CallTimes><Alarm>2026-10-19T08:30:02Z</Alarm></CallTimes></Incident>"
+	incidents, err := Parse(strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got, want := incidents[0].IncidentType.Text, "“FIRE” – CAFÉ"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
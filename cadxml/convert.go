@@ -0,0 +1,208 @@
+package cadxml
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+	"github.com/tekkamanendless/firstdue/mapping"
+)
+
+// Options controls the conversion of an incident.
+type Options struct {
+	Location *time.Location // The time zone of times without an offset; defaults to UTC.
+}
+
+// timeLayouts are the accepted time formats; the ones without an offset are in the options' location.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// Record converts the incident into a notification record.
+//
+// The dispatch number is the CAD event number, or else the incident number.  The alarm time is the alarm time, or
+// else the time the call was received or answered.  Every field is attempted, and all of the problems are returned
+// together, including those that mapping.Check finds.
+func (i Incident) Record(options Options) (firstdue.NfirsNotificationRecord, error) {
+	if options.Location == nil {
+		options.Location = time.UTC
+	}
+	var record firstdue.NfirsNotificationRecord
+	var errs []error
+	parse := func(name string, values ...string) firstdue.Timestamp {
+		for _, value := range values {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			for _, layout := range timeLayouts {
+				parsed, err := time.ParseInLocation(layout, value, options.Location)
+				if err == nil {
+					return firstdue.Timestamp(parsed)
+				}
+			}
+			errs = append(errs, fmt.Errorf("%s: invalid time %q", name, value))
+			return firstdue.Timestamp{}
+		}
+		return firstdue.Timestamp{}
+	}
+
+	n := &record.Notification
+	n.DispatchNumber = firstNonEmpty(i.CADEventNumber, i.IncidentNumber)
+	n.IncidentNumber = strings.TrimSpace(i.IncidentNumber)
+	n.DispatchType = strings.TrimSpace(i.IncidentType.Text)
+	n.DispatchIncidentTypeCode = strings.TrimSpace(i.IncidentType.Code)
+	n.CADPriority = optional(i.IncidentType.Priority)
+	n.EMDCardNumber = optional(i.EMDCardNumber)
+	n.Station = optional(i.ResponsibleStation)
+	n.Zone = optional(i.ResponseZone)
+	if level := strings.TrimSpace(i.AlarmLevel); level != "" {
+		alarms, err := strconv.Atoi(level)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("AlarmLevel: invalid number %q", level))
+		}
+		n.Alarms = alarms
+	}
+
+	times := i.CallTimes
+	n.AlarmAt = parse("CallTimes/Alarm", times.Alarm, times.CallReceived, times.PSAPAnswered)
+	n.DispatchNotifiedAt = parse("CallTimes/FirstDispatched", times.FirstDispatched)
+	n.CallCompletedAt = parse("CallTimes/Closed", times.Closed)
+	if answered := parse("CallTimes/PSAPAnswered", times.PSAPAnswered); !answered.IsZero() {
+		n.PSAPAnsweredAt = &answered
+	}
+	if controlled := parse("CallTimes/Controlled", times.Controlled); !controlled.IsZero() {
+		n.ControlledAt = &controlled
+	}
+
+	civic := i.Location.CivicAddress
+	houseNumber := strings.TrimSpace(strings.TrimSpace(civic.HNO) + " " + strings.TrimSpace(civic.HNS))
+	n.Address = joinNonEmpty(" ", houseNumber, civic.PRD, civic.RD, civic.STS, civic.POD)
+	n.HouseNum = optional(houseNumber)
+	n.PrefixDirection = optional(civic.PRD)
+	n.StreetName = optional(civic.RD)
+	n.StreetType = optional(civic.STS)
+	n.SuffixDirection = optional(civic.POD)
+	n.Unit = optional(civic.UNIT)
+	n.City = strings.TrimSpace(civic.A3)
+	n.StateCode = strings.ToUpper(strings.TrimSpace(civic.A1))
+	n.ZipCode = optional(civic.PC)
+	n.PlaceName = optional(firstNonEmpty(civic.NAM, i.Location.CommonPlaceName, civic.LMK))
+	n.LocationInfo = optional(joinNonEmpty(", ", civic.BLD, floor(civic.FLR), civic.ROOM, civic.LOC))
+	n.CrossStreets = strings.TrimSpace(i.Location.CrossStreets)
+
+	if position := firstNonEmpty(i.Location.Point, i.Location.Circle); position != "" {
+		latitude, longitude, err := parsePosition(position)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Location: %w", err))
+		} else if latitude != 0 || longitude != 0 {
+			n.Latitude = &latitude
+			n.Longitude = &longitude
+		}
+	}
+
+	var narrative []string
+	for _, entry := range i.Narrative {
+		text := strings.TrimSpace(entry.Text)
+		if text == "" {
+			continue
+		}
+		if prefix := joinNonEmpty(" ", entry.Time, entry.Author); prefix != "" {
+			text = "[" + prefix + "] " + text
+		}
+		narrative = append(narrative, text)
+	}
+	n.Narratives = optional(strings.Join(narrative, "\n"))
+
+	for index, unit := range i.Units {
+		name := fmt.Sprintf("Units/Unit[%d]", index+1)
+		status := unit.StatusTimes
+		apparatus := firstdue.NfirsNotificationApparatus{
+			UnitCode:               strings.TrimSpace(unit.UnitID),
+			IsAid:                  isTrue(unit.MutualAid),
+			DispatchAt:             parse(name+"/Dispatched", status.Dispatched),
+			DispatchAcknowledgedAt: parse(name+"/Acknowledged", status.Acknowledged),
+			EnrouteAt:              parse(name+"/Enroute", status.Enroute),
+			ArriveAt:               parse(name+"/OnScene", status.OnScene, status.Arrived),
+			ClearAt:                parse(name+"/Cleared", status.Cleared, status.Available),
+			BackInServiceAt:        parse(name+"/InQuarters", status.InQuarters),
+			CanceledAt:             parse(name+"/Cancelled", status.Cancelled, status.Canceled),
+		}
+		record.Apparatuses = append(record.Apparatuses, apparatus)
+
+		// Without a first dispatch time, use the earliest unit dispatch.
+		if dispatched := time.Time(apparatus.DispatchAt); !dispatched.IsZero() && times.FirstDispatched == "" {
+			if n.DispatchNotifiedAt.IsZero() || dispatched.Before(time.Time(n.DispatchNotifiedAt)) {
+				n.DispatchNotifiedAt = apparatus.DispatchAt
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		errs = mapping.Check(record)
+	}
+	return record, errors.Join(errs...)
+}
+
+// parsePosition parses a GML position in "latitude longitude" order.
+func parsePosition(position string) (firstdue.StringFloat64, firstdue.StringFloat64, error) {
+	parts := strings.Fields(position)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("invalid position %q: expected a latitude and a longitude", position)
+	}
+	latitude, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return 0, 0, fmt.Errorf("invalid latitude %q", parts[0])
+	}
+	longitude, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || longitude < -180 || longitude > 180 {
+		return 0, 0, fmt.Errorf("invalid longitude %q", parts[1])
+	}
+	return firstdue.StringFloat64(latitude), firstdue.StringFloat64(longitude), nil
+}
+
+func floor(value string) string {
+	if value = strings.TrimSpace(value); value != "" {
+		return "Floor " + value
+	}
+	return ""
+}
+
+func isTrue(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "1", "yes", "y":
+		return true
+	}
+	return false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func joinNonEmpty(separator string, values ...string) string {
+	var parts []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, separator)
+}
+
+func optional(value string) *string {
+	if value = strings.TrimSpace(value); value != "" {
+		return &value
+	}
+	return nil
+}
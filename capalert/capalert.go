@@ -0,0 +1,351 @@
+// Package capalert converts First Due dispatches into Common Alerting Protocol (CAP) 1.2 alerts.
+//
+// The alert is shaped by the OASIS CAP 1.2 schema: the elements are in schema order, the times use the CAP date-time
+// format (with a numeric offset, never "Z", and "-00:00" for UTC), and the optional elements are omitted when empty.
+// Each agency has its own sender and defaults; see Agency.
+package capalert
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+	"gopkg.in/yaml.v3"
+)
+
+// Namespace is the CAP 1.2 XML namespace.
+const Namespace = "urn:oasis:names:tc:emergency:cap:1.2"
+
+// TimeFormat is the CAP date-time format.  Use FormatTime rather than formatting with it directly, since it writes
+// UTC as "+00:00".
+const TimeFormat = "2006-01-02T15:04:05-07:00"
+
+// FormatTime formats a time in the CAP date-time format.
+//
+// CAP 1.2 (section 3.3.2) requires UTC to be written as "-00:00", so that is used for any time with a zero offset.
+func FormatTime(t time.Time) string {
+	value := t.Format(TimeFormat)
+	if strings.HasSuffix(value, "+00:00") {
+		value = strings.TrimSuffix(value, "+00:00") + "-00:00"
+	}
+	return value
+}
+
+// ErrSuppressed is returned for a dispatch whose incident type the agency does not broadcast.
+var ErrSuppressed = errors.New("the incident type is suppressed")
+
+// Alert is a CAP alert message.
+type Alert struct {
+	XMLName     xml.Name `xml:"urn:oasis:names:tc:emergency:cap:1.2 alert"`
+	Identifier  string   `xml:"identifier"`
+	Sender      string   `xml:"sender"`
+	Sent        string   `xml:"sent"`
+	Status      string   `xml:"status"`
+	MsgType     string   `xml:"msgType"`
+	Source      string   `xml:"source,omitempty"`
+	Scope       string   `xml:"scope"`
+	Restriction string   `xml:"restriction,omitempty"`
+	Addresses   string   `xml:"addresses,omitempty"`
+	Code        []string `xml:"code,omitempty"`
+	Note        string   `xml:"note,omitempty"`
+	References  string   `xml:"references,omitempty"`
+	Incidents   string   `xml:"incidents,omitempty"`
+	Info        []Info   `xml:"info"`
+}
+
+// Info is the information block of an alert.
+type Info struct {
+	Language     string       `xml:"language,omitempty"`
+	Category     []string     `xml:"category"`
+	Event        string       `xml:"event"`
+	ResponseType []string     `xml:"responseType,omitempty"`
+	Urgency      string       `xml:"urgency"`
+	Severity     string       `xml:"severity"`
+	Certainty    string       `xml:"certainty"`
+	Audience     string       `xml:"audience,omitempty"`
+	EventCode    []NamedValue `xml:"eventCode,omitempty"`
+	Effective    string       `xml:"effective,omitempty"`
+	Onset        string       `xml:"onset,omitempty"`
+	Expires      string       `xml:"expires,omitempty"`
+	SenderName   string       `xml:"senderName,omitempty"`
+	Headline     string       `xml:"headline,omitempty"`
+	Description  string       `xml:"description,omitempty"`
+	Instruction  string       `xml:"instruction,omitempty"`
+	Web          string       `xml:"web,omitempty"`
+	Contact      string       `xml:"contact,omitempty"`
+	Parameter    []NamedValue `xml:"parameter,omitempty"`
+	Area         []Area       `xml:"area"`
+}
+
+// NamedValue is a CAP value with its name, as used by eventCode, parameter, and geocode.
+type NamedValue struct {
+	ValueName string `xml:"valueName"`
+	Value     string `xml:"value"`
+}
+
+// Area is the affected area of an alert.
+type Area struct {
+	AreaDesc string       `xml:"areaDesc"`
+	Polygon  []string     `xml:"polygon,omitempty"`
+	Circle   []string     `xml:"circle,omitempty"`
+	Geocode  []NamedValue `xml:"geocode,omitempty"`
+}
+
+// Write writes an alert as an XML document.
+func Write(w io.Writer, alert *Alert) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(alert)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// Config is the CAP settings of each agency, by name.
+type Config struct {
+	Agencies map[string]Agency `yaml:"agencies" json:"agencies"`
+}
+
+// LoadConfig reads the agencies from a YAML or JSON file.
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("error reading CAP config: %w", err)
+	}
+	err = yaml.Unmarshal(contents, &config)
+	if err != nil {
+		return config, fmt.Errorf("error parsing CAP config: %w", err)
+	}
+	return config, nil
+}
+
+// Agency is the sender of the alerts and the defaults for them.
+type Agency struct {
+	Sender       string            `yaml:"sender" json:"sender"`                                   // The CAP sender, such as "dispatch@springfield.example"; required.
+	SenderName   string            `yaml:"sender_name,omitempty" json:"sender_name,omitempty"`     // The human-readable name of the agency.
+	Status       string            `yaml:"status,omitempty" json:"status,omitempty"`               // Defaults to "Actual"; use "Exercise" or "Test" when trying things out.
+	Scope        string            `yaml:"scope,omitempty" json:"scope,omitempty"`                 // Defaults to "Public".
+	Restriction  string            `yaml:"restriction,omitempty" json:"restriction,omitempty"`     // Required when the scope is "Restricted".
+	Language     string            `yaml:"language,omitempty" json:"language,omitempty"`           // Defaults to "en-US".
+	Severity     string            `yaml:"severity,omitempty" json:"severity,omitempty"`           // Defaults to "Unknown".
+	Web          string            `yaml:"web,omitempty" json:"web,omitempty"`                     // A URL with more information.
+	Contact      string            `yaml:"contact,omitempty" json:"contact,omitempty"`             // How to contact the agency.
+	Instruction  string            `yaml:"instruction,omitempty" json:"instruction,omitempty"`     // What the public should do.
+	RadiusKm     float64           `yaml:"radius_km,omitempty" json:"radius_km,omitempty"`         // The radius of the area around the dispatch; defaults to 0.5.
+	Expires      time.Duration     `yaml:"expires,omitempty" json:"expires,omitempty"`             // How long the alert is in effect; defaults to 2 hours.
+	Events       map[string]string `yaml:"events,omitempty" json:"events,omitempty"`               // The event text for each incident type code; defaults to the dispatch type.
+	Categories   map[string]string `yaml:"categories,omitempty" json:"categories,omitempty"`       // The CAP category for incident type codes, by code or pattern; see Category.
+	Suppress     []string          `yaml:"suppress,omitempty" json:"suppress,omitempty"`           // Incident type codes or dispatch types not to broadcast; patterns such as "32*" are allowed.
+	IncludeUnits bool              `yaml:"include_units,omitempty" json:"include_units,omitempty"` // Include the assigned units as a parameter.
+}
+
+// Suppressed returns true if the agency does not broadcast the dispatch's incident type.
+//
+// The patterns are matched without regard to case against the incident type code and the dispatch type.
+func (a Agency) Suppressed(dispatch firstdue.GetDispatchesResponseDispatch) bool {
+	for _, pattern := range a.Suppress {
+		pattern = strings.ToUpper(strings.TrimSpace(pattern))
+		for _, value := range []string{dispatch.IncidentTypeCode, dispatch.Type} {
+			value = strings.ToUpper(strings.TrimSpace(value))
+			if value == "" {
+				continue
+			}
+			if matched, _ := path.Match(pattern, value); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Category returns the CAP category of an incident type code.
+//
+// The agency's categories are checked first, by exact code and then by pattern; otherwise the category follows the
+// NFIRS incident type series.
+func (a Agency) Category(code string) string {
+	code = strings.TrimSpace(code)
+	if category, ok := a.Categories[code]; ok {
+		return category
+	}
+	patterns := make([]string, 0, len(a.Categories))
+	for pattern := range a.Categories {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, code); matched {
+			return a.Categories[pattern]
+		}
+	}
+	if code == "" {
+		return "Other"
+	}
+	switch {
+	case code[0] == '1':
+		return "Fire"
+	case code[0] == '2':
+		return "Safety"
+	case strings.HasPrefix(code, "31"), strings.HasPrefix(code, "32"):
+		return "Health"
+	case code[0] == '3':
+		return "Rescue"
+	case strings.HasPrefix(code, "41"), strings.HasPrefix(code, "42"):
+		return "CBRNE"
+	case strings.HasPrefix(code, "44"):
+		return "Infra"
+	case code[0] == '4':
+		return "Safety"
+	case strings.HasPrefix(code, "811"):
+		return "Geo"
+	case code[0] == '8':
+		return "Met"
+	}
+	return "Other"
+}
+
+// Alert converts a dispatch into an alert.
+//
+// It returns ErrSuppressed if the agency does not broadcast the dispatch's incident type.
+func (a Agency) Alert(dispatch firstdue.GetDispatchesResponseDispatch) (*Alert, error) {
+	if strings.TrimSpace(a.Sender) == "" {
+		return nil, fmt.Errorf("the agency has no sender")
+	}
+	if strings.ContainsAny(a.Sender, " ,<&") {
+		return nil, fmt.Errorf("the sender %q may not contain spaces, commas, or the characters < and &", a.Sender)
+	}
+	if a.Suppressed(dispatch) {
+		return nil, ErrSuppressed
+	}
+
+	sent := time.Time(dispatch.CreatedAt)
+	if sent.IsZero() {
+		sent = time.Now()
+	}
+	sent = sent.Truncate(time.Second)
+	expires := a.Expires
+	if expires <= 0 {
+		expires = 2 * time.Hour
+	}
+	radius := a.RadiusKm
+	if radius <= 0 {
+		radius = 0.5
+	}
+
+	event := a.Events[strings.TrimSpace(dispatch.IncidentTypeCode)]
+	if event == "" {
+		event = firstNonEmpty(dispatch.Type, dispatch.IncidentTypeCode, "Emergency Dispatch")
+	}
+
+	alert := &Alert{
+		Identifier:  identifier(a.Sender, dispatch),
+		Sender:      a.Sender,
+		Sent:        FormatTime(sent),
+		Status:      firstNonEmpty(a.Status, "Actual"),
+		MsgType:     "Alert",
+		Scope:       firstNonEmpty(a.Scope, "Public"),
+		Restriction: a.Restriction,
+		Incidents:   identifierSafe(dispatch.XrefID),
+	}
+	if alert.Scope == "Restricted" && alert.Restriction == "" {
+		return nil, fmt.Errorf("a restriction is required when the scope is Restricted")
+	}
+
+	info := Info{
+		Language:    firstNonEmpty(a.Language, "en-US"),
+		Category:    []string{a.Category(dispatch.IncidentTypeCode)},
+		Event:       event,
+		Urgency:     "Immediate",
+		Severity:    firstNonEmpty(a.Severity, "Unknown"),
+		Certainty:   "Observed",
+		Effective:   FormatTime(sent),
+		Expires:     FormatTime(sent.Add(expires)),
+		SenderName:  a.SenderName,
+		Headline:    headline(event, dispatch.Address),
+		Description: strings.TrimSpace(dispatch.Message),
+		Instruction: a.Instruction,
+		Web:         a.Web,
+		Contact:     a.Contact,
+	}
+	if code := strings.TrimSpace(dispatch.IncidentTypeCode); code != "" {
+		info.EventCode = append(info.EventCode, NamedValue{ValueName: "NFIRS", Value: code})
+	}
+	if a.IncludeUnits && len(dispatch.UnitCodes) > 0 {
+		info.Parameter = append(info.Parameter, NamedValue{ValueName: "units", Value: strings.Join(dispatch.UnitCodes, " ")})
+	}
+
+	area := Area{AreaDesc: areaDescription(dispatch)}
+	if dispatch.Latitude != 0 && dispatch.Longitude != 0 {
+		area.Circle = append(area.Circle, fmt.Sprintf("%s,%s %s",
+			strconv.FormatFloat(dispatch.Latitude, 'f', -1, 64),
+			strconv.FormatFloat(dispatch.Longitude, 'f', -1, 64),
+			strconv.FormatFloat(radius, 'f', -1, 64)))
+	}
+	info.Area = append(info.Area, area)
+	alert.Info = append(alert.Info, info)
+	return alert, nil
+}
+
+// identifier returns a unique identifier for the dispatch's alert.
+func identifier(sender string, dispatch firstdue.GetDispatchesResponseDispatch) string {
+	return identifierSafe(fmt.Sprintf("%s-dispatch-%d", sender, dispatch.ID))
+}
+
+// identifierSafe removes the characters that CAP does not allow in identifiers and lists.
+func identifierSafe(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', ',', '<', '&':
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(value))
+}
+
+func headline(event string, address string) string {
+	text := event
+	if address = strings.TrimSpace(address); address != "" {
+		text += " at " + address
+	}
+	// CAP recommends headlines of 160 characters or less.
+	if runes := []rune(text); len(runes) > 160 {
+		text = string(runes[:157]) + "..."
+	}
+	return text
+}
+
+func areaDescription(dispatch firstdue.GetDispatchesResponseDispatch) string {
+	var parts []string
+	for _, part := range []string{dispatch.Address, dispatch.Address2, dispatch.City, dispatch.StateCode} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return "Unknown location"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package capalert
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+func TestFormatTime(t *testing.T) {
+	for _, test := range []struct {
+		value time.Time
+		want  string
+	}{
+		{time.Date(2026, 10, 19, 13, 30, 5, 0, time.UTC), "2026-10-19T13:30:05-00:00"},
+		{time.Date(2026, 10, 19, 13, 30, 5, 0, time.FixedZone("GMT", 0)), "2026-10-19T13:30:05-00:00"},
+		{time.Date(2026, 10, 19, 8, 30, 5, 0, time.FixedZone("CDT", -5*60*60)), "2026-10-19T08:30:05-05:00"},
+		{time.Date(2026, 10, 19, 19, 0, 5, 0, time.FixedZone("IST", 5*60*60+30*60)), "2026-10-19T19:00:05+05:30"},
+	} {
+		if got := FormatTime(test.value); got != test.want {
+			t.Errorf("got %s, want %s", got, test.want)
+		}
+	}
+}
+
+func TestAlert(t *testing.T) {
+	agency := Agency{Sender: "dispatch@springfield.example", Expires: 90 * time.Minute, IncludeUnits: true}
+	dispatch := firstdue.GetDispatchesResponseDispatch{
+		ID:               42,
+		Type:             "FIRE",
+		Message:          "STRUCTURE FIRE",
+		Address:          "123 N MAIN ST",
+		City:             "SPRINGFIELD",
+		StateCode:        "IL",
+		Latitude:         39.7817,
+		Longitude:        -89.6501,
+		UnitCodes:        []string{"E1", "L2"},
+		IncidentTypeCode: "111",
+		XrefID:           "CAD 5678",
+		CreatedAt:        firstdue.Timestamp(time.Date(2026, 10, 19, 13, 30, 5, 0, time.UTC)),
+	}
+	alert, err := agency.Alert(dispatch)
+	if err != nil {
+		t.Fatalf("Alert: %v", err)
+	}
+	var buffer bytes.Buffer
+	if err := Write(&buffer, alert); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	// Read the document back to check what was written rather than the struct.
+	var parsed Alert
+	if err := xml.Unmarshal(buffer.Bytes(), &parsed); err != nil {
+		t.Fatalf("xml.Unmarshal: %v\n%s", err, buffer.String())
+	}
+	if parsed.Sent != "2026-10-19T13:30:05-00:00" || parsed.Identifier != "dispatch@springfield.example-dispatch-42" || parsed.Incidents != "CAD_5678" {
+		t.Errorf("unexpected alert: %+v", parsed)
+	}
+	if len(parsed.Info) != 1 {
+		t.Fatalf("expected 1 info block, found %d", len(parsed.Info))
+	}
+	info := parsed.Info[0]
+	if info.Effective != "2026-10-19T13:30:05-00:00" || info.Expires != "2026-10-19T15:00:05-00:00" {
+		t.Errorf("got effective %s and expires %s", info.Effective, info.Expires)
+	}
+	if info.Category[0] != "Fire" || info.Event != "FIRE" || len(info.Area) != 1 || info.Area[0].Circle[0] != "39.7817,-89.6501 0.5" {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	if len(info.Parameter) != 1 || info.Parameter[0].Value != "E1 L2" {
+		t.Errorf("unexpected parameters: %+v", info.Parameter)
+	}
+	if strings.Contains(buffer.String(), "+00:00") || strings.Contains(buffer.String(), "Z<") {
+		t.Errorf("the alert has a UTC time that is not written as -00:00:\n%s", buffer.String())
+	}
+
+	// A dispatch with only one of its coordinates has no circle.
+	for _, coordinates := range [][2]float64{{39.7817, 0}, {0, -89.6501}, {0, 0}} {
+		located := dispatch
+		located.Latitude, located.Longitude = coordinates[0], coordinates[1]
+		alert, err := agency.Alert(located)
+		if err != nil {
+			t.Fatalf("Alert: %v", err)
+		}
+		if area := alert.Info[0].Area[0]; len(area.Circle) != 0 {
+			t.Errorf("%v: unexpected circle %q", coordinates, area.Circle)
+		}
+	}
+
+	agency.Suppress = []string{"1*"}
+	if _, err := agency.Alert(dispatch); err != ErrSuppressed {
+		t.Errorf("got %v, want ErrSuppressed", err)
+	}
+}
@@ -3,12 +3,17 @@ package firstdue
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 
 	"github.com/google/go-querystring/query"
 )
 
 type GetApparatusesRequest struct {
+	Page    int    `url:"page,omitempty"`
+	PerPage int    `url:"per_page,omitempty"`
+	Name    string `url:"name,omitempty"`     // Only include apparatuses whose names match this.
+	UseCode string `url:"use_code,omitempty"` // Only include apparatuses with this use code.
 }
 
 type GetApparatusesResponse struct {
@@ -35,7 +40,24 @@ func (c *Client) GetApparatuses(ctx context.Context, input GetApparatusesRequest
 	}
 	err = c.Raw(ctx, http.MethodGet, path, nil, &output)
 	if err != nil {
-		return output, fmt.Errorf("getdispatches: %w", err)
+		return output, fmt.Errorf("getapparatuses: %w", err)
 	}
 	return output, nil
 }
+
+// IterateApparatuses iterates over the apparatuses on every page.
+//
+// The page in the input is ignored; iteration always starts at the first page.  If the number of apparatuses does not
+// match the total reported by the API, the final error will wrap ErrTotalMismatch.
+func (c *Client) IterateApparatuses(ctx context.Context, input GetApparatusesRequest) iter.Seq2[GetApparatusesResponseApparatus, error] {
+	return paginate(func(page int) ([]GetApparatusesResponseApparatus, int, error) {
+		input.Page = page
+		output, err := c.GetApparatuses(ctx, input)
+		return output.List, output.Total, err
+	})
+}
+
+// GetAllApparatuses returns the apparatuses on every page.
+func (c *Client) GetAllApparatuses(ctx context.Context, input GetApparatusesRequest) ([]GetApparatusesResponseApparatus, error) {
+	return collect(c.IterateApparatuses(ctx, input))
+}
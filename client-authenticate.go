@@ -19,17 +19,28 @@ type PostAuthTokenResponse struct {
 	Scope       string `json:"scope"`
 }
 
+// PostAuthToken requests an access token.
+//
+// Unlike Authenticate, this does not change the token that the client uses.
+func (c *Client) PostAuthToken(ctx context.Context, input PostAuthTokenRequest) (output PostAuthTokenResponse, err error) {
+	err = c.Raw(ctx, http.MethodPost, "/v1/auth/token", input, &output)
+	if err != nil {
+		return output, fmt.Errorf("postauthtoken: %w", err)
+	}
+	return output, nil
+}
+
 func (c *Client) Authenticate(ctx context.Context, username string, password string) error {
 	input := PostAuthTokenRequest{
 		GrantType: "client_credentials",
 		Email:     username,
 		Password:  password,
 	}
-	var output PostAuthTokenResponse
-	err := c.Raw(ctx, http.MethodPost, "/v1/auth/token", input, &output)
+	output, err := c.PostAuthToken(ctx, input)
 	if err != nil {
 		return fmt.Errorf("authenticate: %w", err)
 	}
 	c.config.Token = output.AccessToken
+	c.username = username
 	return nil
 }
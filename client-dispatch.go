@@ -3,6 +3,7 @@ package firstdue
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 
 	"github.com/google/go-querystring/query"
@@ -13,7 +14,9 @@ type GetDispatchesRequest struct {
 	Since Timestamp `url:"since,omitempty"`
 }
 
-type GetDispatchesResponse []struct {
+type GetDispatchesResponse []GetDispatchesResponseDispatch
+
+type GetDispatchesResponseDispatch struct {
 	ID               int       `json:"id"`
 	Type             string    `json:"type"`
 	Message          string    `json:"message"`
@@ -45,3 +48,50 @@ func (c *Client) GetDispatches(ctx context.Context, input GetDispatchesRequest)
 	}
 	return output, nil
 }
+
+// IterateDispatches iterates over the dispatches on every page.
+//
+// The page in the input is ignored; iteration always starts at the first page.  The API does not report a total, so
+// iteration stops at the first empty page.
+func (c *Client) IterateDispatches(ctx context.Context, input GetDispatchesRequest) iter.Seq2[GetDispatchesResponseDispatch, error] {
+	return paginateUntilEmpty(func(page int) ([]GetDispatchesResponseDispatch, error) {
+		input.Page = page
+		return c.GetDispatches(ctx, input)
+	}, func(dispatch GetDispatchesResponseDispatch) int {
+		return dispatch.ID
+	})
+}
+
+// GetAllDispatches returns the dispatches on every page.
+func (c *Client) GetAllDispatches(ctx context.Context, input GetDispatchesRequest) ([]GetDispatchesResponseDispatch, error) {
+	return collect(c.IterateDispatches(ctx, input))
+}
+
+type PostDispatchesRequest struct {
+	Type             string    `json:"type"`
+	Message          string    `json:"message"`
+	Address          string    `json:"address"`
+	Address2         string    `json:"address2,omitempty"`
+	City             string    `json:"city,omitempty"`
+	StateCode        string    `json:"state_code,omitempty"`
+	Latitude         float64   `json:"latitude,omitempty"`
+	Longitude        float64   `json:"longitude,omitempty"`
+	UnitCodes        []string  `json:"unit_codes,omitempty"`
+	IncidentTypeCode string    `json:"incident_type_code,omitempty"`
+	StatusCode       string    `json:"status_code,omitempty"`
+	XrefID           string    `json:"xref_id,omitempty"`
+	CreatedAt        Timestamp `json:"created_at,omitzero"`
+}
+
+type PostDispatchesResponse GetDispatchesResponseDispatch
+
+// PostDispatches creates a dispatch.
+//
+// This endpoint is not in the published API documentation, so its shape is assumed; see Endpoints.
+func (c *Client) PostDispatches(ctx context.Context, input PostDispatchesRequest) (output PostDispatchesResponse, err error) {
+	err = c.Raw(ctx, http.MethodPost, "/v1/dispatches", input, &output)
+	if err != nil {
+		return output, fmt.Errorf("postdispatches: %w", err)
+	}
+	return output, nil
+}
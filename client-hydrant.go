@@ -0,0 +1,200 @@
+package firstdue
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+
+	"github.com/google/go-querystring/query"
+)
+
+// Hydrant is a hydrant in the water-supply inventory.
+//
+// The hydrant endpoints are not in the published API documentation, so their shape is assumed; see Endpoints.
+type Hydrant struct {
+	ID                  uint64           `json:"id,omitempty"`
+	Number              string           `json:"number"` // The hydrant number, as painted on the hydrant.
+	HydrantType         string           `json:"hydrant_type"`
+	StatusCode          string           `json:"status_code"` // Such as "in_service" or "out_of_service".
+	Address             string           `json:"address"`
+	LocationDescription *string          `json:"location_description"`
+	City                string           `json:"city"`
+	StateCode           string           `json:"state_code"`
+	Latitude            *FlexibleFloat64 `json:"latitude"`
+	Longitude           *FlexibleFloat64 `json:"longitude"`
+	WaterSystem         *string          `json:"water_system"`
+	MainSize            *FlexibleFloat64 `json:"main_size"`  // The diameter of the main, in inches.
+	NFPAClass           *string          `json:"nfpa_class"` // The NFPA 291 class: AA, A, B, or C.
+	Manufacturer        *string          `json:"manufacturer"`
+	Model               *string          `json:"model"`
+	YearInstalled       *int             `json:"year_installed"`
+	Notes               *string          `json:"notes"`
+	CreatedAt           Timestamp        `json:"created_at,omitzero"`
+	UpdatedAt           Timestamp        `json:"updated_at,omitzero"`
+}
+
+// HydrantFlowTest is a flow test of a hydrant.
+//
+// Pressures are in PSI, flows in gallons per minute, and diameters in inches.
+type HydrantFlowTest struct {
+	ID               uint64           `json:"id,omitempty"`
+	HydrantID        uint64           `json:"hydrant_id,omitempty"`
+	TestedAt         Timestamp        `json:"tested_at"`
+	TestedBy         *string          `json:"tested_by"`
+	StaticPressure   *FlexibleFloat64 `json:"static_pressure"`
+	ResidualPressure *FlexibleFloat64 `json:"residual_pressure"`
+	PitotPressure    *FlexibleFloat64 `json:"pitot_pressure"`
+	OutletDiameter   *FlexibleFloat64 `json:"outlet_diameter"`
+	FlowRate         *FlexibleFloat64 `json:"flow_rate"`
+	AvailableFlow    *FlexibleFloat64 `json:"available_flow"` // The flow available at 20 PSI residual pressure.
+	Notes            *string          `json:"notes"`
+	CreatedAt        Timestamp        `json:"created_at,omitzero"`
+}
+
+type GetHydrantsRequest struct {
+	Page       int       `url:"page,omitempty"`
+	PerPage    int       `url:"per_page,omitempty"`
+	Number     string    `url:"number,omitempty"`      // Only include hydrants whose numbers match this.
+	StatusCode string    `url:"status_code,omitempty"` // Only include hydrants with this status.
+	Since      Timestamp `url:"since,omitempty"`       // Only include hydrants updated at or after this time.
+}
+
+type GetHydrantsResponse struct {
+	List  []Hydrant `json:"list"`
+	Total int       `json:"total"`
+}
+
+func (c *Client) GetHydrants(ctx context.Context, input GetHydrantsRequest) (output GetHydrantsResponse, err error) {
+	values, err := query.Values(input)
+	if err != nil {
+		return output, err
+	}
+	path := "/v1/hydrants"
+	if q := values.Encode(); q != "" {
+		path += "?" + q
+	}
+	err = c.Raw(ctx, http.MethodGet, path, nil, &output)
+	if err != nil {
+		return output, fmt.Errorf("gethydrants: %w", err)
+	}
+	return output, nil
+}
+
+// IterateHydrants iterates over the hydrants on every page.
+//
+// The page in the input is ignored; iteration always starts at the first page.  If the number of hydrants does not
+// match the total reported by the API, the final error will wrap ErrTotalMismatch.
+func (c *Client) IterateHydrants(ctx context.Context, input GetHydrantsRequest) iter.Seq2[Hydrant, error] {
+	return paginate(func(page int) ([]Hydrant, int, error) {
+		input.Page = page
+		output, err := c.GetHydrants(ctx, input)
+		return output.List, output.Total, err
+	})
+}
+
+// GetAllHydrants returns the hydrants on every page.
+func (c *Client) GetAllHydrants(ctx context.Context, input GetHydrantsRequest) ([]Hydrant, error) {
+	return collect(c.IterateHydrants(ctx, input))
+}
+
+type GetHydrantsIDResponse Hydrant
+
+func (c *Client) GetHydrantsID(ctx context.Context, id uint64) (output GetHydrantsIDResponse, err error) {
+	err = c.Raw(ctx, http.MethodGet, fmt.Sprintf("/v1/hydrants/%d", id), nil, &output)
+	if err != nil {
+		return output, fmt.Errorf("gethydrantsid: %w", err)
+	}
+	return output, nil
+}
+
+type PostHydrantsRequest Hydrant
+
+type PostHydrantsResponse struct {
+	ID StringUint64 `json:"id"`
+}
+
+func (c *Client) PostHydrants(ctx context.Context, input PostHydrantsRequest) (output PostHydrantsResponse, err error) {
+	err = c.Raw(ctx, http.MethodPost, "/v1/hydrants", input, &output)
+	if err != nil {
+		return output, fmt.Errorf("posthydrants: %w", err)
+	}
+	return output, nil
+}
+
+type PutHydrantsIDRequest Hydrant
+
+func (c *Client) PutHydrantsID(ctx context.Context, id uint64, input PutHydrantsIDRequest) error {
+	err := c.Raw(ctx, http.MethodPut, fmt.Sprintf("/v1/hydrants/%d", id), input, nil)
+	if err != nil {
+		return fmt.Errorf("puthydrantsid: %w", err)
+	}
+	return nil
+}
+
+type GetHydrantsIDFlowTestsRequest struct {
+	Page    int `url:"page,omitempty"`
+	PerPage int `url:"per_page,omitempty"`
+}
+
+type GetHydrantsIDFlowTestsResponse struct {
+	List  []HydrantFlowTest `json:"list"`
+	Total int               `json:"total"`
+}
+
+func (c *Client) GetHydrantsIDFlowTests(ctx context.Context, id uint64, input GetHydrantsIDFlowTestsRequest) (output GetHydrantsIDFlowTestsResponse, err error) {
+	values, err := query.Values(input)
+	if err != nil {
+		return output, err
+	}
+	path := fmt.Sprintf("/v1/hydrants/%d/flow-tests", id)
+	if q := values.Encode(); q != "" {
+		path += "?" + q
+	}
+	err = c.Raw(ctx, http.MethodGet, path, nil, &output)
+	if err != nil {
+		return output, fmt.Errorf("gethydrantsidflowtests: %w", err)
+	}
+	return output, nil
+}
+
+// IterateHydrantFlowTests iterates over the flow tests of a hydrant on every page.
+//
+// The page in the input is ignored; iteration always starts at the first page.  If the number of flow tests does
+// not match the total reported by the API, the final error will wrap ErrTotalMismatch.
+func (c *Client) IterateHydrantFlowTests(ctx context.Context, id uint64, input GetHydrantsIDFlowTestsRequest) iter.Seq2[HydrantFlowTest, error] {
+	return paginate(func(page int) ([]HydrantFlowTest, int, error) {
+		input.Page = page
+		output, err := c.GetHydrantsIDFlowTests(ctx, id, input)
+		return output.List, output.Total, err
+	})
+}
+
+// GetAllHydrantFlowTests returns the flow tests of a hydrant on every page.
+func (c *Client) GetAllHydrantFlowTests(ctx context.Context, id uint64, input GetHydrantsIDFlowTestsRequest) ([]HydrantFlowTest, error) {
+	return collect(c.IterateHydrantFlowTests(ctx, id, input))
+}
+
+type PostHydrantsIDFlowTestsRequest HydrantFlowTest
+
+type PostHydrantsIDFlowTestsResponse struct {
+	ID StringUint64 `json:"id"`
+}
+
+func (c *Client) PostHydrantsIDFlowTests(ctx context.Context, id uint64, input PostHydrantsIDFlowTestsRequest) (output PostHydrantsIDFlowTestsResponse, err error) {
+	err = c.Raw(ctx, http.MethodPost, fmt.Sprintf("/v1/hydrants/%d/flow-tests", id), input, &output)
+	if err != nil {
+		return output, fmt.Errorf("posthydrantsidflowtests: %w", err)
+	}
+	return output, nil
+}
+
+type PutHydrantsIDFlowTestsIDRequest HydrantFlowTest
+
+func (c *Client) PutHydrantsIDFlowTestsID(ctx context.Context, id uint64, flowTestID uint64, input PutHydrantsIDFlowTestsIDRequest) error {
+	err := c.Raw(ctx, http.MethodPut, fmt.Sprintf("/v1/hydrants/%d/flow-tests/%d", id, flowTestID), input, nil)
+	if err != nil {
+		return fmt.Errorf("puthydrantsidflowtestsid: %w", err)
+	}
+	return nil
+}
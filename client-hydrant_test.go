@@ -0,0 +1,129 @@
+package firstdue_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+func number(value float64) *firstdue.FlexibleFloat64 {
+	return (*firstdue.FlexibleFloat64)(&value)
+}
+
+func TestHydrants(t *testing.T) {
+	ctx := context.Background()
+	var requests []string
+	client := newMockClient(t, &requests)
+
+	output, err := client.GetHydrants(ctx, firstdue.GetHydrantsRequest{StatusCode: "out_of_service"})
+	if err != nil {
+		t.Fatalf("GetHydrants: %v", err)
+	}
+	if output.Total != 1 || len(output.List) != 1 || output.List[0].Number != "H-103" || output.List[0].Latitude != nil {
+		t.Fatalf("unexpected hydrants: %+v", output)
+	}
+
+	// Two per page means that the three hydrants take two requests.
+	requests = nil
+	var numbers []string
+	for hydrant, err := range client.IterateHydrants(ctx, firstdue.GetHydrantsRequest{PerPage: 2}) {
+		if err != nil {
+			t.Fatalf("IterateHydrants: %v", err)
+		}
+		numbers = append(numbers, hydrant.Number)
+	}
+	if len(numbers) != 3 || numbers[0] != "H-101" || numbers[2] != "H-103" || len(requests) != 2 {
+		t.Fatalf("got hydrants %v in %d requests", numbers, len(requests))
+	}
+
+	created, err := client.PostHydrants(ctx, firstdue.PostHydrantsRequest{Number: "H-104", HydrantType: "dry_barrel", StatusCode: "in_service", Address: "5 ELM ST", City: "Washington", StateCode: "DC", Latitude: number(38.89), Longitude: number(-77.03)})
+	if err != nil {
+		t.Fatalf("PostHydrants: %v", err)
+	}
+	if _, err := client.PostHydrants(ctx, firstdue.PostHydrantsRequest{Number: "h-104"}); err == nil {
+		t.Errorf("expected an error for a duplicate hydrant number")
+	}
+	id := uint64(created.ID)
+	err = client.PutHydrantsID(ctx, id, firstdue.PutHydrantsIDRequest{Number: "H-104", HydrantType: "dry_barrel", StatusCode: "out_of_service", Address: "5 ELM ST", City: "Washington", StateCode: "DC", Latitude: number(38.89), Longitude: number(-77.03)})
+	if err != nil {
+		t.Fatalf("PutHydrantsID: %v", err)
+	}
+	hydrant, err := client.GetHydrantsID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetHydrantsID: %v", err)
+	}
+	if hydrant.StatusCode != "out_of_service" || hydrant.Latitude == nil || *hydrant.Latitude != 38.89 || hydrant.CreatedAt.IsZero() {
+		t.Errorf("unexpected hydrant: %+v", hydrant)
+	}
+	if _, err := client.GetHydrantsID(ctx, 999); err == nil {
+		t.Errorf("expected an error for a missing hydrant")
+	}
+}
+
+func TestHydrantFlowTests(t *testing.T) {
+	ctx := context.Background()
+	client := newMockClient(t, nil)
+
+	flowTests, err := client.GetAllHydrantFlowTests(ctx, 1, firstdue.GetHydrantsIDFlowTestsRequest{})
+	if err != nil {
+		t.Fatalf("GetAllHydrantFlowTests: %v", err)
+	}
+	if len(flowTests) != 1 || flowTests[0].HydrantID != 1 || flowTests[0].AvailableFlow == nil || *flowTests[0].AvailableFlow != 2100 {
+		t.Fatalf("unexpected flow tests: %+v", flowTests)
+	}
+
+	testedAt := firstdue.Timestamp(time.Date(2026, 4, 2, 10, 0, 0, 0, time.UTC))
+	if _, err := client.PostHydrantsIDFlowTests(ctx, 2, firstdue.PostHydrantsIDFlowTestsRequest{StaticPressure: number(70)}); err == nil {
+		t.Errorf("expected an error for a flow test without a time")
+	}
+	created, err := client.PostHydrantsIDFlowTests(ctx, 2, firstdue.PostHydrantsIDFlowTestsRequest{TestedAt: testedAt, StaticPressure: number(70), ResidualPressure: number(55)})
+	if err != nil {
+		t.Fatalf("PostHydrantsIDFlowTests: %v", err)
+	}
+	err = client.PutHydrantsIDFlowTestsID(ctx, 2, uint64(created.ID), firstdue.PutHydrantsIDFlowTestsIDRequest{StaticPressure: number(71), ResidualPressure: number(56)})
+	if err != nil {
+		t.Fatalf("PutHydrantsIDFlowTestsID: %v", err)
+	}
+	if err := client.PutHydrantsIDFlowTestsID(ctx, 1, uint64(created.ID), firstdue.PutHydrantsIDFlowTestsIDRequest{}); err == nil {
+		t.Errorf("expected an error for a flow test of another hydrant")
+	}
+
+	var found []firstdue.HydrantFlowTest
+	for flowTest, err := range client.IterateHydrantFlowTests(ctx, 2, firstdue.GetHydrantsIDFlowTestsRequest{}) {
+		if err != nil {
+			t.Fatalf("IterateHydrantFlowTests: %v", err)
+		}
+		found = append(found, flowTest)
+	}
+	if len(found) != 1 || found[0].ID != uint64(created.ID) || !time.Time(found[0].TestedAt).Equal(time.Time(testedAt)) || *found[0].StaticPressure != 71 {
+		t.Errorf("unexpected flow tests: %+v", found)
+	}
+}
+
+func TestHydrantEmptyCoordinates(t *testing.T) {
+	ctx := context.Background()
+	client := newMockClient(t, nil)
+
+	// An empty coordinate decodes as a non-nil zero, which the mock stores as no location.
+	var hydrant firstdue.Hydrant
+	if err := json.Unmarshal([]byte(`{"number":"H-105","latitude":"","longitude":"-77.03"}`), &hydrant); err != nil {
+		t.Fatal(err)
+	}
+	if hydrant.Latitude == nil || *hydrant.Latitude != 0 {
+		t.Fatalf("unexpected latitude: %v", hydrant.Latitude)
+	}
+	created, err := client.PostHydrants(ctx, firstdue.PostHydrantsRequest(hydrant))
+	if err != nil {
+		t.Fatalf("PostHydrants: %v", err)
+	}
+	output, err := client.GetHydrantsID(ctx, uint64(created.ID))
+	if err != nil {
+		t.Fatalf("GetHydrantsID: %v", err)
+	}
+	if output.Latitude != nil || output.Longitude != nil {
+		t.Errorf("got location %v, %v; want none", output.Latitude, output.Longitude)
+	}
+}
@@ -0,0 +1,127 @@
+package firstdue
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+
+	"github.com/google/go-querystring/query"
+)
+
+// Inspection is a fire inspection of an occupancy.
+//
+// The inspection endpoints are not in the published API documentation, so their shape is assumed; see Endpoints.
+type Inspection struct {
+	ID             uint64                `json:"id,omitempty"`
+	OccupancyID    uint64                `json:"occupancy_id"`    // The inspected occupancy, from GetOccupancies.
+	InspectionType string                `json:"inspection_type"` // Such as "annual", "reinspection", or "complaint".
+	StatusCode     string                `json:"status_code"`     // Such as "scheduled", "completed", or "canceled".
+	Result         *string               `json:"result"`          // Such as "pass" or "fail", once completed.
+	InspectorUUID  *string               `json:"inspector_uuid"`
+	InspectorName  *string               `json:"inspector_name"`
+	ScheduledAt    Timestamp             `json:"scheduled_at,omitzero"`
+	CompletedAt    Timestamp             `json:"completed_at,omitzero"`
+	Violations     []InspectionViolation `json:"violations"`
+	Notes          *string               `json:"notes"`
+	CreatedAt      Timestamp             `json:"created_at,omitzero"`
+	UpdatedAt      Timestamp             `json:"updated_at,omitzero"`
+}
+
+type InspectionViolation struct {
+	ID          uint64    `json:"id,omitempty"`
+	Code        string    `json:"code"` // The code section, such as "IFC 906.1".
+	Description string    `json:"description"`
+	Location    *string   `json:"location"`
+	StatusCode  string    `json:"status_code"` // Such as "open" or "corrected".
+	CorrectBy   Timestamp `json:"correct_by,omitzero"`
+	CorrectedAt Timestamp `json:"corrected_at,omitzero"`
+	Notes       *string   `json:"notes"`
+}
+
+// GetInspectionsRequest filters the inspections.
+//
+// Each date range includes its start and excludes its end; either end may be left open.
+type GetInspectionsRequest struct {
+	Page            int       `url:"page,omitempty"`
+	PerPage         int       `url:"per_page,omitempty"`
+	OccupancyID     uint64    `url:"occupancy_id,omitempty"`
+	StatusCode      string    `url:"status_code,omitempty"`
+	InspectorUUID   string    `url:"inspector_uuid,omitempty"`
+	ScheduledAfter  Timestamp `url:"scheduled_after,omitempty"`  // Only include inspections scheduled at or after this time.
+	ScheduledBefore Timestamp `url:"scheduled_before,omitempty"` // Only include inspections scheduled before this time.
+	CompletedAfter  Timestamp `url:"completed_after,omitempty"`  // Only include inspections completed at or after this time.
+	CompletedBefore Timestamp `url:"completed_before,omitempty"` // Only include inspections completed before this time.
+}
+
+type GetInspectionsResponse struct {
+	List  []Inspection `json:"list"`
+	Total int          `json:"total"`
+}
+
+func (c *Client) GetInspections(ctx context.Context, input GetInspectionsRequest) (output GetInspectionsResponse, err error) {
+	values, err := query.Values(input)
+	if err != nil {
+		return output, err
+	}
+	path := "/v1/inspections"
+	if q := values.Encode(); q != "" {
+		path += "?" + q
+	}
+	err = c.Raw(ctx, http.MethodGet, path, nil, &output)
+	if err != nil {
+		return output, fmt.Errorf("getinspections: %w", err)
+	}
+	return output, nil
+}
+
+// IterateInspections iterates over the inspections on every page.
+//
+// The page in the input is ignored; iteration always starts at the first page.  If the number of inspections does
+// not match the total reported by the API, the final error will wrap ErrTotalMismatch.
+func (c *Client) IterateInspections(ctx context.Context, input GetInspectionsRequest) iter.Seq2[Inspection, error] {
+	return paginate(func(page int) ([]Inspection, int, error) {
+		input.Page = page
+		output, err := c.GetInspections(ctx, input)
+		return output.List, output.Total, err
+	})
+}
+
+// GetAllInspections returns the inspections on every page.
+func (c *Client) GetAllInspections(ctx context.Context, input GetInspectionsRequest) ([]Inspection, error) {
+	return collect(c.IterateInspections(ctx, input))
+}
+
+type GetInspectionsIDResponse Inspection
+
+func (c *Client) GetInspectionsID(ctx context.Context, id uint64) (output GetInspectionsIDResponse, err error) {
+	err = c.Raw(ctx, http.MethodGet, fmt.Sprintf("/v1/inspections/%d", id), nil, &output)
+	if err != nil {
+		return output, fmt.Errorf("getinspectionsid: %w", err)
+	}
+	return output, nil
+}
+
+type PostInspectionsRequest Inspection
+
+type PostInspectionsResponse struct {
+	ID StringUint64 `json:"id"`
+}
+
+func (c *Client) PostInspections(ctx context.Context, input PostInspectionsRequest) (output PostInspectionsResponse, err error) {
+	err = c.Raw(ctx, http.MethodPost, "/v1/inspections", input, &output)
+	if err != nil {
+		return output, fmt.Errorf("postinspections: %w", err)
+	}
+	return output, nil
+}
+
+type PutInspectionsIDRequest Inspection
+
+func (c *Client) PutInspectionsID(ctx context.Context, id uint64, input PutInspectionsIDRequest) error {
+	err := c.Raw(ctx, http.MethodPut, fmt.Sprintf("/v1/inspections/%d", id), input, nil)
+	if err != nil {
+		return fmt.Errorf("putinspectionsid: %w", err)
+	}
+	return nil
+}
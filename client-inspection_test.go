@@ -0,0 +1,92 @@
+package firstdue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+func TestInspectionDateRanges(t *testing.T) {
+	ctx := context.Background()
+	var requests []string
+	client := newMockClient(t, &requests)
+	inspected := time.Date(2024, 9, 12, 14, 30, 0, 0, time.UTC)
+	east := time.FixedZone("+02:00", 2*60*60)
+
+	// The times keep their offsets, so the "+" must be escaped; the start of each range is inclusive.
+	output, err := client.GetInspections(ctx, firstdue.GetInspectionsRequest{
+		StatusCode:      "completed",
+		CompletedAfter:  firstdue.Timestamp(inspected.In(east)),
+		CompletedBefore: firstdue.Timestamp(inspected.Add(time.Second)),
+	})
+	if err != nil {
+		t.Fatalf("GetInspections: %v", err)
+	}
+	if want := "/v1/inspections?completed_after=2024-09-12T16%3A30%3A00%2B02%3A00&completed_before=2024-09-12T14%3A30%3A01Z&status_code=completed"; len(requests) != 1 || requests[0] != want {
+		t.Errorf("got requests %v, want %s", requests, want)
+	}
+	if output.Total != 1 || output.List[0].ID != 12 || len(output.List[0].Violations) != 1 {
+		t.Fatalf("unexpected inspections: %+v", output)
+	}
+
+	// The end of each range is exclusive.
+	output, err = client.GetInspections(ctx, firstdue.GetInspectionsRequest{CompletedBefore: firstdue.Timestamp(inspected)})
+	if err != nil {
+		t.Fatalf("GetInspections: %v", err)
+	}
+	if output.Total != 0 {
+		t.Errorf("unexpected inspections: %+v", output)
+	}
+
+	// An open-ended range leaves out the other bound, and skips inspections that were never completed.
+	requests = nil
+	output, err = client.GetInspections(ctx, firstdue.GetInspectionsRequest{ScheduledAfter: firstdue.Timestamp(inspected.Add(time.Hour))})
+	if err != nil {
+		t.Fatalf("GetInspections: %v", err)
+	}
+	if want := "/v1/inspections?scheduled_after=2024-09-12T15%3A30%3A00Z"; requests[0] != want {
+		t.Errorf("got request %s, want %s", requests[0], want)
+	}
+	if output.Total != 1 || output.List[0].ID != 14 || !output.List[0].CompletedAt.IsZero() {
+		t.Errorf("unexpected inspections: %+v", output)
+	}
+	output, err = client.GetInspections(ctx, firstdue.GetInspectionsRequest{CompletedAfter: firstdue.Timestamp(inspected.AddDate(-1, 0, 0))})
+	if err != nil {
+		t.Fatalf("GetInspections: %v", err)
+	}
+	if output.Total != 1 || output.List[0].ID != 12 {
+		t.Errorf("unexpected inspections: %+v", output)
+	}
+}
+
+func TestInspections(t *testing.T) {
+	ctx := context.Background()
+	client := newMockClient(t, nil)
+
+	if _, err := client.PostInspections(ctx, firstdue.PostInspectionsRequest{OccupancyID: 999, InspectionType: "annual"}); err == nil {
+		t.Errorf("expected an error for an inspection of a missing occupancy")
+	}
+	scheduledAt := firstdue.Timestamp(time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC))
+	created, err := client.PostInspections(ctx, firstdue.PostInspectionsRequest{OccupancyID: 5, InspectionType: "reinspection", StatusCode: "scheduled", ScheduledAt: scheduledAt})
+	if err != nil {
+		t.Fatalf("PostInspections: %v", err)
+	}
+	inspection, err := client.GetInspectionsID(ctx, uint64(created.ID))
+	if err != nil {
+		t.Fatalf("GetInspectionsID: %v", err)
+	}
+	inspection.StatusCode = "completed"
+	inspection.CompletedAt = firstdue.Timestamp(time.Time(scheduledAt).Add(time.Hour))
+	if err := client.PutInspectionsID(ctx, inspection.ID, firstdue.PutInspectionsIDRequest(inspection)); err != nil {
+		t.Fatalf("PutInspectionsID: %v", err)
+	}
+	all, err := client.GetAllInspections(ctx, firstdue.GetInspectionsRequest{OccupancyID: 5, StatusCode: "completed", PerPage: 1})
+	if err != nil {
+		t.Fatalf("GetAllInspections: %v", err)
+	}
+	if len(all) != 2 || all[1].ID != inspection.ID || !time.Time(all[1].CompletedAt).Equal(time.Time(inspection.CompletedAt)) {
+		t.Errorf("unexpected inspections: %+v", all)
+	}
+}
@@ -0,0 +1,178 @@
+package firstdue
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+// Occupancy is an occupancy and its pre-plan.
+//
+// The addresses, contacts, and hazards are part of the occupancy; they are replaced as a whole when it is updated.
+//
+// The occupancy endpoints are not in the published API documentation, so their shape is assumed; see Endpoints.
+type Occupancy struct {
+	ID               uint64             `json:"id,omitempty"`
+	Name             string             `json:"name"`
+	OccupancyType    string             `json:"occupancy_type"` // Such as "business" or "assembly".
+	StatusCode       string             `json:"status_code"`
+	StationUUID      *string            `json:"station_uuid"` // The first-due station, from GetStations.
+	Addresses        []OccupancyAddress `json:"addresses"`
+	Contacts         []OccupancyContact `json:"contacts"`
+	Hazards          []OccupancyHazard  `json:"hazards"`
+	ConstructionType *string            `json:"construction_type"`
+	Stories          *FlexibleInt64     `json:"stories"`
+	SquareFootage    *FlexibleFloat64   `json:"square_footage"`
+	YearBuilt        *FlexibleInt64     `json:"year_built"`
+	OccupantLoad     *FlexibleInt64     `json:"occupant_load"`
+	Sprinklered      *bool              `json:"sprinklered"`
+	FireAlarm        *bool              `json:"fire_alarm"`
+	KnoxBoxLocation  *string            `json:"knox_box_location"`
+	PrePlanNotes     *string            `json:"pre_plan_notes"`
+	CreatedAt        Timestamp          `json:"created_at,omitzero"`
+	UpdatedAt        Timestamp          `json:"updated_at,omitzero"`
+}
+
+type OccupancyAddress struct {
+	ID        uint64           `json:"id,omitempty"`
+	IsPrimary bool             `json:"is_primary"`
+	Address   string           `json:"address"`
+	Address2  *string          `json:"address2"`
+	City      string           `json:"city"`
+	StateCode string           `json:"state_code"`
+	ZipCode   *string          `json:"zip_code"`
+	Latitude  *FlexibleFloat64 `json:"latitude"`
+	Longitude *FlexibleFloat64 `json:"longitude"`
+}
+
+type OccupancyContact struct {
+	ID          uint64  `json:"id,omitempty"`
+	Name        string  `json:"name"`
+	Role        *string `json:"role"` // Such as "owner" or "key holder".
+	Phone       *string `json:"phone"`
+	Email       *string `json:"email"`
+	IsEmergency bool    `json:"is_emergency"` // Whether to call this contact after hours.
+}
+
+type OccupancyHazard struct {
+	ID          uint64           `json:"id,omitempty"`
+	HazardType  string           `json:"hazard_type"` // Such as "hazmat", "structural", or "electrical".
+	Description string           `json:"description"`
+	Location    *string          `json:"location"`
+	Quantity    *FlexibleFloat64 `json:"quantity"`
+	Unit        *string          `json:"unit"` // The unit of the quantity, such as "gal".
+	UNNumber    *string          `json:"un_number"`
+}
+
+type GetOccupanciesRequest struct {
+	Page          int       `url:"page,omitempty"`
+	PerPage       int       `url:"per_page,omitempty"`
+	Name          string    `url:"name,omitempty"`           // Only include occupancies whose names match this.
+	OccupancyType string    `url:"occupancy_type,omitempty"` // Only include occupancies of this type.
+	Since         Timestamp `url:"since,omitempty"`          // Only include occupancies updated at or after this time.
+}
+
+type GetOccupanciesResponse struct {
+	List  []Occupancy `json:"list"`
+	Total int         `json:"total"`
+}
+
+func (c *Client) GetOccupancies(ctx context.Context, input GetOccupanciesRequest) (output GetOccupanciesResponse, err error) {
+	values, err := query.Values(input)
+	if err != nil {
+		return output, err
+	}
+	path := "/v1/occupancies"
+	if q := values.Encode(); q != "" {
+		path += "?" + q
+	}
+	err = c.Raw(ctx, http.MethodGet, path, nil, &output)
+	if err != nil {
+		return output, fmt.Errorf("getoccupancies: %w", err)
+	}
+	return output, nil
+}
+
+// IterateOccupancies iterates over the occupancies on every page.
+//
+// The page in the input is ignored; iteration always starts at the first page.  If the number of occupancies does
+// not match the total reported by the API, the final error will wrap ErrTotalMismatch.
+func (c *Client) IterateOccupancies(ctx context.Context, input GetOccupanciesRequest) iter.Seq2[Occupancy, error] {
+	return paginate(func(page int) ([]Occupancy, int, error) {
+		input.Page = page
+		output, err := c.GetOccupancies(ctx, input)
+		return output.List, output.Total, err
+	})
+}
+
+// GetAllOccupancies returns the occupancies on every page.
+func (c *Client) GetAllOccupancies(ctx context.Context, input GetOccupanciesRequest) ([]Occupancy, error) {
+	return collect(c.IterateOccupancies(ctx, input))
+}
+
+// SyncOccupancies returns the occupancies that were updated at or after the given time, along with the time to pass
+// on the next sync.
+//
+// The next time is the latest update time that was seen, or the given time if nothing changed; it comes from the
+// server, so the local clock does not matter.  Because "since" is inclusive, the occupancies updated at exactly that
+// time will be returned again by the next sync, so the caller should apply the changes idempotently.  Use the zero
+// time for the first, full sync.
+func (c *Client) SyncOccupancies(ctx context.Context, since Timestamp) (changed []Occupancy, next Timestamp, err error) {
+	next = since
+	for occupancy, err := range c.IterateOccupancies(ctx, GetOccupanciesRequest{Since: since}) {
+		if err != nil {
+			return changed, since, fmt.Errorf("syncoccupancies: %w", err)
+		}
+		changed = append(changed, occupancy)
+		if time.Time(occupancy.UpdatedAt).After(time.Time(next)) {
+			next = occupancy.UpdatedAt
+		}
+	}
+	return changed, next, nil
+}
+
+type GetOccupanciesIDResponse Occupancy
+
+func (c *Client) GetOccupanciesID(ctx context.Context, id uint64) (output GetOccupanciesIDResponse, err error) {
+	err = c.Raw(ctx, http.MethodGet, fmt.Sprintf("/v1/occupancies/%d", id), nil, &output)
+	if err != nil {
+		return output, fmt.Errorf("getoccupanciesid: %w", err)
+	}
+	return output, nil
+}
+
+type PostOccupanciesRequest Occupancy
+
+type PostOccupanciesResponse struct {
+	ID StringUint64 `json:"id"`
+}
+
+func (c *Client) PostOccupancies(ctx context.Context, input PostOccupanciesRequest) (output PostOccupanciesResponse, err error) {
+	err = c.Raw(ctx, http.MethodPost, "/v1/occupancies", input, &output)
+	if err != nil {
+		return output, fmt.Errorf("postoccupancies: %w", err)
+	}
+	return output, nil
+}
+
+type PutOccupanciesIDRequest Occupancy
+
+func (c *Client) PutOccupanciesID(ctx context.Context, id uint64, input PutOccupanciesIDRequest) error {
+	err := c.Raw(ctx, http.MethodPut, fmt.Sprintf("/v1/occupancies/%d", id), input, nil)
+	if err != nil {
+		return fmt.Errorf("putoccupanciesid: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) DeleteOccupanciesID(ctx context.Context, id uint64) error {
+	err := c.Raw(ctx, http.MethodDelete, fmt.Sprintf("/v1/occupancies/%d", id), nil, nil)
+	if err != nil {
+		return fmt.Errorf("deleteoccupanciesid: %w", err)
+	}
+	return nil
+}
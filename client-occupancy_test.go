@@ -0,0 +1,98 @@
+package firstdue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+func TestSyncOccupancies(t *testing.T) {
+	ctx := context.Background()
+	client := newMockClient(t, nil)
+	installed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// The first sync returns everything, and the next time comes from the fixtures rather than the clock.
+	changed, next, err := client.SyncOccupancies(ctx, firstdue.Timestamp{})
+	if err != nil {
+		t.Fatalf("SyncOccupancies: %v", err)
+	}
+	if len(changed) != 2 || !time.Time(next).Equal(installed) {
+		t.Fatalf("got %d occupancies and next %v", len(changed), time.Time(next))
+	}
+
+	// "since" is inclusive, so the occupancies updated at exactly that time come back.
+	changed, again, err := client.SyncOccupancies(ctx, next)
+	if err != nil {
+		t.Fatalf("SyncOccupancies: %v", err)
+	}
+	if len(changed) != 2 || !time.Time(again).Equal(installed) {
+		t.Fatalf("got %d occupancies and next %v", len(changed), time.Time(again))
+	}
+
+	occupancy, err := client.GetOccupanciesID(ctx, 9)
+	if err != nil {
+		t.Fatalf("GetOccupanciesID: %v", err)
+	}
+	occupancy.PrePlanNotes = new(string)
+	*occupancy.PrePlanNotes = "Stage at the bus loop."
+	if err := client.PutOccupanciesID(ctx, 9, firstdue.PutOccupanciesIDRequest(occupancy)); err != nil {
+		t.Fatalf("PutOccupanciesID: %v", err)
+	}
+	changed, next, err = client.SyncOccupancies(ctx, next)
+	if err != nil {
+		t.Fatalf("SyncOccupancies: %v", err)
+	}
+	if len(changed) != 2 || !time.Time(next).After(installed) {
+		t.Fatalf("got %d occupancies and next %v", len(changed), time.Time(next))
+	}
+	changed, _, err = client.SyncOccupancies(ctx, next)
+	if err != nil {
+		t.Fatalf("SyncOccupancies: %v", err)
+	}
+	if len(changed) != 1 || changed[0].ID != 9 || changed[0].PrePlanNotes == nil || len(changed[0].Addresses) != 1 {
+		t.Fatalf("unexpected occupancies: %+v", changed)
+	}
+
+	// Nothing changed, so the given time is returned.
+	future := firstdue.Timestamp(time.Time(next).Add(time.Hour))
+	changed, next, err = client.SyncOccupancies(ctx, future)
+	if err != nil {
+		t.Fatalf("SyncOccupancies: %v", err)
+	}
+	if len(changed) != 0 || !time.Time(next).Equal(time.Time(future)) {
+		t.Fatalf("got %d occupancies and next %v", len(changed), time.Time(next))
+	}
+}
+
+func TestOccupancies(t *testing.T) {
+	ctx := context.Background()
+	client := newMockClient(t, nil)
+
+	output, err := client.GetOccupancies(ctx, firstdue.GetOccupanciesRequest{OccupancyType: "educational"})
+	if err != nil {
+		t.Fatalf("GetOccupancies: %v", err)
+	}
+	if output.Total != 1 || output.List[0].Name != "Oak Avenue Elementary School" || output.List[0].OccupantLoad == nil || *output.List[0].OccupantLoad != 650 {
+		t.Fatalf("unexpected occupancies: %+v", output)
+	}
+
+	if _, err := client.PostOccupancies(ctx, firstdue.PostOccupanciesRequest{OccupancyType: "business"}); err == nil {
+		t.Errorf("expected an error for an occupancy without a name")
+	}
+	created, err := client.PostOccupancies(ctx, firstdue.PostOccupanciesRequest{Name: "Corner Bakery", OccupancyType: "business", StatusCode: "active"})
+	if err != nil {
+		t.Fatalf("PostOccupancies: %v", err)
+	}
+	all, err := client.GetAllOccupancies(ctx, firstdue.GetOccupanciesRequest{PerPage: 1})
+	if err != nil || len(all) != 3 {
+		t.Fatalf("GetAllOccupancies: got %d occupancies: %v", len(all), err)
+	}
+	if err := client.DeleteOccupanciesID(ctx, uint64(created.ID)); err != nil {
+		t.Fatalf("DeleteOccupanciesID: %v", err)
+	}
+	if _, err := client.GetOccupanciesID(ctx, uint64(created.ID)); err == nil {
+		t.Errorf("expected an error for a deleted occupancy")
+	}
+}
@@ -1,6 +1,7 @@
 package firstdue
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -9,7 +10,9 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/tekkamanendless/httperror"
 )
@@ -19,29 +22,105 @@ import (
 //
 // Input and output are expected to be JSON-serializable structures.  If omitted, they will not be sent or parsed.
 func (c *Client) Raw(ctx context.Context, method string, path string, input any, output any) error {
-	fullURL := strings.TrimRight(c.config.BaseURL, "/") + "/" + strings.TrimLeft(path, "/")
+	var data []byte
+	var err error
+	if ttl, ok := c.cacheTTL(method, path); ok {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		key := c.cacheKey(path)
+		data, err = c.cache.get(ctx, key, ttl, func(ctx context.Context) ([]byte, error) {
+			return c.rawBytes(ctx, method, path, input)
+		})
+	} else {
+		data, err = c.rawBytes(ctx, method, path, input)
+	}
+	if err != nil {
+		return err
+	}
 
-	var inputReader io.Reader
-	if input != nil {
-		inputContents, err := json.Marshal(input)
-		if err != nil {
-			return fmt.Errorf("failed to marshal input: %w", err)
+	// An empty body (such as from a "204 No Content" response) leaves the output as it is.
+	if output != nil && len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, output); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
 		}
-		inputReader = strings.NewReader(string(inputContents))
 	}
-	request, err := http.NewRequest(strings.ToUpper(method), fullURL, inputReader)
+
+	return nil
+}
+
+// cacheTTL returns the cache lifetime for the request, and whether the request should be cached at all.
+func (c *Client) cacheTTL(method string, path string) (time.Duration, bool) {
+	if c.cache == nil || !strings.EqualFold(method, http.MethodGet) {
+		return 0, false
+	}
+	return c.cache.ttl(path)
+}
+
+// RawRequest is a request for Client.Do.
+type RawRequest struct {
+	Method string      // The HTTP method; if empty, GET is used.
+	Path   string      // The path relative to the base URL; it may include a query string.
+	Query  url.Values  // Query parameters to add to the path.
+	Header http.Header // Extra headers to send.
+	Body   []byte      // The request body, if any.  Unless a Content-Type header is given, it is sent as JSON.
+}
+
+// RawResponse is the response from Client.Do.
+type RawResponse struct {
+	Proto      string      // For example, "HTTP/1.1".
+	Status     string      // For example, "200 OK".
+	StatusCode int         // For example, 200.
+	Header     http.Header // The response headers.
+	Body       []byte      // The response body; this is empty for "204 No Content" responses.
+}
+
+// Do performs an HTTP request to the FirstDue API and returns the response as-is.
+//
+// Unlike Raw, the body is neither encoded nor decoded, and a non-2xx status is not an error; only a failure to send
+// the request or to read the response is.  The response cache is not used.
+func (c *Client) Do(ctx context.Context, input RawRequest) (*RawResponse, error) {
+	fullURL, err := url.Parse(strings.TrimRight(c.config.BaseURL, "/") + "/" + strings.TrimLeft(input.Path, "/"))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("do: invalid path: %w", err)
+	}
+	if len(input.Query) > 0 {
+		query := fullURL.Query()
+		for key, values := range input.Query {
+			for _, value := range values {
+				query.Add(key, value)
+			}
+		}
+		fullURL.RawQuery = query.Encode()
+	}
+
+	method := strings.ToUpper(input.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+	var bodyReader io.Reader
+	if input.Body != nil {
+		bodyReader = bytes.NewReader(input.Body)
+	}
+	if ctx == nil {
+		ctx = context.Background()
 	}
-	if ctx != nil {
-		request = request.WithContext(ctx)
+	request, err := http.NewRequestWithContext(ctx, method, fullURL.String(), bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 	if c.config.Token != "" {
 		request.Header.Set("Authorization", "Bearer "+c.config.Token)
 	}
-	if input != nil {
+	if input.Body != nil {
 		request.Header.Set("Content-Type", "application/json")
 	}
+	for key, values := range input.Header {
+		request.Header.Del(key)
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
 
 	if c.config.Debug {
 		contents, err := httputil.DumpRequest(request, true)
@@ -58,7 +137,7 @@ func (c *Client) Raw(ctx context.Context, method string, path string, input any,
 	}
 	response, err := httpClient.Do(request)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer response.Body.Close()
 
@@ -71,14 +150,56 @@ func (c *Client) Raw(ctx context.Context, method string, path string, input any,
 		}
 	}
 
-	if response.StatusCode < 200 {
-		return httperror.ErrorFromStatus(response.StatusCode)
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	output := &RawResponse{
+		Proto:      response.Proto,
+		Status:     response.Status,
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		Body:       data,
+	}
+	return output, nil
+}
+
+// rawBytes performs a raw HTTP request to the FirstDue API and returns the response body.
+func (c *Client) rawBytes(ctx context.Context, method string, path string, input any) ([]byte, error) {
+	request := RawRequest{
+		Method: method,
+		Path:   path,
+	}
+	if input != nil {
+		inputContents, err := json.Marshal(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal input: %w", err)
+		}
+		request.Body = inputContents
+	}
+
+	response, err := c.Do(ctx, request)
+	if err != nil {
+		return nil, err
 	}
+	if err := response.Err(); err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
 
-	if response.StatusCode >= 300 {
+// Err returns an error describing the response if its status is not 2xx, and nil otherwise.
+//
+// The error wraps the matching httperror error and includes the messages from the API's error response.
+func (r *RawResponse) Err() error {
+	if r.StatusCode < 200 {
+		return httperror.ErrorFromStatus(r.StatusCode)
+	}
+
+	if r.StatusCode >= 300 {
 		var errorResponse ErrorResponse
-		if err := json.NewDecoder(response.Body).Decode(&errorResponse); err != nil {
-			return fmt.Errorf("%w: (failed to decode error response)", httperror.ErrorFromStatus(response.StatusCode))
+		if err := json.Unmarshal(r.Body, &errorResponse); err != nil {
+			return fmt.Errorf("%w: (failed to decode error response)", httperror.ErrorFromStatus(r.StatusCode))
 		}
 
 		var errs []error
@@ -86,15 +207,9 @@ func (c *Client) Raw(ctx context.Context, method string, path string, input any,
 			errs = append(errs, fmt.Errorf("%s: %s: %s", err.Field, err.Code, err.Message))
 		}
 		if len(errs) == 0 {
-			return fmt.Errorf("%w: %s", httperror.ErrorFromStatus(response.StatusCode), errorResponse.Message)
+			return fmt.Errorf("%w: %s", httperror.ErrorFromStatus(r.StatusCode), errorResponse.Message)
 		} else {
-			return fmt.Errorf("%w: %s: %w", httperror.ErrorFromStatus(response.StatusCode), errorResponse.Message, errors.Join(errs...))
-		}
-	}
-
-	if output != nil {
-		if err := json.NewDecoder(response.Body).Decode(output); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
+			return fmt.Errorf("%w: %s: %w", httperror.ErrorFromStatus(r.StatusCode), errorResponse.Message, errors.Join(errs...))
 		}
 	}
 
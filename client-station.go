@@ -3,12 +3,16 @@ package firstdue
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 
 	"github.com/google/go-querystring/query"
 )
 
 type GetStationsRequest struct {
+	Page    int    `url:"page,omitempty"`
+	PerPage int    `url:"per_page,omitempty"`
+	Name    string `url:"name,omitempty"` // Only include stations whose names match this.
 }
 
 type GetStationsResponse struct {
@@ -32,7 +36,24 @@ func (c *Client) GetStations(ctx context.Context, input GetStationsRequest) (out
 	}
 	err = c.Raw(ctx, http.MethodGet, path, nil, &output)
 	if err != nil {
-		return output, fmt.Errorf("getdispatches: %w", err)
+		return output, fmt.Errorf("getstations: %w", err)
 	}
 	return output, nil
 }
+
+// IterateStations iterates over the stations on every page.
+//
+// The page in the input is ignored; iteration always starts at the first page.  If the number of stations does not
+// match the total reported by the API, the final error will wrap ErrTotalMismatch.
+func (c *Client) IterateStations(ctx context.Context, input GetStationsRequest) iter.Seq2[GetStationsResponseStation, error] {
+	return paginate(func(page int) ([]GetStationsResponseStation, int, error) {
+		input.Page = page
+		output, err := c.GetStations(ctx, input)
+		return output.List, output.Total, err
+	})
+}
+
+// GetAllStations returns the stations on every page.
+func (c *Client) GetAllStations(ctx context.Context, input GetStationsRequest) ([]GetStationsResponseStation, error) {
+	return collect(c.IterateStations(ctx, input))
+}
@@ -12,11 +12,14 @@ type ClientConfig struct {
 	Token      string       // The API "Bearer" token for authentication.
 	Debug      bool         // If true, debug information will be printed to the log.
 	HTTPClient *http.Client // The HTTP client to use.
+	Cache      *CacheConfig // If set, responses from the reference endpoints will be cached.
 }
 
 // Client is a client for the FirstDue API.
 type Client struct {
-	config ClientConfig
+	config   ClientConfig
+	cache    *responseCache
+	username string // The user name given to Authenticate, if any.
 }
 
 // ClientOption is a function that configures a Client.
@@ -37,6 +40,9 @@ func NewClient(opts ...ClientOption) *Client {
 	c := &Client{
 		config: config,
 	}
+	if config.Cache != nil {
+		c.cache = newResponseCache(*config.Cache)
+	}
 	return c
 }
 
@@ -0,0 +1,28 @@
+package firstdue_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tekkamanendless/firstdue"
+	"github.com/tekkamanendless/firstdue/mock"
+)
+
+// newMockClient returns a client for a mock server with the default fixtures.
+//
+// If requests is not nil, the URL of every API request is appended to it.
+func newMockClient(t *testing.T, requests *[]string) *firstdue.Client {
+	t.Helper()
+	var handler http.Handler = mock.NewServer(mock.Config{Fixtures: mock.DefaultFixtures(), Seed: 1})
+	if requests != nil {
+		next := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*requests = append(*requests, r.URL.String())
+			next.ServeHTTP(w, r)
+		})
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return firstdue.NewClient(firstdue.WithBaseURL(server.URL), firstdue.WithToken("test"))
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+type APICommand struct {
+	Endpoint string   `arg:"--endpoint,required" placeholder:"ENDPOINT" help:"API endpoint to call"`
+	Method   string   `arg:"--method" default:"GET" choices:"GET,POST,PUT,PATCH,DELETE" help:"HTTP method to use"`
+	Body     string   `arg:"--body" help:"Request body for POST/PUT methods; use @FILE to read it from a file, or - to read it from standard input"`
+	Query    []string `arg:"--query,separate" help:"Query parameter to add, as KEY=VALUE (repeatable)"`
+	Header   []string `arg:"--header,separate" help:"Request header to add, as NAME:VALUE (repeatable)"`
+	Include  bool     `arg:"--include" help:"Print the response status line and headers before the body"`
+	Raw      bool     `arg:"--raw" help:"Print the response body as-is instead of formatting it"`
+}
+
+func runAPI(ctx context.Context, args Args, config *Config) error {
+	slog.InfoContext(ctx, "For a list of API endpoints, see https://support.firstduesizeup.com/portal/en/kb/articles/first-due-rest-api-documentation")
+
+	request := firstdue.RawRequest{
+		Method: args.API.Method,
+		Path:   args.API.Endpoint,
+		Query:  url.Values{},
+		Header: http.Header{},
+	}
+	for _, query := range args.API.Query {
+		key, value, ok := strings.Cut(query, "=")
+		if !ok {
+			return fmt.Errorf("invalid query parameter %q: expected KEY=VALUE", query)
+		}
+		request.Query.Add(key, value)
+	}
+	for _, header := range args.API.Header {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid header %q: expected NAME:VALUE", header)
+		}
+		request.Header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	body, err := readAPIBody(args.API.Body)
+	if err != nil {
+		return err
+	}
+	request.Body = body
+
+	client, err := newClient(ctx, args, config)
+	if err != nil {
+		return err
+	}
+	response, err := client.Do(ctx, request)
+	if err != nil {
+		return fmt.Errorf("error making API request: %w", err)
+	}
+
+	if args.API.Include {
+		fmt.Fprintf(os.Stdout, "%s %s\n", response.Proto, response.Status)
+		names := make([]string, 0, len(response.Header))
+		for name := range response.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, value := range response.Header[name] {
+				fmt.Fprintf(os.Stdout, "%s: %s\n", textproto.CanonicalMIMEHeaderKey(name), value)
+			}
+		}
+		fmt.Fprintln(os.Stdout)
+	}
+
+	switch {
+	case len(bytes.TrimSpace(response.Body)) == 0:
+		// There is nothing to print.
+	case args.API.Raw || !isJSONResponse(response):
+		_, err = os.Stdout.Write(response.Body)
+		if err != nil {
+			return err
+		}
+	default:
+		err = printOutput(args, json.RawMessage(response.Body))
+		if err != nil {
+			return err
+		}
+	}
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("error making API request: %s", response.Status)
+	}
+	return nil
+}
+
+// readAPIBody returns the request body for the "api" command.
+//
+// A value of "-" reads standard input and a value starting with "@" reads the named file; anything else is the body
+// itself.  An empty value means that there is no body.
+func readAPIBody(value string) ([]byte, error) {
+	switch {
+	case value == "":
+		return nil, nil
+	case value == "-":
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("error reading request body: %w", err)
+		}
+		return contents, nil
+	case strings.HasPrefix(value, "@"):
+		contents, err := os.ReadFile(strings.TrimPrefix(value, "@"))
+		if err != nil {
+			return nil, fmt.Errorf("error reading request body: %w", err)
+		}
+		return contents, nil
+	}
+	return []byte(value), nil
+}
+
+// isJSONResponse returns true if the response body is JSON, going by its content type if it has one.
+func isJSONResponse(response *firstdue.RawResponse) bool {
+	if contentType := response.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err == nil && mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+			return false
+		}
+	}
+	return json.Valid(response.Body)
+}
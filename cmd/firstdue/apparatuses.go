@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+type ApparatusesCommand struct {
+	List *ApparatusesListCommand `arg:"subcommand" help:"List apparatuses"`
+}
+
+type ApparatusesListCommand struct {
+	Page    int    `arg:"--page" help:"Page number"`
+	PerPage int    `arg:"--per-page" help:"Number of apparatuses per page"`
+	Name    string `arg:"--name" help:"Only list apparatuses with this name"`
+	UseCode string `arg:"--use-code" help:"Only list apparatuses with this use code"`
+	All     bool   `arg:"--all" help:"List the apparatuses on every page"`
+}
+
+func runApparatuses(ctx context.Context, args Args, config *Config) error {
+	switch {
+	case args.Apparatuses.List != nil:
+		client, err := newClient(ctx, args, config)
+		if err != nil {
+			return err
+		}
+		input := firstdue.GetApparatusesRequest{
+			Page:    args.Apparatuses.List.Page,
+			PerPage: args.Apparatuses.List.PerPage,
+			Name:    args.Apparatuses.List.Name,
+			UseCode: args.Apparatuses.List.UseCode,
+		}
+		if args.Apparatuses.List.All {
+			output, err := client.GetAllApparatuses(ctx, input)
+			if err != nil {
+				return fmt.Errorf("error listing apparatuses: %w", err)
+			}
+			return printOutput(args, output)
+		}
+		output, err := client.GetApparatuses(ctx, input)
+		if err != nil {
+			return fmt.Errorf("error listing apparatuses: %w", err)
+		}
+		return printOutput(args, output)
+	default:
+		return errUsage
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type AuthCommand struct {
+	Status *AuthStatusCommand `arg:"subcommand" help:"Show the cached access token"`
+	Logout *AuthLogoutCommand `arg:"subcommand" help:"Remove the cached access token"`
+}
+
+type AuthStatusCommand struct{}
+
+type AuthLogoutCommand struct{}
+
+// AuthStatus describes the cached access token.
+type AuthStatus struct {
+	Profile   string    `json:"profile"`
+	Cached    bool      `json:"cached"`
+	Valid     bool      `json:"valid"`
+	Scope     string    `json:"scope,omitempty"`
+	TokenType string    `json:"tokenType,omitempty"`
+	IssuedAt  time.Time `json:"issuedAt,omitzero"`
+	Age       string    `json:"age,omitempty"`
+	ExpiresAt time.Time `json:"expiresAt,omitzero"`
+	ExpiresIn string    `json:"expiresIn,omitempty"`
+}
+
+func runAuth(ctx context.Context, args Args, config *Config) error {
+	switch {
+	case args.Auth.Status != nil:
+		name, account, err := activeAccount(args, config)
+		if err != nil {
+			return err
+		}
+		token, err := loadToken(name)
+		if err != nil {
+			return err
+		}
+		status := AuthStatus{
+			Profile: name,
+			Cached:  token.AccessToken != "",
+		}
+		if status.Cached {
+			status.Valid = token.valid(account)
+			status.Scope = token.Scope
+			status.TokenType = token.TokenType
+			status.IssuedAt = token.IssuedAt
+			status.Age = time.Since(token.IssuedAt).Round(time.Second).String()
+			status.ExpiresAt = token.ExpiresAt
+			status.ExpiresIn = time.Until(token.ExpiresAt).Round(time.Second).String()
+		}
+		return printOutput(args, status)
+	case args.Auth.Logout != nil:
+		name, _, err := activeAccount(args, config)
+		if err != nil {
+			return err
+		}
+		err = deleteToken(name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Removed the cached token for '%s'.\n", name)
+		return nil
+	default:
+		return errUsage
+	}
+}
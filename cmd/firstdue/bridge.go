@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tekkamanendless/firstdue/bridge"
+	"github.com/tekkamanendless/firstdue/mapping"
+)
+
+type BridgeCommand struct {
+	Serve *BridgeServeCommand `arg:"subcommand" help:"Receive signed incident payloads from a CAD system and upsert them as NFIRS notifications"`
+}
+
+type BridgeServeCommand struct {
+	Host            string        `arg:"--host" default:"127.0.0.1" help:"Address to listen on"`
+	Port            int           `arg:"--port" default:"8090" help:"Port to listen on"`
+	Mapping         string        `arg:"--mapping,required" placeholder:"FILE" help:"YAML or JSON file that maps the payload fields to the notification fields"`
+	Secret          string        `arg:"--secret,env:FIRSTDUE_BRIDGE_SECRET" help:"HMAC-SHA256 secret shared with the sender (prefer the environment variable or --secret-file)"`
+	SecretFile      string        `arg:"--secret-file" placeholder:"FILE" help:"File containing the HMAC-SHA256 secret"`
+	SignatureHeader string        `arg:"--signature-header" default:"X-Signature-256" help:"Header that carries the payload signature"`
+	TimestampHeader string        `arg:"--timestamp-header" default:"X-Signature-Timestamp" help:"Header that carries the Unix time at which the payload was signed"`
+	Tolerance       time.Duration `arg:"--tolerance" default:"5m" help:"How far the signing time may be from this machine's clock"`
+	MaxBodyBytes    int64         `arg:"--max-body-bytes" default:"1048576" help:"Largest payload accepted, in bytes"`
+}
+
+func runBridge(ctx context.Context, args Args, config *Config) error {
+	switch {
+	case args.Bridge.Serve != nil:
+		command := args.Bridge.Serve
+
+		secret := command.Secret
+		if command.SecretFile != "" {
+			contents, err := os.ReadFile(command.SecretFile)
+			if err != nil {
+				return fmt.Errorf("error reading secret: %w", err)
+			}
+			secret = strings.TrimSpace(string(contents))
+		}
+		if secret == "" {
+			return fmt.Errorf("a secret is required: use --secret-file or FIRSTDUE_BRIDGE_SECRET")
+		}
+
+		m, err := mapping.Load(command.Mapping)
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient(ctx, args, config)
+		if err != nil {
+			return err
+		}
+
+		server, err := bridge.NewServer(bridge.Config{
+			Client:          client,
+			Mapping:         m,
+			Secret:          []byte(secret),
+			SignatureHeader: command.SignatureHeader,
+			TimestampHeader: command.TimestampHeader,
+			Tolerance:       command.Tolerance,
+			MaxBodyBytes:    command.MaxBodyBytes,
+			Logger:          slog.Default(),
+		})
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		listener, err := net.Listen("tcp", net.JoinHostPort(command.Host, strconv.Itoa(command.Port)))
+		if err != nil {
+			return fmt.Errorf("error listening: %w", err)
+		}
+		httpServer := &http.Server{
+			Handler:           server,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			httpServer.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Fprintf(os.Stderr, "The bridge is listening on http://%s; press Ctrl-C to stop.\n", listener.Addr())
+		fmt.Fprintf(os.Stderr, "POST signed payloads to /incidents; /healthz, /readyz, and /metrics are also available.\n")
+
+		err = httpServer.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("error serving: %w", err)
+		}
+		return nil
+	default:
+		return errUsage
+	}
+}
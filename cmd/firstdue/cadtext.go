@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tekkamanendless/firstdue/cadtext"
+)
+
+type CADTextCommand struct {
+	Parse *CADTextParseCommand `arg:"subcommand" help:"Extract a dispatch from a CAD text page or email"`
+}
+
+type CADTextParseCommand struct {
+	Templates string `arg:"--templates" placeholder:"FILE" help:"YAML or JSON file with the page templates (defaults to a template for common labels)"`
+	Email     bool   `arg:"--email" help:"Read the input as an RFC 822 email message (detected automatically when it starts with headers)"`
+	As        string `arg:"--as" default:"dispatch" choices:"dispatch,notification,request" help:"What to print: the extracted dispatch, a notification draft, or a dispatch create request"`
+	File      string `arg:"positional,required" placeholder:"FILE" help:"Page or message file (- for stdin)"`
+}
+
+func runCADText(ctx context.Context, args Args, config *Config) error {
+	switch {
+	case args.CADText.Parse != nil:
+		command := args.CADText.Parse
+
+		templates := cadtext.DefaultConfig()
+		if command.Templates != "" {
+			var err error
+			templates, err = cadtext.LoadConfig(command.Templates)
+			if err != nil {
+				return err
+			}
+		}
+		parser, err := cadtext.NewParser(templates)
+		if err != nil {
+			return err
+		}
+
+		var contents []byte
+		if command.File == "-" {
+			contents, err = io.ReadAll(os.Stdin)
+		} else {
+			contents, err = os.ReadFile(command.File)
+		}
+		if err != nil {
+			return fmt.Errorf("error reading input file: %w", err)
+		}
+
+		var dispatch *cadtext.Dispatch
+		if command.Email || looksLikeEmail(contents) {
+			dispatch, err = parser.ParseMessage(bytes.NewReader(contents))
+		} else {
+			dispatch, err = parser.Parse(string(contents))
+		}
+		if dispatch == nil {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		if command.As != "dispatch" {
+			for _, warning := range dispatch.Warnings() {
+				fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+			}
+		}
+
+		switch command.As {
+		case "dispatch":
+			return printOutput(args, dispatch)
+		case "notification":
+			return printOutput(args, dispatch.Notification())
+		case "request":
+			return printOutput(args, dispatch.DispatchRequest())
+		default:
+			return fmt.Errorf("invalid value for --as: %q", command.As)
+		}
+	default:
+		return errUsage
+	}
+}
+
+// looksLikeEmail returns true if the contents start with the headers of an email message.
+func looksLikeEmail(contents []byte) bool {
+	for _, prefix := range []string{"From:", "Received:", "Return-Path:", "MIME-Version:", "Date:", "Subject:", "To:", "Message-ID:", "Delivered-To:"} {
+		if bytes.HasPrefix(contents, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
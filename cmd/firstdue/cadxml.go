@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+	"github.com/tekkamanendless/firstdue/cadxml"
+	"github.com/tekkamanendless/firstdue/mapping"
+)
+
+type CADXMLCommand struct {
+	Convert *CADXMLConvertCommand `arg:"subcommand" help:"Convert an APCO/NENA-style CAD XML export into NFIRS notifications"`
+}
+
+type CADXMLConvertCommand struct {
+	TimeZone string `arg:"--time-zone" help:"IANA time zone of the times that have no offset (defaults to UTC)"`
+	Upsert   bool   `arg:"--upsert" help:"Create or update the notifications instead of printing them"`
+	File     string `arg:"positional,required" placeholder:"FILE" help:"XML file (- for stdin)"`
+}
+
+func runCADXML(ctx context.Context, args Args, config *Config) error {
+	switch {
+	case args.CADXML.Convert != nil:
+		command := args.CADXML.Convert
+
+		options := cadxml.Options{}
+		if command.TimeZone != "" {
+			location, err := time.LoadLocation(command.TimeZone)
+			if err != nil {
+				return fmt.Errorf("invalid time zone: %w", err)
+			}
+			options.Location = location
+		}
+
+		var input io.Reader = os.Stdin
+		if command.File != "-" {
+			file, err := os.Open(command.File)
+			if err != nil {
+				return fmt.Errorf("error reading input file: %w", err)
+			}
+			defer file.Close()
+			input = file
+		}
+		incidents, err := cadxml.Parse(input)
+		if err != nil {
+			return err
+		}
+
+		var records []firstdue.NfirsNotificationRecord
+		var problems int
+		for i, incident := range incidents {
+			record, err := incident.Record(options)
+			for _, problem := range mapping.Problems(err) {
+				fmt.Fprintf(os.Stderr, "incident %d (%s): %s\n", i+1, record.Notification.DispatchNumber, problem)
+				problems++
+			}
+			if err == nil {
+				records = append(records, record)
+			}
+		}
+
+		if !command.Upsert {
+			var output any = records
+			if len(incidents) == 1 && len(records) == 1 {
+				output = records[0]
+			}
+			err = printOutput(args, output)
+			if err != nil {
+				return err
+			}
+		} else {
+			client, err := newClient(ctx, args, config)
+			if err != nil {
+				return err
+			}
+			for _, record := range records {
+				result, err := client.UpsertNfirsNotification(ctx, record)
+				if err != nil {
+					return fmt.Errorf("error upserting dispatch %s: %w", record.Notification.DispatchNumber, err)
+				}
+				fmt.Printf("%s %s (%d apparatuses)\n", strings.ToUpper(result[:1])+result[1:], record.Notification.DispatchNumber, len(record.Apparatuses))
+			}
+		}
+		if problems > 0 {
+			return fmt.Errorf("%d of %d incidents were skipped because of %d problems", len(incidents)-len(records), len(incidents), problems)
+		}
+		return nil
+	default:
+		return errUsage
+	}
+}
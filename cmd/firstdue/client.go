@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// errUsage is returned by a command when it was not given enough information to run; the help will be shown.
+var errUsage = errors.New("usage")
+
+// newClient returns an authenticated client for the active account.
+//
+// The access token is cached between runs and is only requested again when it is about to expire or is rejected.
+func newClient(ctx context.Context, args Args, config *Config) (*firstdue.Client, error) {
+	name, account, err := activeAccount(args, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var options []firstdue.ClientOption
+	if args.Debug {
+		options = append(options, firstdue.WithDebug(true))
+	}
+	if account.BaseURL != "" {
+		options = append(options, firstdue.WithBaseURL(account.BaseURL))
+	}
+
+	source := &tokenSource{
+		name:    name,
+		account: account,
+		options: options,
+	}
+	token, err := source.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{
+		Transport: &tokenTransport{
+			source: source,
+			base:   http.DefaultTransport,
+		},
+	}
+	options = append(options, firstdue.WithHTTPClient(httpClient), firstdue.WithToken(token))
+	return firstdue.NewClient(options...), nil
+}
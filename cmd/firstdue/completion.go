@@ -0,0 +1,422 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+type CompletionCommand struct {
+	Shell string `arg:"positional,required" choices:"bash,zsh,fish" help:"Shell to generate the completion script for: bash, zsh, or fish"`
+}
+
+// completeCommandName is the hidden first argument that the completion scripts use to ask for completions.
+//
+// It is handled before the arguments are parsed, since the words being completed are usually not valid arguments
+// yet.  The arguments are: the shell, then the words of the command line after the program name, the last of which
+// is the word being completed.
+const completeCommandName = "__complete"
+
+// completeFileDirective is printed instead of any completions when the shell should complete a file name.
+const completeFileDirective = ":file"
+
+// The placeholder of an argument, as shown in the help, describes how to complete its value:
+//
+//	PROFILE     the names of the configurations
+//	ENDPOINT    the paths in the endpoint catalog
+//	FILE        a file name
+//
+// An argument with a fixed set of values lists them, separated by commas, in a "choices" tag instead.  Any other
+// argument has no completions.
+const (
+	completeProfilePlaceholder  = "PROFILE"
+	completeEndpointPlaceholder = "ENDPOINT"
+	completeFilePlaceholder     = "FILE"
+)
+
+func runCompletion(ctx context.Context, args Args, config *Config) error {
+	var script string
+	switch args.Completion.Shell {
+	case "bash":
+		script = bashCompletionScript
+	case "zsh":
+		script = zshCompletionScript
+	case "fish":
+		script = fishCompletionScript
+	default:
+		return fmt.Errorf("unsupported shell: %q", args.Completion.Shell)
+	}
+	_, err := fmt.Print(script)
+	return err
+}
+
+const bashCompletionScript = `# bash completion for firstdue
+#
+# Load it with:  source <(firstdue completion bash)
+_firstdue() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	local IFS=$'\n'
+	local output
+	output=$(firstdue __complete bash "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null)
+	if [[ "$output" == ":file" ]]; then
+		COMPREPLY=($(compgen -f -- "$cur"))
+		return
+	fi
+	COMPREPLY=($output)
+}
+complete -o default -F _firstdue firstdue
+`
+
+const zshCompletionScript = `#compdef firstdue
+#
+# Load it with:  source <(firstdue completion zsh)
+_firstdue() {
+	local -a completions
+	local output
+	output=$(firstdue __complete zsh "${(@)words[2,CURRENT]}" 2>/dev/null)
+	if [[ "$output" == ":file" ]]; then
+		_files
+		return
+	fi
+	completions=("${(@f)output}")
+	_describe 'firstdue' completions
+}
+compdef _firstdue firstdue
+`
+
+const fishCompletionScript = `# fish completion for firstdue
+#
+# Load it with:  firstdue completion fish | source
+function __firstdue_complete
+	set -l tokens (commandline -opc) (commandline -ct)
+	set -l output (firstdue __complete fish $tokens[2..-1] 2>/dev/null)
+	if test "$output" = ":file"
+		__fish_complete_path (commandline -ct)
+		return
+	end
+	printf '%s\n' $output
+end
+complete -c firstdue -f -a '(__firstdue_complete)'
+`
+
+// completionCommandSpec describes a command (or subcommand) for completion.
+type completionCommandSpec struct {
+	name        string
+	help        string
+	options     []*completionOptionSpec
+	positionals []*completionOptionSpec
+	subcommands []*completionCommandSpec
+	parent      *completionCommandSpec
+}
+
+// completionOptionSpec describes an option or a positional argument for completion.
+type completionOptionSpec struct {
+	long        string
+	short       string
+	help        string
+	placeholder string   // Empty if the option does not take a value.
+	choices     []string // The values that the option accepts, if they are fixed.
+	repeatable  bool
+}
+
+// completionSpec builds the completion description of a command and its subcommands from the struct that go-arg
+// parses them into, reading the same tags that go-arg does so that completion offers exactly what the parser
+// accepts.
+//
+// Only the program itself has the built-in --help option; the subcommands find it, like the other global options,
+// through their parents.
+func completionSpec(t reflect.Type, name string, help string) *completionCommandSpec {
+	command := &completionCommandSpec{name: name, help: help}
+	if name == "firstdue" {
+		command.options = append(command.options, &completionOptionSpec{long: "help", short: "h", help: "display this help and exit"})
+	}
+	addCompletionFields(command, t)
+	return command
+}
+
+// addCompletionFields adds the options, positional arguments, and subcommands of a struct to the command.
+//
+// As in go-arg, the fields of embedded structs belong to the command, the long name of an option defaults to the
+// lower-case field name, and its placeholder defaults to the upper-case long name.  Boolean options take no value,
+// slices are repeatable, and hidden arguments are left out.
+func addCompletionFields(command *completionCommandSpec, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("arg")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			addCompletionFields(command, field.Type)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		option := &completionOptionSpec{long: strings.ToLower(field.Name), help: field.Tag.Get("help")}
+		var positional, hidden bool
+		var subcommand string
+		for _, key := range strings.Split(tag, ",") {
+			key, value, _ := strings.Cut(strings.TrimLeft(key, " "), ":")
+			switch {
+			case strings.HasPrefix(key, "--"):
+				option.long = key[2:]
+			case strings.HasPrefix(key, "-"):
+				option.short = key[1:]
+			case key == "positional":
+				positional = true
+			case key == "hidden":
+				hidden = true
+			case key == "subcommand":
+				subcommand = strings.ToLower(field.Name)
+				if value != "" {
+					subcommand, _, _ = strings.Cut(value, "|")
+					subcommand = strings.TrimSpace(subcommand)
+				}
+			}
+		}
+		if hidden {
+			continue
+		}
+		if subcommand != "" {
+			child := completionSpec(field.Type.Elem(), subcommand, option.help)
+			child.parent = command
+			command.subcommands = append(command.subcommands, child)
+			continue
+		}
+
+		valueType := field.Type
+		if valueType.Kind() == reflect.Pointer {
+			valueType = valueType.Elem()
+		}
+		option.repeatable = valueType.Kind() == reflect.Slice
+		if positional || valueType.Kind() != reflect.Bool {
+			option.placeholder = strings.ToUpper(option.long)
+			if placeholder, ok := field.Tag.Lookup("placeholder"); ok {
+				option.placeholder = placeholder
+			}
+		}
+		if choices := field.Tag.Get("choices"); choices != "" {
+			option.choices = strings.Split(choices, ",")
+		}
+
+		if positional {
+			command.positionals = append(command.positionals, option)
+		} else {
+			command.options = append(command.options, option)
+		}
+	}
+}
+
+// completion is a single candidate.
+type completion struct {
+	value       string
+	description string
+}
+
+// runComplete prints the completions for a partial command line, in the format that the shell's script expects.
+func runComplete(w io.Writer, shell string, words []string) error {
+	root := completionSpec(reflect.TypeOf(Args{}), "firstdue", "")
+	completions, file := completeWords(root, words)
+	if file {
+		_, err := fmt.Fprintln(w, completeFileDirective)
+		return err
+	}
+	for _, c := range completions {
+		var line string
+		description := strings.ReplaceAll(c.description, "\n", " ")
+		switch shell {
+		case "zsh":
+			line = strings.ReplaceAll(c.value, ":", `\:`)
+			if description != "" {
+				line += ":" + description
+			}
+		case "fish":
+			line = c.value
+			if description != "" {
+				line += "\t" + description
+			}
+		default:
+			line = c.value
+		}
+		_, err := fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// completeWords returns the completions for the last word, given the words before it.
+//
+// If the last word is a file name, it returns true instead of any completions.
+func completeWords(root *completionCommandSpec, words []string) ([]completion, bool) {
+	current := ""
+	if len(words) > 0 {
+		current = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	command := root
+	used := map[*completionOptionSpec]bool{}
+	var pending *completionOptionSpec
+	positionals := 0
+	afterDashes := false
+	for _, word := range words {
+		switch {
+		case pending != nil:
+			pending = nil
+		case afterDashes:
+			positionals++
+		case word == "--":
+			afterDashes = true
+		case strings.HasPrefix(word, "-") && len(word) > 1:
+			name, _, hasValue := strings.Cut(word, "=")
+			option := findCompletionOption(command, name)
+			if option != nil {
+				used[option] = true
+				if option.placeholder != "" && !hasValue {
+					pending = option
+				}
+			}
+		default:
+			if child := findCompletionSubcommand(command, word); child != nil {
+				command = child
+				positionals = 0
+				continue
+			}
+			positionals++
+		}
+	}
+
+	if pending != nil {
+		return completeValue(pending, "", current)
+	}
+	if strings.HasPrefix(current, "-") && !afterDashes {
+		if name, value, ok := strings.Cut(current, "="); ok {
+			option := findCompletionOption(command, name)
+			if option == nil || option.placeholder == "" {
+				return nil, false
+			}
+			return completeValue(option, name+"=", value)
+		}
+		var completions []completion
+		for c := command; c != nil; c = c.parent {
+			for _, option := range c.options {
+				if used[option] && !option.repeatable {
+					continue
+				}
+				completions = append(completions, completion{value: "--" + option.long, description: option.help})
+			}
+		}
+		return filterCompletions(completions, current), false
+	}
+
+	var completions []completion
+	for _, child := range command.subcommands {
+		completions = append(completions, completion{value: child.name, description: child.help})
+	}
+	if positionals < len(command.positionals) {
+		values, file := completeValue(command.positionals[positionals], "", current)
+		if file && len(completions) == 0 {
+			return nil, true
+		}
+		completions = append(completions, values...)
+	}
+	return filterCompletions(completions, current), false
+}
+
+func findCompletionOption(command *completionCommandSpec, name string) *completionOptionSpec {
+	for c := command; c != nil; c = c.parent {
+		for _, option := range c.options {
+			if name == "--"+option.long || (option.short != "" && name == "-"+option.short) {
+				return option
+			}
+		}
+	}
+	return nil
+}
+
+func findCompletionSubcommand(command *completionCommandSpec, name string) *completionCommandSpec {
+	for _, child := range command.subcommands {
+		if child.name == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// completeValue returns the completions for the value of an option or positional argument.
+func completeValue(option *completionOptionSpec, prefix string, current string) ([]completion, bool) {
+	var completions []completion
+	switch placeholder := option.placeholder; {
+	case len(option.choices) > 0:
+		for _, value := range option.choices {
+			completions = append(completions, completion{value: prefix + value})
+		}
+	case placeholder == completeFilePlaceholder:
+		if prefix == "" {
+			return nil, true
+		}
+	case placeholder == completeProfilePlaceholder:
+		for _, name := range completionProfileNames() {
+			completions = append(completions, completion{value: prefix + name})
+		}
+	case placeholder == completeEndpointPlaceholder:
+		seen := map[string]bool{}
+		for _, endpoint := range firstdue.Endpoints {
+			if seen[endpoint.Path] {
+				continue
+			}
+			seen[endpoint.Path] = true
+			description := endpoint.Description
+			if endpoint.Assumed {
+				description += " (assumed; not in the API documentation)"
+			}
+			completions = append(completions, completion{value: prefix + endpoint.Path, description: description})
+		}
+	}
+	return filterCompletions(completions, prefix+current), false
+}
+
+// completionProfileNames returns the names of the configurations.
+//
+// The config file is read directly rather than with loadConfig, since completion must never prompt or write.
+func completionProfileNames() []string {
+	path, err := configFilePath()
+	if err != nil {
+		return nil
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var config Config
+	if json.Unmarshal(contents, &config) != nil {
+		return nil
+	}
+	var names []string
+	for name := range config.AccountMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func filterCompletions(completions []completion, current string) []completion {
+	var filtered []completion
+	for _, c := range completions {
+		if strings.HasPrefix(c.value, current) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
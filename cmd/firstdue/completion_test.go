@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alexflint/go-arg"
+)
+
+func TestCompleteWords(t *testing.T) {
+	root := completionSpec(reflect.TypeOf(Args{}), "firstdue", "")
+
+	values := func(words ...string) []string {
+		completions, file := completeWords(root, words)
+		if file {
+			return []string{completeFileDirective}
+		}
+		var values []string
+		for _, c := range completions {
+			values = append(values, c.value)
+		}
+		return values
+	}
+	for _, test := range []struct {
+		words []string
+		want  []string
+	}{
+		{[]string{"con"}, []string{"config"}},
+		{[]string{"config", "m"}, []string{"migrate"}},
+		{[]string{"config", "edit", "prod", "--cr"}, []string{"--credential-store"}},
+		{[]string{"config", "edit", "prod", "--credential-store", ""}, []string{"auto", "file", "keyring"}},
+		{[]string{"--output=nd"}, []string{"--output=ndjson"}},
+		{[]string{"-o", "ya"}, []string{"yaml"}},
+		{[]string{"dispatches", "watch", "--state-file", ""}, []string{completeFileDirective}},
+		{[]string{"dispatches", "watch", "--no-state", "--no"}, nil},
+		{[]string{"dispatches", "watch", "--unit-code", "E1", "--unit"}, []string{"--unit-code"}},
+		{[]string{"dispatches", "watch", "--he"}, []string{"--help"}},
+		{[]string{"api", "--endpoint", "/v1/stati"}, []string{"/v1/stations"}},
+		{[]string{"completion", "z"}, []string{"zsh"}},
+	} {
+		if got := values(test.words...); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%q: got %q, want %q", test.words, got, test.want)
+		}
+	}
+}
+
+// TestCompletionSpecMatchesHelp checks that completion knows the same commands and options as the parser.
+func TestCompletionSpecMatchesHelp(t *testing.T) {
+	var args Args
+	parser, err := arg.NewParser(arg.Config{Program: "firstdue"}, &args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var check func(command *completionCommandSpec, path []string)
+	check = func(command *completionCommandSpec, path []string) {
+		var buffer bytes.Buffer
+		if err := parser.WriteHelpForSubcommand(&buffer, path...); err != nil {
+			t.Errorf("%q: %v", path, err)
+			return
+		}
+		help := buffer.String()
+		for _, option := range command.options {
+			if !strings.Contains(help, "--"+option.long) {
+				t.Errorf("%q: --%s is not in the help", path, option.long)
+			}
+		}
+		for _, positional := range command.positionals {
+			if !strings.Contains(help, positional.placeholder) {
+				t.Errorf("%q: %s is not in the help", path, positional.placeholder)
+			}
+		}
+		for _, child := range command.subcommands {
+			if !strings.Contains(help, "  "+child.name) {
+				t.Errorf("%q: %s is not in the help", path, child.name)
+			}
+			check(child, append(append([]string{}, path...), child.name))
+		}
+	}
+	check(completionSpec(reflect.TypeOf(Args{}), "firstdue", ""), nil)
+}
@@ -0,0 +1,499 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/kirsle/configdir"
+	"golang.org/x/term"
+)
+
+type ConfigCommand struct {
+	Activate  *ConfigActivateCommand  `arg:"subcommand" help:"Activate a configuration"`
+	Configure *ConfigConfigureCommand `arg:"subcommand" help:"Configure a new configuration"`
+	Edit      *ConfigEditCommand      `arg:"subcommand" help:"Change some of the settings of a configuration"`
+	List      *ConfigListCommand      `arg:"subcommand" help:"List all configurations"`
+	Show      *ConfigShowCommand      `arg:"subcommand" help:"Show a configuration"`
+	Rename    *ConfigRenameCommand    `arg:"subcommand" help:"Rename a configuration"`
+	Delete    *ConfigDeleteCommand    `arg:"subcommand" help:"Delete a configuration"`
+	Migrate   *ConfigMigrateCommand   `arg:"subcommand" help:"Move plaintext passwords out of the config file and into a credential store"`
+}
+
+type ConfigActivateCommand struct {
+	Name string `arg:"positional,required" placeholder:"PROFILE" help:"Name of the configuration"`
+}
+
+type ConfigConfigureCommand struct {
+	Name     string `arg:"positional,required" help:"Name of the configuration"`
+	BaseURL  string `arg:"--base-url" help:"Base URL for the configuration"`
+	Username string `arg:"--username" help:"Username for the configuration"`
+	PasswordOptions
+}
+
+type ConfigEditCommand struct {
+	Name     string  `arg:"positional,required" placeholder:"PROFILE" help:"Name of the configuration"`
+	BaseURL  *string `arg:"--base-url" help:"Base URL for the configuration"`
+	Username *string `arg:"--username" help:"Username for the configuration"`
+	PasswordOptions
+}
+
+// PasswordOptions are the ways that a password can be given to a configuration command.
+type PasswordOptions struct {
+	Password        string `arg:"--password" help:"Password for the configuration (visible in the shell history; prefer the prompt, --password-stdin, or --password-file)"`
+	PasswordStdin   bool   `arg:"--password-stdin" help:"Read the password from standard input"`
+	PasswordFile    string `arg:"--password-file" placeholder:"FILE" help:"Read the password from this file"`
+	CredentialStore string `arg:"--credential-store" default:"auto" placeholder:"STORE" choices:"auto,file,keyring" help:"Where to store the password: auto, file (encrypted), or keyring"`
+}
+
+type ConfigListCommand struct{}
+
+type ConfigShowCommand struct {
+	Name string `arg:"positional" placeholder:"PROFILE" help:"Name of the configuration (defaults to the active one)"`
+}
+
+type ConfigRenameCommand struct {
+	Name    string `arg:"positional,required" placeholder:"PROFILE" help:"Name of the configuration"`
+	NewName string `arg:"positional,required" help:"New name of the configuration"`
+}
+
+type ConfigDeleteCommand struct {
+	Name string `arg:"positional,required" placeholder:"PROFILE" help:"Name of the configuration"`
+}
+
+type ConfigMigrateCommand struct {
+	CredentialStore string `arg:"--credential-store" default:"auto" placeholder:"STORE" choices:"auto,file,keyring" help:"Where to store the passwords: auto, file (encrypted), or keyring"`
+}
+
+// ConfigSummary describes a configuration without its password.
+type ConfigSummary struct {
+	Name            string `json:"name"`
+	Active          bool   `json:"active"`
+	BaseURL         string `json:"baseURL"`
+	Username        string `json:"username"`
+	CredentialStore string `json:"credentialStore"`
+}
+
+type Config struct {
+	DefaultAccount string             `json:"defaultAccount"`
+	AccountMap     map[string]Account `json:"accountMap"`
+}
+
+type Account struct {
+	BaseURL         string `json:"baseURL"`
+	Username        string `json:"username"`
+	Password        string `json:"password,omitempty"`        // A plaintext password from an old config file; it is moved at startup or by "config migrate".
+	CredentialStore string `json:"credentialStore,omitempty"` // Where the password is stored.
+}
+
+// password returns the password for the account with the given name.
+func (a Account) password(name string) (string, error) {
+	if a.Password != "" {
+		return a.Password, nil
+	}
+	if a.CredentialStore == "" {
+		return "", nil
+	}
+	store, err := openCredentialStore(a.CredentialStore)
+	if err != nil {
+		return "", err
+	}
+	return store.Get(name)
+}
+
+// Environment variables that configure the CLI.
+//
+// If both FIRSTDUE_USERNAME and FIRSTDUE_PASSWORD are set, they are used instead of any configuration, which is
+// convenient for CI jobs.
+const (
+	profileEnvironmentVariable  = "FIRSTDUE_PROFILE"
+	baseURLEnvironmentVariable  = "FIRSTDUE_BASE_URL"
+	usernameEnvironmentVariable = "FIRSTDUE_USERNAME"
+	passwordEnvironmentVariable = "FIRSTDUE_PASSWORD"
+)
+
+// environmentProfile is the name used for the token cache when the credentials come from the environment.
+const environmentProfile = "_environment"
+
+// activeAccount returns the name and settings of the account to use.
+//
+// The credentials from the environment take precedence, followed by the "--profile" flag (or FIRSTDUE_PROFILE),
+// followed by the activated configuration.
+func activeAccount(args Args, config *Config) (string, Account, error) {
+	username := os.Getenv(usernameEnvironmentVariable)
+	password := os.Getenv(passwordEnvironmentVariable)
+	if username != "" && password != "" {
+		account := Account{
+			BaseURL:  os.Getenv(baseURLEnvironmentVariable),
+			Username: username,
+			Password: password,
+		}
+		return environmentProfile, account, nil
+	}
+
+	name := activeProfile(args, config)
+	if name == "" {
+		return "", Account{}, fmt.Errorf("no configuration is active; use \"config activate\", --profile, or %s", profileEnvironmentVariable)
+	}
+	account, exists := config.AccountMap[name]
+	if !exists {
+		return "", Account{}, fmt.Errorf("configuration '%s' does not exist", name)
+	}
+	return name, account, nil
+}
+
+// activeProfile returns the name of the configuration to use.
+func activeProfile(args Args, config *Config) string {
+	if args.Profile != "" {
+		return args.Profile
+	}
+	return config.DefaultAccount
+}
+
+// configDirectory returns the directory that holds the configuration, creating it if necessary.
+func configDirectory() (string, error) {
+	directory := configdir.LocalConfig("firstdue")
+	err := configdir.MakePath(directory)
+	if err != nil {
+		return "", fmt.Errorf("error creating config directory: %w", err)
+	}
+	return directory, nil
+}
+
+// configFilePath returns the path to the configuration file.
+func configFilePath() (string, error) {
+	directory, err := configDirectory()
+	if err != nil {
+		return "", err
+	}
+	return directory + string(os.PathSeparator) + "config.json", nil
+}
+
+// loadConfig reads the configuration file.
+//
+// If the file does not exist, an empty configuration is returned.
+func loadConfig() (*Config, error) {
+	var config Config
+
+	configFilePath, err := configFilePath()
+	if err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(configFilePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("error reading config file: %w", err)
+		}
+	} else {
+		err = json.Unmarshal(contents, &config)
+		if err != nil {
+			fmt.Printf("error parsing config file: %v\n", err)
+		}
+	}
+
+	if config.AccountMap == nil {
+		config.AccountMap = make(map[string]Account)
+	}
+	return &config, nil
+}
+
+// autoMigratePasswords moves any plaintext passwords into the default credential store, as "config migrate" does.
+//
+// It runs at startup.  If the passwords would go into the encrypted file, but there is neither a passphrase in the
+// environment nor a terminal to prompt for one, they are left where they are with a warning.
+func autoMigratePasswords(config *Config) {
+	plaintext := false
+	for _, account := range config.AccountMap {
+		plaintext = plaintext || account.Password != ""
+	}
+	if !plaintext {
+		return
+	}
+	if resolveCredentialStore(CredentialStoreAuto) == CredentialStoreFile && os.Getenv(passphraseEnvironmentVariable) == "" && !term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "warning: the config file has plaintext passwords; set %s or run \"firstdue config migrate\" from a terminal to move them into a credential store\n", passphraseEnvironmentVariable)
+		return
+	}
+	migrated, err := migratePasswords(config, CredentialStoreAuto)
+	for _, name := range migrated {
+		fmt.Fprintf(os.Stderr, "Moved the plaintext password of '%s' to %s.\n", name, describeCredentialStore(config.AccountMap[name]))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not move the plaintext passwords out of the config file: %v\n", err)
+	}
+}
+
+// migratePasswords moves any plaintext passwords from the configuration into the given kind of credential store, and
+// returns the names of the configurations whose passwords were moved.
+func migratePasswords(config *Config, kind string) ([]string, error) {
+	var names []string
+	for name, account := range config.AccountMap {
+		if account.Password != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var migrated []string
+	var err error
+	for _, name := range names {
+		account := config.AccountMap[name]
+		account.CredentialStore, err = storePassword(name, kind, account.Password)
+		if err != nil {
+			err = fmt.Errorf("error storing the password of '%s': %w", name, err)
+			break
+		}
+		account.Password = ""
+		config.AccountMap[name] = account
+		migrated = append(migrated, name)
+	}
+	// Save whatever was moved, even if a later password could not be.
+	if len(migrated) > 0 {
+		if saveErr := saveConfig(config); saveErr != nil {
+			return nil, saveErr
+		}
+	}
+	return migrated, err
+}
+
+// saveConfig writes the configuration file.
+func saveConfig(config *Config) error {
+	configFilePath, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	contents, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error serializing config file: %w", err)
+	}
+	// Write to a temporary file and rename it so that concurrent invocations never see a partial file.
+	err = writeFileAtomic(configFilePath, contents, 0600)
+	if err != nil {
+		return fmt.Errorf("error writing config file: %w", err)
+	}
+	return nil
+}
+
+func runConfig(ctx context.Context, args Args, config *Config) error {
+	switch {
+	case args.Config.Activate != nil:
+		_, exists := config.AccountMap[args.Config.Activate.Name]
+		if !exists {
+			return fmt.Errorf("configuration '%s' does not exist", args.Config.Activate.Name)
+		}
+		config.DefaultAccount = args.Config.Activate.Name
+		return saveConfig(config)
+	case args.Config.Configure != nil:
+		command := args.Config.Configure
+		password, err := command.PasswordOptions.password(true)
+		if err != nil {
+			return err
+		}
+		// Without a new password, an existing configuration keeps the one that it has.
+		previous := config.AccountMap[command.Name]
+		account := Account{
+			BaseURL:         command.BaseURL,
+			Username:        command.Username,
+			Password:        previous.Password,
+			CredentialStore: previous.CredentialStore,
+		}
+		if password != "" {
+			if previous.CredentialStore != "" && previous.CredentialStore != resolveCredentialStore(command.CredentialStore) {
+				deletePassword(command.Name, previous.CredentialStore)
+			}
+			account.Password = ""
+			account.CredentialStore, err = storePassword(command.Name, command.CredentialStore, password)
+			if err != nil {
+				return err
+			}
+		}
+		config.AccountMap[command.Name] = account
+		return saveConfig(config)
+	case args.Config.Edit != nil:
+		command := args.Config.Edit
+		account, exists := config.AccountMap[command.Name]
+		if !exists {
+			return fmt.Errorf("configuration '%s' does not exist", command.Name)
+		}
+		if command.BaseURL != nil {
+			account.BaseURL = *command.BaseURL
+		}
+		if command.Username != nil {
+			account.Username = *command.Username
+		}
+		password, err := command.PasswordOptions.password(false)
+		if err != nil {
+			return err
+		}
+		if password != "" {
+			kind := command.CredentialStore
+			if kind == CredentialStoreAuto && account.CredentialStore != "" {
+				kind = account.CredentialStore
+			}
+			if previous := account.CredentialStore; previous != "" && previous != resolveCredentialStore(kind) {
+				deletePassword(command.Name, previous)
+			}
+			account.CredentialStore, err = storePassword(command.Name, kind, password)
+			if err != nil {
+				return err
+			}
+		}
+		config.AccountMap[command.Name] = account
+		return saveConfig(config)
+	case args.Config.List != nil:
+		var names []string
+		for name := range config.AccountMap {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if name == activeProfile(args, config) {
+				fmt.Printf("* %s\n", name)
+			} else {
+				fmt.Printf("  %s\n", name)
+			}
+		}
+		return nil
+	case args.Config.Show != nil:
+		name := args.Config.Show.Name
+		if name == "" {
+			name = activeProfile(args, config)
+		}
+		account, exists := config.AccountMap[name]
+		if !exists {
+			return fmt.Errorf("configuration '%s' does not exist", name)
+		}
+		return printOutput(args, ConfigSummary{
+			Name:            name,
+			Active:          name == activeProfile(args, config),
+			BaseURL:         account.BaseURL,
+			Username:        account.Username,
+			CredentialStore: account.CredentialStore,
+		})
+	case args.Config.Rename != nil:
+		command := args.Config.Rename
+		account, exists := config.AccountMap[command.Name]
+		if !exists {
+			return fmt.Errorf("configuration '%s' does not exist", command.Name)
+		}
+		if _, exists := config.AccountMap[command.NewName]; exists {
+			return fmt.Errorf("configuration '%s' already exists", command.NewName)
+		}
+		if account.CredentialStore != "" {
+			store, err := openCredentialStore(account.CredentialStore)
+			if err != nil {
+				return err
+			}
+			password, err := store.Get(command.Name)
+			if err != nil {
+				return err
+			}
+			err = store.Set(command.NewName, password)
+			if err != nil {
+				return err
+			}
+		}
+		delete(config.AccountMap, command.Name)
+		config.AccountMap[command.NewName] = account
+		if config.DefaultAccount == command.Name {
+			config.DefaultAccount = command.NewName
+		}
+		err := saveConfig(config)
+		if err != nil {
+			return err
+		}
+		deletePassword(command.Name, account.CredentialStore)
+		return deleteToken(command.Name)
+	case args.Config.Migrate != nil:
+		migrated, err := migratePasswords(config, args.Config.Migrate.CredentialStore)
+		for _, name := range migrated {
+			fmt.Printf("Moved the password of '%s' to %s.\n", name, describeCredentialStore(config.AccountMap[name]))
+		}
+		if err != nil {
+			return err
+		}
+		if len(migrated) == 0 {
+			fmt.Println("There are no plaintext passwords to move.")
+		}
+		return nil
+	case args.Config.Delete != nil:
+		command := args.Config.Delete
+		account, exists := config.AccountMap[command.Name]
+		if !exists {
+			return fmt.Errorf("configuration '%s' does not exist", command.Name)
+		}
+		delete(config.AccountMap, command.Name)
+		if config.DefaultAccount == command.Name {
+			config.DefaultAccount = ""
+		}
+		err := saveConfig(config)
+		if err != nil {
+			return err
+		}
+		deletePassword(command.Name, account.CredentialStore)
+		return deleteToken(command.Name)
+	default:
+		return errUsage
+	}
+}
+
+// storePassword saves the password in the given kind of credential store and returns the concrete kind used.
+func storePassword(name string, kind string, password string) (string, error) {
+	kind = resolveCredentialStore(kind)
+	store, err := openCredentialStore(kind)
+	if err != nil {
+		return "", err
+	}
+	err = store.Set(name, password)
+	if err != nil {
+		return "", err
+	}
+	return kind, nil
+}
+
+// deletePassword removes a password from a credential store; failures are only reported as warnings.
+func deletePassword(name string, kind string) {
+	if kind == "" {
+		return
+	}
+	store, err := openCredentialStore(kind)
+	if err == nil {
+		err = store.Delete(name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: could not remove the password for '%s': %v\n", name, err)
+	}
+}
+
+// password returns the password from whichever source was given.
+//
+// If no source was given, standard input is a terminal, and prompt is true, the password is prompted for.
+func (o PasswordOptions) password(prompt bool) (string, error) {
+	switch {
+	case o.Password != "":
+		fmt.Fprintf(os.Stderr, "warning: passwords given with --password are visible in the shell history and process list\n")
+		return o.Password, nil
+	case o.PasswordStdin:
+		password, err := readSecretFrom(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("error reading password: %w", err)
+		}
+		return password, nil
+	case o.PasswordFile != "":
+		file, err := os.Open(o.PasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("error reading password file: %w", err)
+		}
+		defer file.Close()
+		password, err := readSecretFrom(file)
+		if err != nil {
+			return "", fmt.Errorf("error reading password file: %w", err)
+		}
+		return password, nil
+	}
+	if !prompt || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", nil
+	}
+	return readSecret("Password: ")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kirsle/configdir"
+)
+
+// useTemporaryConfig points the configuration at a temporary directory with the encrypted credential file.
+func useTemporaryConfig(t *testing.T) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "") // No keyring.
+	t.Setenv(passphraseEnvironmentVariable, "passphrase")
+	configdir.Refresh()
+	t.Cleanup(configdir.Refresh)
+}
+
+func TestAutoMigratePasswords(t *testing.T) {
+	useTemporaryConfig(t)
+	config := &Config{AccountMap: map[string]Account{
+		"prod": {Username: "prod@example.com", Password: "secret"},
+	}}
+
+	// Without a passphrase or a terminal, the passwords stay where they are.
+	t.Setenv(passphraseEnvironmentVariable, "")
+	autoMigratePasswords(config)
+	if config.AccountMap["prod"].Password != "secret" {
+		t.Fatalf("the password was moved without a passphrase")
+	}
+
+	t.Setenv(passphraseEnvironmentVariable, "passphrase")
+	autoMigratePasswords(config)
+	account := config.AccountMap["prod"]
+	if account.Password != "" || account.CredentialStore != CredentialStoreFile {
+		t.Fatalf("the password was not moved: %+v", account)
+	}
+	if password, err := account.password("prod"); err != nil || password != "secret" {
+		t.Errorf("got password %q (%v)", password, err)
+	}
+	saved, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.AccountMap["prod"].Password != "" {
+		t.Errorf("the plaintext password is still in the config file")
+	}
+}
+
+func TestConfigureKeepsPassword(t *testing.T) {
+	useTemporaryConfig(t)
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordFile, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{AccountMap: map[string]Account{}}
+	configure := func(command ConfigConfigureCommand) {
+		t.Helper()
+		command.Name = "prod"
+		command.CredentialStore = CredentialStoreAuto
+		if err := runConfig(context.Background(), Args{Config: &ConfigCommand{Configure: &command}}, config); err != nil {
+			t.Fatalf("configure: %v", err)
+		}
+	}
+
+	configure(ConfigConfigureCommand{Username: "prod@example.com", PasswordOptions: PasswordOptions{PasswordFile: passwordFile}})
+	// Configuring it again without a password keeps the stored one.
+	configure(ConfigConfigureCommand{Username: "prod@example.com", BaseURL: "http://localhost:8080"})
+
+	account := config.AccountMap["prod"]
+	if account.BaseURL != "http://localhost:8080" || account.CredentialStore != CredentialStoreFile {
+		t.Fatalf("unexpected account: %+v", account)
+	}
+	if password, err := account.password("prod"); err != nil || password != "secret" {
+		t.Errorf("got password %q (%v)", password, err)
+	}
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Credential stores.
+const (
+	CredentialStoreAuto    = "auto"    // Use the system keyring if there is one, and the encrypted file otherwise.
+	CredentialStoreFile    = "file"    // An encrypted file in the config directory.
+	CredentialStoreKeyring = "keyring" // The system keyring.
+)
+
+// passphraseEnvironmentVariable holds the passphrase for the encrypted credential file, for non-interactive use.
+const passphraseEnvironmentVariable = "FIRSTDUE_PASSPHRASE"
+
+// credentialStore stores the password for each configuration.
+type credentialStore interface {
+	Get(name string) (string, error)
+	Set(name string, password string) error
+	Delete(name string) error
+}
+
+// openCredentialStore returns the credential store of the given kind.
+func openCredentialStore(kind string) (credentialStore, error) {
+	switch kind {
+	case CredentialStoreAuto, "":
+		if keyringAvailable() {
+			return keyringCredentialStore{}, nil
+		}
+		return newFileCredentialStore()
+	case CredentialStoreFile:
+		return newFileCredentialStore()
+	case CredentialStoreKeyring:
+		if !keyringAvailable() {
+			return nil, fmt.Errorf("no system keyring is available")
+		}
+		return keyringCredentialStore{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported credential store: %q", kind)
+	}
+}
+
+// resolveCredentialStore returns the concrete kind of credential store for the given (possibly "auto") kind.
+func resolveCredentialStore(kind string) string {
+	if kind == CredentialStoreAuto || kind == "" {
+		if keyringAvailable() {
+			return CredentialStoreKeyring
+		}
+		return CredentialStoreFile
+	}
+	return kind
+}
+
+// readSecret prompts for a secret on the terminal without echoing it.
+func readSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("cannot prompt for %s: standard input is not a terminal", strings.ToLower(strings.TrimRight(prompt, ": ")))
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	contents, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", strings.ToLower(strings.TrimRight(prompt, ": ")), err)
+	}
+	return string(contents), nil
+}
+
+// readSecretFrom reads a secret from the first line of a reader.
+func readSecretFrom(reader io.Reader) (string, error) {
+	line, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// fileCredentialStore keeps the passwords in a file that is encrypted with AES-GCM using a key derived from a
+// passphrase.
+//
+// The passphrase is read from the FIRSTDUE_PASSPHRASE environment variable, or prompted for on the terminal.
+type fileCredentialStore struct {
+	path       string
+	passphrase string
+}
+
+// encryptedCredentialFile is the on-disk format of the credential file.
+type encryptedCredentialFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+const (
+	credentialFileVersion    = 1
+	credentialFileKDF        = "pbkdf2-sha256"
+	credentialFileIterations = 600000
+)
+
+func newFileCredentialStore() (*fileCredentialStore, error) {
+	directory, err := configDirectory()
+	if err != nil {
+		return nil, err
+	}
+	s := &fileCredentialStore{
+		path: directory + string(os.PathSeparator) + "credentials.enc",
+	}
+	return s, nil
+}
+
+func (s *fileCredentialStore) Get(name string) (string, error) {
+	passwords, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	password, ok := passwords[name]
+	if !ok {
+		return "", fmt.Errorf("no password is stored for configuration '%s'", name)
+	}
+	return password, nil
+}
+
+func (s *fileCredentialStore) Set(name string, password string) error {
+	passwords, err := s.load()
+	if err != nil {
+		return err
+	}
+	passwords[name] = password
+	return s.save(passwords)
+}
+
+func (s *fileCredentialStore) Delete(name string) error {
+	passwords, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := passwords[name]; !ok {
+		return nil
+	}
+	delete(passwords, name)
+	return s.save(passwords)
+}
+
+// getPassphrase returns the passphrase, prompting for it if necessary.
+//
+// When the file is being created, the passphrase is confirmed.
+func (s *fileCredentialStore) getPassphrase(creating bool) (string, error) {
+	if s.passphrase != "" {
+		return s.passphrase, nil
+	}
+	if passphrase := os.Getenv(passphraseEnvironmentVariable); passphrase != "" {
+		s.passphrase = passphrase
+		return s.passphrase, nil
+	}
+	passphrase, err := readSecret("Credential passphrase: ")
+	if err != nil {
+		return "", fmt.Errorf("%w (set %s to provide it non-interactively)", err, passphraseEnvironmentVariable)
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("the passphrase cannot be empty")
+	}
+	if creating {
+		confirmation, err := readSecret("Confirm passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if confirmation != passphrase {
+			return "", fmt.Errorf("the passphrases do not match")
+		}
+	}
+	s.passphrase = passphrase
+	return s.passphrase, nil
+}
+
+func (s *fileCredentialStore) load() (map[string]string, error) {
+	passwords := map[string]string{}
+	contents, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return passwords, nil
+		}
+		return nil, fmt.Errorf("error reading credential file: %w", err)
+	}
+	var file encryptedCredentialFile
+	err = json.Unmarshal(contents, &file)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing credential file: %w", err)
+	}
+	if file.Version != credentialFileVersion || file.KDF != credentialFileKDF {
+		return nil, fmt.Errorf("unsupported credential file: version %d, KDF %q", file.Version, file.KDF)
+	}
+	passphrase, err := s.getPassphrase(false)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := credentialCipher(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		s.passphrase = ""
+		return nil, fmt.Errorf("could not decrypt the credential file; the passphrase is probably wrong")
+	}
+	err = json.Unmarshal(plaintext, &passwords)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing credential file: %w", err)
+	}
+	return passwords, nil
+}
+
+func (s *fileCredentialStore) save(passwords map[string]string) error {
+	_, err := os.Stat(s.path)
+	creating := os.IsNotExist(err)
+	passphrase, err := s.getPassphrase(creating)
+	if err != nil {
+		return err
+	}
+
+	file := encryptedCredentialFile{
+		Version:    credentialFileVersion,
+		KDF:        credentialFileKDF,
+		Iterations: credentialFileIterations,
+		Salt:       make([]byte, 16),
+	}
+	_, err = rand.Read(file.Salt)
+	if err != nil {
+		return err
+	}
+	aead, err := credentialCipher(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, aead.NonceSize())
+	_, err = rand.Read(file.Nonce)
+	if err != nil {
+		return err
+	}
+	plaintext, err := json.Marshal(passwords)
+	if err != nil {
+		return err
+	}
+	file.Ciphertext = aead.Seal(nil, file.Nonce, plaintext, nil)
+
+	contents, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(s.path, contents, 0600)
+	if err != nil {
+		return fmt.Errorf("error writing credential file: %w", err)
+	}
+	return nil
+}
+
+// credentialCipher derives the key from the passphrase and returns the AES-GCM cipher.
+func credentialCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// keyringService is the service name that the passwords are stored under in the system keyring.
+const keyringService = "firstdue"
+
+// keyringCredentialStore keeps the passwords in the system keyring.
+//
+// This uses the "security" tool on macOS and the "secret-tool" tool (libsecret) on Linux.
+type keyringCredentialStore struct{}
+
+// keyringAvailable returns true if there is a supported system keyring.
+func keyringAvailable() bool {
+	switch runtime.GOOS {
+	case "darwin":
+		_, err := exec.LookPath("security")
+		return err == nil
+	case "linux", "freebsd", "openbsd":
+		if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+			return false
+		}
+		_, err := exec.LookPath("secret-tool")
+		return err == nil
+	}
+	return false
+}
+
+func (keyringCredentialStore) Get(name string) (string, error) {
+	var output []byte
+	var err error
+	if runtime.GOOS == "darwin" {
+		output, err = runKeyringCommand(nil, "security", "find-generic-password", "-s", keyringService, "-a", name, "-w")
+	} else {
+		output, err = runKeyringCommand(nil, "secret-tool", "lookup", "service", keyringService, "account", name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("no password is stored in the keyring for configuration '%s': %w", name, err)
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
+func (keyringCredentialStore) Set(name string, password string) error {
+	var err error
+	if runtime.GOOS == "darwin" {
+		// Send the command on standard input (with the password hex-encoded) so that the password never appears in
+		// the process list.
+		command := fmt.Sprintf("add-generic-password -U -s %q -a %q -X %s\n", keyringService, name, hex.EncodeToString([]byte(password)))
+		_, err = runKeyringCommand(strings.NewReader(command), "security", "-i")
+	} else {
+		_, err = runKeyringCommand(strings.NewReader(password), "secret-tool", "store", "--label", "First Due ("+name+")", "service", keyringService, "account", name)
+	}
+	if err != nil {
+		return fmt.Errorf("error storing password in the keyring: %w", err)
+	}
+	return nil
+}
+
+func (keyringCredentialStore) Delete(name string) error {
+	var err error
+	if runtime.GOOS == "darwin" {
+		_, err = runKeyringCommand(nil, "security", "delete-generic-password", "-s", keyringService, "-a", name)
+	} else {
+		_, err = runKeyringCommand(nil, "secret-tool", "clear", "service", keyringService, "account", name)
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil // There was nothing to delete.
+		}
+		return fmt.Errorf("error removing password from the keyring: %w", err)
+	}
+	return nil
+}
+
+func runKeyringCommand(stdin io.Reader, name string, arguments ...string) ([]byte, error) {
+	command := exec.Command(name, arguments...)
+	command.Stdin = stdin
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+	output, err := command.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%w: %s", err, message)
+		}
+		return nil, err
+	}
+	return output, nil
+}
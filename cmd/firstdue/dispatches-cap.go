@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tekkamanendless/firstdue"
+	"github.com/tekkamanendless/firstdue/capalert"
+)
+
+// defaultCAPAgency is the agency used when there is none for the active profile.
+const defaultCAPAgency = "default"
+
+func runDispatchesCAP(ctx context.Context, args Args, config *Config) error {
+	command := args.Dispatches.CAP
+
+	capConfig, err := capalert.LoadConfig(command.Config)
+	if err != nil {
+		return err
+	}
+	agencyName := command.Agency
+	if agencyName == "" {
+		agencyName, _, _ = activeAccount(args, config)
+		if _, ok := capConfig.Agencies[agencyName]; !ok {
+			agencyName = defaultCAPAgency
+		}
+	}
+	agency, ok := capConfig.Agencies[agencyName]
+	if !ok {
+		return fmt.Errorf("the CAP config has no agency %q", agencyName)
+	}
+	agency.Suppress = append(agency.Suppress, command.Suppress...)
+
+	since, err := parseSince(command.Since)
+	if err != nil {
+		return err
+	}
+	client, err := newClient(ctx, args, config)
+	if err != nil {
+		return err
+	}
+	dispatches, err := client.GetDispatches(ctx, firstdue.GetDispatchesRequest{Since: since})
+	if err != nil {
+		return fmt.Errorf("error listing dispatches: %w", err)
+	}
+
+	var alerts []*capalert.Alert
+	for _, dispatch := range dispatches {
+		if command.ID != 0 && dispatch.ID != command.ID {
+			continue
+		}
+		alert, err := agency.Alert(dispatch)
+		if errors.Is(err, capalert.ErrSuppressed) {
+			fmt.Fprintf(os.Stderr, "Suppressed dispatch %d (%s %s).\n", dispatch.ID, dispatch.IncidentTypeCode, dispatch.Type)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error converting dispatch %d: %w", dispatch.ID, err)
+		}
+		alerts = append(alerts, alert)
+	}
+	if command.ID != 0 && len(alerts) == 0 {
+		return fmt.Errorf("dispatch %d was not found or is suppressed", command.ID)
+	}
+
+	if command.Dir == "" {
+		if len(alerts) > 1 {
+			return fmt.Errorf("%d alerts were produced; use --dir to write one file per alert, or --id to choose one", len(alerts))
+		}
+		for _, alert := range alerts {
+			err := capalert.Write(os.Stdout, alert)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = os.MkdirAll(command.Dir, 0o755)
+	if err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+	for _, alert := range alerts {
+		path := filepath.Join(command.Dir, filepath.Base(alert.Identifier)+".xml")
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("error writing alert: %w", err)
+		}
+		err = capalert.Write(file, alert)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("error writing alert: %w", err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d alerts to %s.\n", len(alerts), command.Dir)
+	return nil
+}
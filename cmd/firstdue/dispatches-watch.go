@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// watchEvent is how a dispatch event is printed and sent to the hooks.
+type watchEvent struct {
+	Event string `json:"event"`
+	firstdue.GetDispatchesResponseDispatch
+}
+
+func runDispatchesWatch(ctx context.Context, args Args, config *Config) error {
+	command := args.Dispatches.Watch
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	name, _, err := activeAccount(args, config)
+	if err != nil {
+		return err
+	}
+	stateFile := command.StateFile
+	if stateFile == "" && !command.NoState {
+		directory, err := configDirectory()
+		if err != nil {
+			return err
+		}
+		stateFile = directory + string(os.PathSeparator) + "watch-" + url.PathEscape(name) + ".json"
+	}
+
+	var state firstdue.DispatchWatcherState
+	if !command.NoState {
+		contents, err := os.ReadFile(stateFile)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error reading state file: %w", err)
+		}
+		if err == nil {
+			err = json.Unmarshal(contents, &state)
+			if err != nil {
+				return fmt.Errorf("error parsing state file: %w", err)
+			}
+		}
+	}
+	if state.HighWaterMark.IsZero() {
+		state.HighWaterMark, err = parseSince(command.Since)
+		if err != nil {
+			return err
+		}
+	}
+
+	client, err := newClient(ctx, args, config)
+	if err != nil {
+		return err
+	}
+	watcher := firstdue.NewDispatchWatcher(client, state, command.Lookback)
+	fmt.Fprintf(os.Stderr, "Watching for dispatches every %s; press Ctrl-C to stop.\n", command.Interval)
+
+	// Each event is only printed and recorded in the state once it was delivered; a failed delivery leaves that event
+	// and the ones after it for the next poll, so that they are delivered in order and printed once.
+	return watcher.Run(ctx, command.Interval, func(event firstdue.DispatchEvent) error {
+		if watchMatches(command, event.Dispatch) {
+			output := watchEvent{
+				Event:                         event.Type,
+				GetDispatchesResponseDispatch: event.Dispatch,
+			}
+			err := deliverWatchEvent(ctx, command, output)
+			if err != nil {
+				return fmt.Errorf("%w: %w", firstdue.ErrRetryLater, err)
+			}
+			err = printOutput(args, output)
+			if err != nil {
+				return err
+			}
+		}
+		watcher.Acknowledge(event)
+		if !command.NoState {
+			err := saveWatchState(stateFile, watcher.State())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			}
+		}
+		return nil
+	})
+}
+
+// deliverWatchEvent runs the hook and posts to the webhook of the watch command, if they are set.
+func deliverWatchEvent(ctx context.Context, command *DispatchesWatchCommand, event watchEvent) error {
+	if command.Exec != "" {
+		err := runWatchExec(ctx, command.Exec, event)
+		if err != nil {
+			return fmt.Errorf("hook failed for dispatch %d: %w", event.ID, err)
+		}
+	}
+	if command.Webhook != "" {
+		err := postWatchWebhook(ctx, command.Webhook, event)
+		if err != nil {
+			return fmt.Errorf("webhook failed for dispatch %d: %w", event.ID, err)
+		}
+	}
+	return nil
+}
+
+// watchMatches returns true if the dispatch passes the filters of the watch command.
+func watchMatches(command *DispatchesWatchCommand, dispatch firstdue.GetDispatchesResponseDispatch) bool {
+	if len(command.UnitCodes) > 0 {
+		found := false
+		for _, unitCode := range dispatch.UnitCodes {
+			if containsFold(command.UnitCodes, unitCode) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(command.IncidentTypes) > 0 && !containsFold(command.IncidentTypes, dispatch.IncidentTypeCode) {
+		return false
+	}
+	if len(command.Statuses) > 0 && !containsFold(command.Statuses, dispatch.StatusCode) {
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// runWatchExec runs the shell hook with the event as JSON on standard input.
+//
+// The most useful fields are also given as environment variables.
+func runWatchExec(ctx context.Context, script string, event watchEvent) error {
+	contents, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	var command *exec.Cmd
+	if runtime.GOOS == "windows" {
+		command = exec.CommandContext(ctx, "cmd", "/C", script)
+	} else {
+		command = exec.CommandContext(ctx, "sh", "-c", script)
+	}
+	command.Stdin = bytes.NewReader(contents)
+	command.Stdout = os.Stderr
+	command.Stderr = os.Stderr
+	command.Env = append(os.Environ(),
+		"FIRSTDUE_EVENT="+event.Event,
+		fmt.Sprintf("FIRSTDUE_DISPATCH_ID=%d", event.ID),
+		"FIRSTDUE_DISPATCH_TYPE="+event.Type,
+		"FIRSTDUE_INCIDENT_TYPE_CODE="+event.IncidentTypeCode,
+		"FIRSTDUE_STATUS_CODE="+event.StatusCode,
+		"FIRSTDUE_ADDRESS="+event.Address,
+		"FIRSTDUE_CITY="+event.City,
+		"FIRSTDUE_UNIT_CODES="+strings.Join(event.UnitCodes, ","),
+		"FIRSTDUE_MESSAGE="+event.Message,
+	)
+	return command.Run()
+}
+
+// postWatchWebhook sends the event as JSON to the webhook.
+func postWatchWebhook(ctx context.Context, webhook string, event watchEvent) error {
+	contents, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(contents))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status: %s", response.Status)
+	}
+	return nil
+}
+
+func saveWatchState(path string, state firstdue.DispatchWatcherState) error {
+	contents, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(path, contents, 0600)
+	if err != nil {
+		return fmt.Errorf("error writing state file: %w", err)
+	}
+	return nil
+}
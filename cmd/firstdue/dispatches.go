@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+type DispatchesCommand struct {
+	List  *DispatchesListCommand  `arg:"subcommand" help:"List dispatches"`
+	Watch *DispatchesWatchCommand `arg:"subcommand" help:"Print new and updated dispatches as they arrive"`
+	CAP   *DispatchesCAPCommand   `arg:"subcommand:cap" help:"Export dispatches as CAP 1.2 alerts"`
+}
+
+type DispatchesListCommand struct {
+	Since string `arg:"--since" help:"Only list dispatches since this time (RFC 3339, or a duration such as 2h)"`
+	Page  int    `arg:"--page" help:"Page number"`
+}
+
+type DispatchesWatchCommand struct {
+	Since         string        `arg:"--since" help:"Start from this time when there is no saved state (RFC 3339, or a duration such as 2h)"`
+	Interval      time.Duration `arg:"--interval" default:"30s" help:"How often to poll for dispatches"`
+	Lookback      time.Duration `arg:"--lookback" default:"1h" help:"How far before the newest dispatch to look for updates"`
+	UnitCodes     []string      `arg:"--unit-code,separate" help:"Only report dispatches for this unit (repeatable)"`
+	IncidentTypes []string      `arg:"--incident-type,separate" help:"Only report dispatches with this incident type code (repeatable)"`
+	Statuses      []string      `arg:"--status,separate" help:"Only report dispatches with this status code (repeatable)"`
+	Exec          string        `arg:"--exec" help:"Shell command to run for each event; the event is given as JSON on standard input, and it is sent again at the next poll if the command fails"`
+	Webhook       string        `arg:"--webhook" help:"URL to POST each event to as JSON; the event is sent again at the next poll if the request fails"`
+	StateFile     string        `arg:"--state-file" placeholder:"FILE" help:"File that holds the high-water mark between runs (defaults to one per profile in the config directory)"`
+	NoState       bool          `arg:"--no-state" help:"Do not load or save the high-water mark"`
+}
+
+type DispatchesCAPCommand struct {
+	Config   string   `arg:"--config,required" placeholder:"FILE" help:"YAML or JSON file with the CAP settings of each agency"`
+	Agency   string   `arg:"--agency" help:"Agency in the CAP config to send as (defaults to the one named after the profile, or \"default\")"`
+	Since    string   `arg:"--since" default:"1h" help:"Only export dispatches since this time (RFC 3339, or a duration such as 2h)"`
+	ID       int      `arg:"--id" help:"Only export the dispatch with this ID"`
+	Suppress []string `arg:"--suppress,separate" help:"Also suppress this incident type code or dispatch type; patterns such as 32* are allowed (repeatable)"`
+	Dir      string   `arg:"--dir" help:"Write one file per alert to this directory instead of standard output"`
+}
+
+func runDispatches(ctx context.Context, args Args, config *Config) error {
+	switch {
+	case args.Dispatches.List != nil:
+		since, err := parseSince(args.Dispatches.List.Since)
+		if err != nil {
+			return err
+		}
+		client, err := newClient(ctx, args, config)
+		if err != nil {
+			return err
+		}
+		output, err := client.GetDispatches(ctx, firstdue.GetDispatchesRequest{
+			Page:  args.Dispatches.List.Page,
+			Since: since,
+		})
+		if err != nil {
+			return fmt.Errorf("error listing dispatches: %w", err)
+		}
+		return printOutput(args, output)
+	case args.Dispatches.Watch != nil:
+		return runDispatchesWatch(ctx, args, config)
+	case args.Dispatches.CAP != nil:
+		return runDispatchesCAP(ctx, args, config)
+	default:
+		return errUsage
+	}
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+	"github.com/tekkamanendless/httperror"
+)
+
+type DoctorCommand struct {
+	Timeout time.Duration `arg:"--timeout" default:"10s" help:"Time limit for each network check"`
+	JSON    bool          `arg:"--json" help:"Print the results in the --output format instead of as a summary"`
+}
+
+// Doctor check statuses.
+const (
+	doctorPass = "pass"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+	doctorSkip = "skip"
+)
+
+// certificateExpiryWarning is how soon before its expiry a server certificate is reported.
+const certificateExpiryWarning = 14 * 24 * time.Hour
+
+// DoctorCheck is the result of a single diagnostic check.
+type DoctorCheck struct {
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Hint     string `json:"hint,omitempty"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// DoctorReport is the result of all of the diagnostic checks.
+type DoctorReport struct {
+	Profile string        `json:"profile,omitempty"`
+	BaseURL string        `json:"baseURL,omitempty"`
+	Healthy bool          `json:"healthy"`
+	Checks  []DoctorCheck `json:"checks"`
+}
+
+// doctorWarning is returned by a check that found a problem that does not stop the CLI from working.
+type doctorWarning struct {
+	error
+}
+
+// doctorResult is what a check function returns.
+type doctorResult struct {
+	detail string
+	hint   string // Only used if there is an error.
+	err    error  // If this is a doctorWarning, the check only warns.
+}
+
+// doctor runs the checks in order.
+//
+// Once a blocking check fails, every check after it is skipped, since it could only fail for the same reason.
+type doctor struct {
+	timeout time.Duration
+	report  DoctorReport
+	blocked bool
+}
+
+func (d *doctor) run(ctx context.Context, name string, blocking bool, fn func(ctx context.Context) doctorResult) {
+	if d.blocked {
+		d.report.Checks = append(d.report.Checks, DoctorCheck{Name: name, Status: doctorSkip, Detail: "skipped because an earlier check failed"})
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := fn(ctx)
+	check := DoctorCheck{
+		Name:     name,
+		Status:   doctorPass,
+		Detail:   result.detail,
+		Duration: time.Since(start).Round(time.Millisecond).String(),
+	}
+	if result.err != nil {
+		check.Status = doctorFail
+		var warning doctorWarning
+		if errors.As(result.err, &warning) {
+			check.Status = doctorWarn
+		}
+		check.Detail = result.err.Error()
+		check.Hint = result.hint
+	}
+	d.report.Checks = append(d.report.Checks, check)
+	if check.Status == doctorFail && blocking {
+		d.blocked = true
+	}
+}
+
+func runDoctor(ctx context.Context, args Args, config *Config) error {
+	d := &doctor{timeout: args.Doctor.Timeout}
+
+	var name string
+	var account Account
+	var password string
+	var baseURL *url.URL
+	var token string
+
+	d.run(ctx, "config", true, func(ctx context.Context) doctorResult {
+		path, err := configFilePath()
+		if err != nil {
+			return doctorResult{err: err, hint: "Check that the home directory exists and is writable."}
+		}
+		detail := path
+		contents, err := os.ReadFile(path)
+		switch {
+		case os.IsNotExist(err):
+			detail = "no config file"
+		case err != nil:
+			return doctorResult{err: err, hint: "Check the permissions of the config file."}
+		default:
+			var fileConfig Config
+			err = json.Unmarshal(contents, &fileConfig)
+			if err != nil {
+				return doctorResult{err: fmt.Errorf("error parsing %s: %w", path, err), hint: "Fix the config file, or remove it and run \"firstdue config configure\" again."}
+			}
+		}
+		name, account, err = activeAccount(args, config)
+		if err != nil {
+			return doctorResult{err: err, hint: "Run \"firstdue config configure\" and \"firstdue config activate\", or set FIRSTDUE_USERNAME and FIRSTDUE_PASSWORD."}
+		}
+		if name == environmentProfile {
+			return doctorResult{detail: detail + "; using the credentials from the environment"}
+		}
+		return doctorResult{detail: fmt.Sprintf("%s; profile '%s'", detail, name)}
+	})
+	d.report.Profile = name
+
+	d.run(ctx, "credentials", true, func(ctx context.Context) doctorResult {
+		if account.Username == "" {
+			return doctorResult{err: fmt.Errorf("no username is configured"), hint: "Run \"firstdue config edit --username ...\"."}
+		}
+		var err error
+		password, err = account.password(name)
+		if err != nil {
+			return doctorResult{err: err, hint: fmt.Sprintf("Run \"firstdue config edit %s --password-stdin\" to store the password again, or set %s if the encrypted file is used.", name, passphraseEnvironmentVariable)}
+		}
+		if password == "" {
+			return doctorResult{err: fmt.Errorf("no password is configured"), hint: fmt.Sprintf("Run \"firstdue config edit %s --password-stdin\".", name)}
+		}
+		if name != environmentProfile && account.Password != "" {
+			return doctorResult{err: doctorWarning{fmt.Errorf("username %s; the password is in plaintext in the config file", account.Username)}, hint: "Run \"firstdue config migrate\" to move it into a credential store."}
+		}
+		return doctorResult{detail: fmt.Sprintf("username %s; password from %s", account.Username, describeCredentialStore(account))}
+	})
+
+	d.run(ctx, "base URL", true, func(ctx context.Context) doctorResult {
+		value := account.BaseURL
+		if value == "" {
+			value = firstdue.BaseURL
+		}
+		d.report.BaseURL = value
+		var err error
+		baseURL, err = url.Parse(value)
+		if err == nil && (baseURL.Scheme != "http" && baseURL.Scheme != "https" || baseURL.Host == "") {
+			err = fmt.Errorf("%q is not an HTTP URL", value)
+		}
+		if err != nil {
+			return doctorResult{err: err, hint: fmt.Sprintf("Set the base URL to %s unless you were given a different one.", firstdue.BaseURL)}
+		}
+		if baseURL.Scheme != "https" {
+			return doctorResult{err: doctorWarning{fmt.Errorf("%s does not use HTTPS; the password is sent in the clear", value)}, hint: "Use an https:// base URL."}
+		}
+		return doctorResult{detail: value}
+	})
+
+	d.run(ctx, "DNS", true, func(ctx context.Context) doctorResult {
+		addresses, err := net.DefaultResolver.LookupHost(ctx, baseURL.Hostname())
+		if err != nil {
+			return doctorResult{err: err, hint: "Check the network connection and DNS settings, and that the base URL's host name is spelled correctly."}
+		}
+		return doctorResult{detail: fmt.Sprintf("%s resolves to %s", baseURL.Hostname(), strings.Join(addresses, ", "))}
+	})
+
+	d.run(ctx, "TCP", true, func(ctx context.Context) doctorResult {
+		var dialer net.Dialer
+		connection, err := dialer.DialContext(ctx, "tcp", doctorHostPort(baseURL))
+		if err != nil {
+			return doctorResult{err: err, hint: "A firewall or proxy may be blocking the connection; check that outbound access to this host and port is allowed."}
+		}
+		defer connection.Close()
+		return doctorResult{detail: "connected to " + connection.RemoteAddr().String()}
+	})
+
+	d.run(ctx, "TLS", true, func(ctx context.Context) doctorResult {
+		if baseURL.Scheme != "https" {
+			return doctorResult{detail: "not used"}
+		}
+		dialer := tls.Dialer{Config: &tls.Config{ServerName: baseURL.Hostname()}}
+		connection, err := dialer.DialContext(ctx, "tcp", doctorHostPort(baseURL))
+		if err != nil {
+			return doctorResult{err: err, hint: tlsHint(err)}
+		}
+		defer connection.Close()
+		state := connection.(*tls.Conn).ConnectionState()
+		certificate := state.PeerCertificates[0]
+		detail := fmt.Sprintf("%s; certificate for %s issued by %s, expires %s", tls.VersionName(state.Version), certificate.Subject.CommonName, certificate.Issuer.CommonName, certificate.NotAfter.Format(time.DateOnly))
+		if time.Until(certificate.NotAfter) < certificateExpiryWarning {
+			return doctorResult{err: doctorWarning{fmt.Errorf("%s; the certificate expires soon", detail)}, hint: "Tell First Due support that the certificate is about to expire."}
+		}
+		return doctorResult{detail: detail}
+	})
+
+	d.run(ctx, "authentication", true, func(ctx context.Context) doctorResult {
+		client := firstdue.NewClient(doctorClientOptions(args, account)...)
+		output, err := client.PostAuthToken(ctx, firstdue.PostAuthTokenRequest{
+			GrantType: "client_credentials",
+			Email:     account.Username,
+			Password:  password,
+		})
+		if err != nil {
+			return doctorResult{err: err, hint: apiHint(err, "Check the username and password with \"firstdue config edit\".")}
+		}
+		token = output.AccessToken
+		expiresIn := time.Duration(output.ExpiresIn) * time.Second
+		return doctorResult{detail: fmt.Sprintf("scope %q; token expires in %s (at %s)", output.Scope, expiresIn, time.Now().Add(expiresIn).Format(time.RFC3339))}
+	})
+
+	client := firstdue.NewClient(append(doctorClientOptions(args, account), firstdue.WithToken(token))...)
+	d.run(ctx, "logs settings", false, func(ctx context.Context) doctorResult {
+		output, err := client.GetLogsSettings(ctx, firstdue.GetLogsSettingsRequest{})
+		if err != nil {
+			return doctorResult{err: err, hint: apiHint(err, "")}
+		}
+		return doctorResult{detail: fmt.Sprintf("connector logging enabled: %t", output.IsFdapiConnectorLogEnabled)}
+	})
+	d.run(ctx, "stations", false, func(ctx context.Context) doctorResult {
+		output, err := client.GetStations(ctx, firstdue.GetStationsRequest{})
+		if err != nil {
+			return doctorResult{err: err, hint: apiHint(err, "")}
+		}
+		return doctorResult{detail: fmt.Sprintf("%d stations", output.Total)}
+	})
+	d.run(ctx, "apparatuses", false, func(ctx context.Context) doctorResult {
+		output, err := client.GetApparatuses(ctx, firstdue.GetApparatusesRequest{})
+		if err != nil {
+			return doctorResult{err: err, hint: apiHint(err, "")}
+		}
+		return doctorResult{detail: fmt.Sprintf("%d apparatuses", output.Total)}
+	})
+
+	d.report.Healthy = true
+	for _, check := range d.report.Checks {
+		if check.Status == doctorFail || check.Status == doctorSkip {
+			d.report.Healthy = false
+		}
+	}
+	if args.Doctor.JSON {
+		err := printOutput(args, d.report)
+		if err != nil {
+			return err
+		}
+	} else {
+		printDoctorReport(d.report)
+	}
+	if !d.report.Healthy {
+		return fmt.Errorf("one or more checks failed")
+	}
+	return nil
+}
+
+// doctorClientOptions returns the options for an unauthenticated client for the account.
+func doctorClientOptions(args Args, account Account) []firstdue.ClientOption {
+	var options []firstdue.ClientOption
+	if args.Debug {
+		options = append(options, firstdue.WithDebug(true))
+	}
+	if account.BaseURL != "" {
+		options = append(options, firstdue.WithBaseURL(account.BaseURL))
+	}
+	return options
+}
+
+// doctorHostPort returns the host and port to connect to for the URL.
+func doctorHostPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// describeCredentialStore returns where the password of the account comes from.
+func describeCredentialStore(account Account) string {
+	switch {
+	case account.Password != "":
+		return "the environment or the config file"
+	case account.CredentialStore == CredentialStoreFile:
+		return "the encrypted credential file"
+	case account.CredentialStore == CredentialStoreKeyring:
+		return "the system keyring"
+	}
+	return account.CredentialStore
+}
+
+// tlsHint returns a remediation hint for a TLS error.
+func tlsHint(err error) string {
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return "The certificate is not trusted; a proxy may be intercepting the connection.  Install the proxy's CA certificate, or ask IT to exempt this host."
+	case errors.As(err, &invalid) && invalid.Reason == x509.Expired:
+		return "The certificate is expired or not yet valid; check that the system clock is correct."
+	case errors.As(err, &hostname):
+		return "The certificate does not match the host name; check the base URL."
+	}
+	return "Check that nothing between this machine and the server is interfering with HTTPS."
+}
+
+// apiHint returns a remediation hint for an error from the API.
+func apiHint(err error, fallback string) string {
+	switch httperror.StatusFromError(err) {
+	case 401:
+		return "The credentials were rejected; check the username and password with \"firstdue config edit\"."
+	case 403:
+		return "The account is not allowed to use this endpoint; check the API user's permissions in First Due."
+	case 404:
+		return fmt.Sprintf("The endpoint was not found; check that the base URL includes the API path (the default is %s).", firstdue.BaseURL)
+	case 429:
+		return "The API is rate limiting this account; try again later."
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "The server took too long to respond; try a longer --timeout."
+	}
+	return fallback
+}
+
+// printDoctorReport prints the report as a human-readable summary.
+func printDoctorReport(report DoctorReport) {
+	if report.Profile != "" {
+		fmt.Printf("Profile:  %s\n", report.Profile)
+	}
+	if report.BaseURL != "" {
+		fmt.Printf("Base URL: %s\n", report.BaseURL)
+	}
+	fmt.Println()
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	counts := map[string]int{}
+	for _, check := range report.Checks {
+		counts[check.Status]++
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", strings.ToUpper(check.Status), check.Name, check.Detail)
+		if check.Hint != "" {
+			fmt.Fprintf(writer, "\t\thint: %s\n", check.Hint)
+		}
+	}
+	writer.Flush()
+
+	fmt.Printf("\n%d passed, %d warnings, %d failed, %d skipped\n", counts[doctorPass], counts[doctorWarn], counts[doctorFail], counts[doctorSkip])
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+	"gopkg.in/yaml.v3"
+)
+
+// readInputFile reads a JSON or YAML file into the target.
+//
+// If the path is "-", standard input is read instead.  The target is always populated using its JSON field names,
+// so a YAML file uses the same keys as the API.
+func readInputFile(path string, target any) error {
+	var contents []byte
+	var err error
+	if path == "-" {
+		contents, err = io.ReadAll(os.Stdin)
+	} else {
+		contents, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("error reading input file: %w", err)
+	}
+
+	trimmed := bytes.TrimSpace(contents)
+	if !bytes.HasPrefix(trimmed, []byte("{")) && !bytes.HasPrefix(trimmed, []byte("[")) {
+		contents, err = yamlToJSON(contents)
+		if err != nil {
+			return fmt.Errorf("error parsing input file: %w", err)
+		}
+	}
+	err = json.Unmarshal(contents, target)
+	if err != nil {
+		return fmt.Errorf("error parsing input file: %w", err)
+	}
+	return nil
+}
+
+// yamlToJSON converts a YAML document into JSON.
+func yamlToJSON(contents []byte) ([]byte, error) {
+	var value any
+	err := yaml.Unmarshal(contents, &value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(normalizeYAML(value))
+}
+
+// normalizeYAML converts the values decoded by the YAML package into values that can be encoded as JSON.
+func normalizeYAML(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for k, v := range value {
+			value[k] = normalizeYAML(v)
+		}
+		return value
+	case map[any]any:
+		result := map[string]any{}
+		for k, v := range value {
+			result[fmt.Sprintf("%v", k)] = normalizeYAML(v)
+		}
+		return result
+	case []any:
+		for i, v := range value {
+			value[i] = normalizeYAML(v)
+		}
+		return value
+	case time.Time:
+		return value.Format(time.RFC3339)
+	}
+	return value
+}
+
+// parseSince parses a "since" time, which is either an RFC 3339 timestamp or a duration before now, such as "2h".
+func parseSince(value string) (firstdue.Timestamp, error) {
+	if value == "" {
+		return firstdue.Timestamp{}, nil
+	}
+	if duration, err := time.ParseDuration(strings.TrimPrefix(value, "-")); err == nil {
+		return firstdue.Timestamp(time.Now().Add(-duration)), nil
+	}
+	var timestamp firstdue.Timestamp
+	err := timestamp.UnmarshalText([]byte(value))
+	if err != nil {
+		return timestamp, fmt.Errorf("invalid time %q: expected an RFC 3339 time or a duration", value)
+	}
+	return timestamp, nil
+}
+
+// writeFileAtomic writes the file by writing a temporary file in the same directory and renaming it into place.
+func writeFileAtomic(path string, contents []byte, perm os.FileMode) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(contents)
+	if err == nil {
+		err = file.Chmod(perm)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
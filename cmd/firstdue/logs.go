@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+type LogsCommand struct {
+	Settings *LogsSettingsCommand `arg:"subcommand" help:"Show the log settings"`
+	Send     *LogsSendCommand     `arg:"subcommand" help:"Send a log message, or a batch of them from a file"`
+}
+
+type LogsSettingsCommand struct{}
+
+type LogsSendCommand struct {
+	File     string `arg:"--file" placeholder:"FILE" help:"JSON or YAML file with a list of log messages to send as a batch (- for stdin)"`
+	Message  string `arg:"--message" help:"Log message"`
+	Level    string `arg:"--level" default:"info" help:"Log level code"`
+	Category string `arg:"--category" help:"Log category"`
+}
+
+func runLogs(ctx context.Context, args Args, config *Config) error {
+	switch {
+	case args.Logs.Settings != nil:
+		client, err := newClient(ctx, args, config)
+		if err != nil {
+			return err
+		}
+		output, err := client.GetLogsSettings(ctx, firstdue.GetLogsSettingsRequest{})
+		if err != nil {
+			return fmt.Errorf("error getting log settings: %w", err)
+		}
+		return printOutput(args, output)
+	case args.Logs.Send != nil:
+		if args.Logs.Send.File != "" {
+			var input firstdue.PostLogsBatchRequest
+			err := readInputFile(args.Logs.Send.File, &input)
+			if err != nil {
+				return err
+			}
+			client, err := newClient(ctx, args, config)
+			if err != nil {
+				return err
+			}
+			err = client.PostLogsBatch(ctx, input)
+			if err != nil {
+				return fmt.Errorf("error sending logs: %w", err)
+			}
+			return nil
+		}
+		if args.Logs.Send.Message == "" {
+			return fmt.Errorf("either --file or --message is required")
+		}
+		client, err := newClient(ctx, args, config)
+		if err != nil {
+			return err
+		}
+		err = client.PostLogs(ctx, firstdue.PostLogsRequest{
+			Message:   args.Logs.Send.Message,
+			LevelCode: args.Logs.Send.Level,
+			Category:  args.Logs.Send.Category,
+		})
+		if err != nil {
+			return fmt.Errorf("error sending log: %w", err)
+		}
+		return nil
+	default:
+		return errUsage
+	}
+}
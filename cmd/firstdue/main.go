@@ -2,62 +2,46 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
 
 	"github.com/alexflint/go-arg"
-	"github.com/kirsle/configdir"
-	"github.com/tekkamanendless/firstdue"
 )
 
 type Args struct {
-	Debug bool `arg:"--debug,env:DEBUG" help:"Enable debug mode"`
-
-	Config *ConfigCommand `arg:"subcommand" help:"Configuration commands"`
-	API    *APICommand    `arg:"subcommand" help:"API commands"`
-}
-
-type ConfigCommand struct {
-	Activate  *ConfigActivateCommand  `arg:"subcommand" help:"Activate a configuration"`
-	Configure *ConfigConfigureCommand `arg:"subcommand" help:"Configure a new configuration"`
-	List      *ConfigListCommand      `arg:"subcommand" help:"List all configurations"`
-}
-
-type ConfigActivateCommand struct {
-	Name string `arg:"positional,required" help:"Name of the configuration"`
-}
-
-type ConfigConfigureCommand struct {
-	Name     string `arg:"positional,required" help:"Name of the configuration"`
-	BaseURL  string `arg:"--base-url" help:"Base URL for the configuration"`
-	Username string `arg:"--username" help:"Username for the configuration"`
-	Password string `arg:"--password" help:"Password for the configuration"`
-}
-
-type ConfigListCommand struct{}
-
-type APICommand struct {
-	Endpoint string `arg:"--endpoint,required" help:"API endpoint to call"`
-	Method   string `arg:"--method" default:"GET" help:"HTTP method to use"`
-	Body     string `arg:"--body" help:"Request body for POST/PUT methods"`
-}
-
-type Config struct {
-	DefaultAccount string             `json:"defaultAccount"`
-	AccountMap     map[string]Account `json:"accountMap"`
-}
-
-type Account struct {
-	BaseURL  string `json:"baseURL"`
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Debug   bool   `arg:"--debug,env:DEBUG" help:"Enable debug mode"`
+	Profile string `arg:"--profile,env:FIRSTDUE_PROFILE" placeholder:"PROFILE" help:"Configuration to use instead of the active one"`
+	OutputOptions
+
+	Config        *ConfigCommand        `arg:"subcommand" help:"Configuration commands"`
+	Auth          *AuthCommand          `arg:"subcommand" help:"Authentication commands"`
+	API           *APICommand           `arg:"subcommand" help:"API commands"`
+	Dispatches    *DispatchesCommand    `arg:"subcommand" help:"Dispatch commands"`
+	Stations      *StationsCommand      `arg:"subcommand" help:"Station commands"`
+	Apparatuses   *ApparatusesCommand   `arg:"subcommand" help:"Apparatus commands"`
+	Logs          *LogsCommand          `arg:"subcommand" help:"Log commands"`
+	Notifications *NotificationsCommand `arg:"subcommand" help:"NFIRS notification commands"`
+	Doctor        *DoctorCommand        `arg:"subcommand" help:"Diagnose configuration and connectivity problems"`
+	Completion    *CompletionCommand    `arg:"subcommand" help:"Generate a shell completion script"`
+	Mock          *MockCommand          `arg:"subcommand" help:"Mock First Due API commands"`
+	Bridge        *BridgeCommand        `arg:"subcommand" help:"CAD bridge commands"`
+	Mapping       *MappingCommand       `arg:"subcommand" help:"CAD field mapping commands"`
+	CADText       *CADTextCommand       `arg:"subcommand:cadtext" help:"CAD text page commands"`
+	CADXML        *CADXMLCommand        `arg:"subcommand:cadxml" help:"APCO/NENA-style CAD XML commands"`
 }
 
 func main() {
 	ctx := context.Background()
 
+	if len(os.Args) > 2 && os.Args[1] == completeCommandName {
+		err := runComplete(os.Stdout, os.Args[2], os.Args[3:])
+		if err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
 	var args Args
 	argsParser, err := arg.NewParser(arg.Config{}, &args)
 	if err != nil {
@@ -79,116 +63,56 @@ func main() {
 		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
 	}
 
-	configDirectory := configdir.LocalConfig("firstdue")
-	err = configdir.MakePath(configDirectory)
+	config, err := loadConfig()
 	if err != nil {
-		fmt.Printf("error creating config directory: %v\n", err)
+		fmt.Printf("error: %v\n", err)
 		os.Exit(1)
 	}
-	var config Config
-	{
-		configFilePath := configDirectory + string(os.PathSeparator) + "config.json"
-		contents, err := os.ReadFile(configFilePath)
-		if err != nil {
-			if !os.IsNotExist(err) {
-				fmt.Printf("error reading config file: %v\n", err)
-				os.Exit(1)
-			}
-		} else {
-			err = json.Unmarshal(contents, &config)
-			if err != nil {
-				fmt.Printf("error parsing config file: %v\n", err)
-			}
-		}
-
-		if config.AccountMap == nil {
-			config.AccountMap = make(map[string]Account)
-		}
+	// The commands that never use the credentials are left alone, and "config migrate" does the migration itself.
+	if args.Completion == nil && args.Mock == nil && (args.Config == nil || args.Config.Migrate == nil) {
+		autoMigratePasswords(config)
 	}
 
 	switch {
 	case args.Config != nil:
-		switch {
-		case args.Config.Activate != nil:
-			_, exists := config.AccountMap[args.Config.Activate.Name]
-			if !exists {
-				fmt.Printf("configuration '%s' does not exist\n", args.Config.Activate.Name)
-				os.Exit(1)
-			}
-			config.DefaultAccount = args.Config.Activate.Name
-			configFilePath := configDirectory + string(os.PathSeparator) + "config.json"
-			contents, err := json.MarshalIndent(config, "", "  ")
-			if err != nil {
-				fmt.Printf("error serializing config file: %v\n", err)
-				os.Exit(1)
-			}
-			err = os.WriteFile(configFilePath, contents, 0600)
-			if err != nil {
-				fmt.Printf("error writing config file: %v\n", err)
-				os.Exit(1)
-			}
-		case args.Config.Configure != nil:
-			config.AccountMap[args.Config.Configure.Name] = Account{
-				BaseURL:  args.Config.Configure.BaseURL,
-				Username: args.Config.Configure.Username,
-				Password: args.Config.Configure.Password,
-			}
-			configFilePath := configDirectory + string(os.PathSeparator) + "config.json"
-			contents, err := json.MarshalIndent(config, "", "  ")
-			if err != nil {
-				fmt.Printf("error serializing config file: %v\n", err)
-				os.Exit(1)
-			}
-			err = os.WriteFile(configFilePath, contents, 0600)
-			if err != nil {
-				fmt.Printf("error writing config file: %v\n", err)
-				os.Exit(1)
-			}
-		case args.Config.List != nil:
-			for name := range config.AccountMap {
-				fmt.Println(name)
-			}
-		default:
-			argsParser.WriteHelp(os.Stdout)
-			os.Exit(1)
-		}
+		err = runConfig(ctx, args, config)
+	case args.Auth != nil:
+		err = runAuth(ctx, args, config)
 	case args.API != nil:
-		slog.InfoContext(ctx, "For a list of API endpoints, see https://support.firstduesizeup.com/portal/en/kb/articles/first-due-rest-api-documentation")
-
-		var options []firstdue.ClientOption
-		if args.Debug {
-			options = append(options, firstdue.WithDebug(true))
-		}
-		if config.AccountMap[config.DefaultAccount].BaseURL != "" {
-			options = append(options, firstdue.WithBaseURL(config.AccountMap[config.DefaultAccount].BaseURL))
-		}
-		client := firstdue.NewClient(options...)
-		err = client.Authenticate(ctx, config.AccountMap[config.DefaultAccount].Username, config.AccountMap[config.DefaultAccount].Password)
-		if err != nil {
-			fmt.Printf("error authenticating: %v\n", err)
-			os.Exit(1)
-		}
-
-		var jsonRequest json.RawMessage
-		if args.API.Body != "" {
-			jsonRequest = json.RawMessage(args.API.Body)
-		}
-		var jsonResponse json.RawMessage
-		err = client.Raw(ctx, args.API.Method, args.API.Endpoint, jsonRequest, &jsonResponse)
-		if err != nil {
-			fmt.Printf("error making API request: %v\n", err)
-			os.Exit(1)
-		}
-
-		var prettyJSON []byte
-		prettyJSON, err = json.MarshalIndent(jsonResponse, "", "  ")
-		if err != nil {
-			fmt.Printf("error formatting JSON response: %v\n", err)
-			os.Exit(1)
-		}
-		fmt.Println(string(prettyJSON))
+		err = runAPI(ctx, args, config)
+	case args.Dispatches != nil:
+		err = runDispatches(ctx, args, config)
+	case args.Stations != nil:
+		err = runStations(ctx, args, config)
+	case args.Apparatuses != nil:
+		err = runApparatuses(ctx, args, config)
+	case args.Logs != nil:
+		err = runLogs(ctx, args, config)
+	case args.Notifications != nil:
+		err = runNotifications(ctx, args, config)
+	case args.Doctor != nil:
+		err = runDoctor(ctx, args, config)
+	case args.Completion != nil:
+		err = runCompletion(ctx, args, config)
+	case args.Mock != nil:
+		err = runMock(ctx, args, config)
+	case args.Bridge != nil:
+		err = runBridge(ctx, args, config)
+	case args.Mapping != nil:
+		err = runMapping(ctx, args, config)
+	case args.CADText != nil:
+		err = runCADText(ctx, args, config)
+	case args.CADXML != nil:
+		err = runCADXML(ctx, args, config)
 	default:
-		argsParser.WriteHelp(os.Stdout)
+		err = errUsage
+	}
+	if err == errUsage {
+		argsParser.WriteHelpForSubcommand(os.Stdout, argsParser.SubcommandNames()...)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
 		os.Exit(1)
 	}
 }
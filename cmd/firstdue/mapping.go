@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tekkamanendless/firstdue/mapping"
+)
+
+type MappingCommand struct {
+	Test *MappingTestCommand `arg:"subcommand" help:"Run a sample payload through a mapping and show the notification it produces"`
+}
+
+type MappingTestCommand struct {
+	Mapping string `arg:"--mapping,required" placeholder:"FILE" help:"YAML or JSON mapping file"`
+	Payload string `arg:"positional,required" placeholder:"FILE" help:"JSON or XML payload file (- for stdin)"`
+}
+
+func runMapping(ctx context.Context, args Args, config *Config) error {
+	switch {
+	case args.Mapping.Test != nil:
+		command := args.Mapping.Test
+
+		m, err := mapping.Load(command.Mapping)
+		if err != nil {
+			return err
+		}
+
+		var payload []byte
+		if command.Payload == "-" {
+			payload, err = io.ReadAll(os.Stdin)
+		} else {
+			payload, err = os.ReadFile(command.Payload)
+		}
+		if err != nil {
+			return fmt.Errorf("error reading payload: %w", err)
+		}
+
+		record, applyErr := m.Apply(payload)
+		err = printOutput(args, record)
+		if err != nil {
+			return err
+		}
+		if applyErr != nil {
+			lines := mapping.Problems(applyErr)
+			for _, line := range lines {
+				fmt.Fprintf(os.Stderr, "validation error: %s\n", line)
+			}
+			return fmt.Errorf("the payload has %d validation errors", len(lines))
+		}
+		return nil
+	default:
+		return errUsage
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/tekkamanendless/firstdue/mock"
+)
+
+type MockCommand struct {
+	Serve *MockServeCommand `arg:"subcommand" help:"Run a fake First Due API for local development"`
+}
+
+type MockServeCommand struct {
+	Host             string        `arg:"--host" default:"127.0.0.1" help:"Address to listen on"`
+	Port             int           `arg:"--port" default:"8080" help:"Port to listen on"`
+	Seed             string        `arg:"--seed" placeholder:"FILE" help:"JSON file with the stations, apparatuses, dispatches, and notifications to start with (defaults to a small built-in department)"`
+	DispatchInterval time.Duration `arg:"--dispatch-interval" help:"Generate a synthetic dispatch this often (such as 30s); 0 disables it"`
+	RandomSeed       int64         `arg:"--random-seed" help:"Seed for the synthetic dispatches, for repeatable runs"`
+}
+
+func runMock(ctx context.Context, args Args, config *Config) error {
+	switch {
+	case args.Mock.Serve != nil:
+		command := args.Mock.Serve
+		fixtures := mock.DefaultFixtures()
+		if command.Seed != "" {
+			var err error
+			fixtures, err = mock.LoadFixtures(command.Seed)
+			if err != nil {
+				return err
+			}
+		}
+		server := mock.NewServer(mock.Config{
+			Fixtures: fixtures,
+			Seed:     command.RandomSeed,
+		})
+
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		listener, err := net.Listen("tcp", net.JoinHostPort(command.Host, strconv.Itoa(command.Port)))
+		if err != nil {
+			return fmt.Errorf("error listening: %w", err)
+		}
+		httpServer := &http.Server{Handler: server}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			httpServer.Shutdown(shutdownCtx)
+		}()
+		if command.DispatchInterval > 0 {
+			go server.GenerateDispatches(ctx, command.DispatchInterval)
+		}
+
+		baseURL := "http://" + listener.Addr().String()
+		fmt.Fprintf(os.Stderr, "The mock First Due API is listening on %s; press Ctrl-C to stop.\n", baseURL)
+		fmt.Fprintf(os.Stderr, "Any credentials are accepted, for example:\n")
+		fmt.Fprintf(os.Stderr, "    export %s=%s %s=dev@example.com %s=any\n", baseURLEnvironmentVariable, baseURL, usernameEnvironmentVariable, passwordEnvironmentVariable)
+		fmt.Fprintf(os.Stderr, "The admin API is at %s/_admin/ (notifications, logs, faults, dispatches, reset).\n", baseURL)
+
+		err = httpServer.Serve(listener)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("error serving: %w", err)
+		}
+		return nil
+	default:
+		return errUsage
+	}
+}
@@ -0,0 +1,564 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+type NotificationsImportCommand struct {
+	File        string `arg:"--file,required" placeholder:"FILE" help:"CSV or NDJSON file to import (- for stdin)"`
+	Format      string `arg:"--format" choices:"csv,ndjson" help:"Input format: csv or ndjson (defaults to the file extension)"`
+	Mapping     string `arg:"--mapping" placeholder:"FILE" help:"JSON or YAML file that maps CSV columns to fields (defaults to columns named after the fields)"`
+	Concurrency int    `arg:"--concurrency" default:"4" help:"Number of notifications to send at once"`
+	Report      string `arg:"--report" placeholder:"FILE" help:"File to write the per-row results to, in the --output format (defaults to standard output)"`
+	DryRun      bool   `arg:"--dry-run" help:"Only validate the input"`
+}
+
+// importMapping maps CSV columns to the JSON fields of a notification and its apparatus.
+//
+// Each CSV row may hold a notification, an apparatus, or both; rows are grouped by dispatch number, and the
+// notification fields are taken from the first row of each group.
+type importMapping struct {
+	TimeFormat   string            `json:"time_format"`  // A Go time layout; defaults to RFC 3339.
+	TimeZone     string            `json:"time_zone"`    // The time zone of times without an offset; defaults to the local time zone.
+	Notification map[string]string `json:"notification"` // Maps a notification field to a column name.
+	Apparatus    map[string]string `json:"apparatus"`    // Maps an apparatus field to a column name.
+}
+
+// Import results.
+const (
+	importResultValid   = "valid"
+	importResultInvalid = "invalid"
+	importResultFailed  = "failed"
+	importResultSkipped = "skipped"
+)
+
+// importResult is the outcome of a single input row.
+type importResult struct {
+	Row            int    `json:"row"`
+	DispatchNumber string `json:"dispatch_number"`
+	Result         string `json:"result"`
+	Error          string `json:"error,omitempty"`
+}
+
+// importRow is a single parsed row of the input.
+type importRow struct {
+	line           int
+	dispatchNumber string
+	notification   *firstdue.NfirsNotification
+	apparatuses    []firstdue.NfirsNotificationApparatus
+	err            error
+}
+
+// importGroup is all of the rows for a single dispatch number.
+type importGroup struct {
+	record firstdue.NfirsNotificationRecord
+	rows   []int // Indexes into the rows.
+	err    error
+}
+
+func runNotificationsImport(ctx context.Context, args Args, config *Config) error {
+	command := args.Notifications.Import
+	if command.Concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	format := strings.ToLower(command.Format)
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(command.File)) {
+		case ".csv":
+			format = "csv"
+		case ".ndjson", ".jsonl", ".json":
+			format = "ndjson"
+		default:
+			return fmt.Errorf("cannot tell the format of %q; use --format", command.File)
+		}
+	}
+
+	var input io.Reader
+	if command.File == "-" {
+		input = os.Stdin
+	} else {
+		file, err := os.Open(command.File)
+		if err != nil {
+			return fmt.Errorf("error reading input file: %w", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var rows []importRow
+	var err error
+	switch format {
+	case "csv":
+		var mapping *importMapping
+		if command.Mapping != "" {
+			mapping = &importMapping{}
+			err = readInputFile(command.Mapping, mapping)
+			if err != nil {
+				return err
+			}
+		}
+		rows, err = readImportCSV(input, mapping)
+	case "ndjson":
+		if command.Mapping != "" {
+			return fmt.Errorf("--mapping only applies to CSV input")
+		}
+		rows, err = readImportNDJSON(input)
+	default:
+		return fmt.Errorf("unsupported format: %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("the input file has no rows")
+	}
+
+	// Validate everything before sending anything.
+	groups := groupImportRows(rows)
+	results := make([]importResult, len(rows))
+	invalid := 0
+	for _, group := range groups {
+		for _, index := range group.rows {
+			row := rows[index]
+			results[index] = importResult{
+				Row:            row.line,
+				DispatchNumber: row.dispatchNumber,
+				Result:         importResultValid,
+			}
+			switch {
+			case row.err != nil:
+				results[index].Result = importResultInvalid
+				results[index].Error = row.err.Error()
+			case group.err != nil:
+				results[index].Result = importResultInvalid
+				results[index].Error = group.err.Error()
+			}
+			if results[index].Result == importResultInvalid {
+				invalid++
+			}
+		}
+	}
+	if invalid > 0 {
+		for i := range results {
+			if results[i].Result == importResultValid {
+				results[i].Result = importResultSkipped
+			}
+		}
+		err := writeImportReport(args, command.Report, results)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("%d of %d rows are invalid; nothing was imported", invalid, len(rows))
+	}
+	if command.DryRun {
+		return writeImportReport(args, command.Report, results)
+	}
+
+	client, err := newClient(ctx, args, config)
+	if err != nil {
+		return err
+	}
+
+	var waitGroup sync.WaitGroup
+	semaphore := make(chan struct{}, command.Concurrency)
+	for _, group := range groups {
+		waitGroup.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer waitGroup.Done()
+			defer func() { <-semaphore }()
+
+			result, err := client.UpsertNfirsNotification(ctx, group.record)
+			for _, index := range group.rows {
+				if err != nil {
+					results[index].Result = importResultFailed
+					results[index].Error = err.Error()
+				} else {
+					results[index].Result = result
+				}
+			}
+		}()
+	}
+	waitGroup.Wait()
+
+	err = writeImportReport(args, command.Report, results)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, result := range results {
+		if result.Result == importResultFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d rows failed to import", failed, len(rows))
+	}
+	return nil
+}
+
+// writeImportReport writes the results to the report file, or to standard output if there is no report file.
+func writeImportReport(args Args, path string, results []importResult) error {
+	if path == "" {
+		return printOutput(args, results)
+	}
+	var buffer bytes.Buffer
+	err := writeOutput(&buffer, args.OutputOptions, results)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(path, buffer.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing report: %w", err)
+	}
+	return nil
+}
+
+// groupImportRows groups the rows by dispatch number, in the order in which each dispatch number first appears,
+// and checks that each group makes sense.
+func groupImportRows(rows []importRow) []*importGroup {
+	var groups []*importGroup
+	groupsByNumber := map[string]*importGroup{}
+	for index, row := range rows {
+		key := strings.TrimSpace(row.dispatchNumber)
+		group := groupsByNumber[key]
+		if group == nil || key == "" {
+			group = &importGroup{}
+			groups = append(groups, group)
+			if key != "" {
+				groupsByNumber[key] = group
+			}
+		}
+		group.rows = append(group.rows, index)
+		if row.err != nil {
+			continue
+		}
+		if row.notification != nil && group.record.Notification.DispatchNumber == "" {
+			group.record.Notification = *row.notification
+		}
+		group.record.Apparatuses = append(group.record.Apparatuses, row.apparatuses...)
+	}
+
+	for _, group := range groups {
+		switch {
+		case group.record.Notification.DispatchNumber == "":
+			group.err = fmt.Errorf("there is no notification with this dispatch number")
+		case time.Time(group.record.Notification.AlarmAt).IsZero():
+			group.err = fmt.Errorf("alarm_at is required")
+		}
+		seen := map[string]bool{}
+		for _, apparatus := range group.record.Apparatuses {
+			unitCode := strings.ToUpper(strings.TrimSpace(apparatus.UnitCode))
+			if seen[unitCode] {
+				group.err = fmt.Errorf("apparatus %s appears more than once", apparatus.UnitCode)
+			}
+			seen[unitCode] = true
+		}
+	}
+	return groups
+}
+
+// readImportNDJSON reads one JSON object per line.
+//
+// A line is either a notification record (with "notification" and "apparatuses" keys), a notification, or an
+// apparatus (with a "unit_code" key) along with the "dispatch_number" of its notification.
+func readImportNDJSON(reader io.Reader) ([]importRow, error) {
+	var rows []importRow
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		contents := bytes.TrimSpace(scanner.Bytes())
+		if len(contents) == 0 {
+			continue
+		}
+		row := importRow{line: line}
+		row.err = parseImportNDJSONLine(contents, &row)
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading input file: %w", err)
+	}
+	return rows, nil
+}
+
+func parseImportNDJSONLine(contents []byte, row *importRow) error {
+	var keys map[string]json.RawMessage
+	err := json.Unmarshal(contents, &keys)
+	if err != nil {
+		return err
+	}
+	// Find the dispatch number first so that it can be reported even if the line is invalid.
+	var numbers struct {
+		DispatchNumber string `json:"dispatch_number"`
+		Notification   struct {
+			DispatchNumber string `json:"dispatch_number"`
+		} `json:"notification"`
+	}
+	if json.Unmarshal(contents, &numbers) == nil {
+		row.dispatchNumber = numbers.DispatchNumber + numbers.Notification.DispatchNumber
+	}
+	strict := func(target any) error {
+		decoder := json.NewDecoder(bytes.NewReader(contents))
+		decoder.DisallowUnknownFields()
+		return decoder.Decode(target)
+	}
+	switch {
+	case keys["notification"] != nil:
+		var record firstdue.NfirsNotificationRecord
+		err := strict(&record)
+		if err != nil {
+			return err
+		}
+		row.dispatchNumber = record.Notification.DispatchNumber
+		row.notification = &record.Notification
+		row.apparatuses = record.Apparatuses
+	case keys["unit_code"] != nil:
+		var apparatus struct {
+			DispatchNumber string `json:"dispatch_number"`
+			firstdue.NfirsNotificationApparatus
+		}
+		err := strict(&apparatus)
+		if err != nil {
+			return err
+		}
+		row.dispatchNumber = apparatus.DispatchNumber
+		row.apparatuses = []firstdue.NfirsNotificationApparatus{apparatus.NfirsNotificationApparatus}
+	default:
+		var notification firstdue.NfirsNotification
+		err := strict(&notification)
+		if err != nil {
+			return err
+		}
+		row.dispatchNumber = notification.DispatchNumber
+		row.notification = &notification
+	}
+	return validateImportRow(row)
+}
+
+// validateImportRow checks the fields that every row needs.
+func validateImportRow(row *importRow) error {
+	if strings.TrimSpace(row.dispatchNumber) == "" {
+		return fmt.Errorf("dispatch_number is required")
+	}
+	for _, apparatus := range row.apparatuses {
+		if strings.TrimSpace(apparatus.UnitCode) == "" {
+			return fmt.Errorf("unit_code is required for an apparatus")
+		}
+	}
+	return nil
+}
+
+// readImportCSV reads a CSV file with a header row.
+//
+// If there is no mapping, each column whose header is the JSON name of a notification or apparatus field is used.
+func readImportCSV(reader io.Reader, mapping *importMapping) ([]importRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+
+	notificationFields := jsonFieldTypes(reflect.TypeOf(firstdue.NfirsNotification{}))
+	apparatusFields := jsonFieldTypes(reflect.TypeOf(firstdue.NfirsNotificationApparatus{}))
+	if mapping == nil {
+		mapping = &importMapping{
+			Notification: map[string]string{},
+			Apparatus:    map[string]string{},
+		}
+		for name := range columns {
+			if _, ok := notificationFields[name]; ok {
+				mapping.Notification[name] = name
+			}
+			if _, ok := apparatusFields[name]; ok {
+				mapping.Apparatus[name] = name
+			}
+		}
+	}
+	for _, fields := range []struct {
+		mapping map[string]string
+		types   map[string]reflect.Type
+	}{
+		{mapping.Notification, notificationFields},
+		{mapping.Apparatus, apparatusFields},
+	} {
+		for field, column := range fields.mapping {
+			if _, ok := fields.types[field]; !ok {
+				return nil, fmt.Errorf("invalid mapping: unknown field %q", field)
+			}
+			if _, ok := columns[column]; !ok {
+				return nil, fmt.Errorf("invalid mapping: the CSV file has no column %q", column)
+			}
+		}
+	}
+	if _, ok := mapping.Notification["dispatch_number"]; !ok {
+		return nil, fmt.Errorf("invalid mapping: no column is mapped to dispatch_number")
+	}
+
+	location := time.Local
+	if mapping.TimeZone != "" {
+		location, err = time.LoadLocation(mapping.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mapping: %w", err)
+		}
+	}
+	timeFormat := mapping.TimeFormat
+	if timeFormat == "" {
+		timeFormat = time.RFC3339
+	}
+	converter := importConverter{timeFormat: timeFormat, location: location}
+
+	var rows []importRow
+	line := 1
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV row %d: %w", line, err)
+		}
+		cell := func(column string) string {
+			index := columns[column]
+			if index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+
+		row := importRow{line: line}
+		row.dispatchNumber = cell(mapping.Notification["dispatch_number"])
+
+		values, err := converter.values(mapping.Notification, notificationFields, cell)
+		if err == nil && len(values) > 1 {
+			var notification firstdue.NfirsNotification
+			err = converter.decode(values, &notification)
+			row.notification = &notification
+		}
+		if err == nil {
+			values, err = converter.values(mapping.Apparatus, apparatusFields, cell)
+			if err == nil && len(values) > 0 {
+				var apparatus firstdue.NfirsNotificationApparatus
+				err = converter.decode(values, &apparatus)
+				row.apparatuses = append(row.apparatuses, apparatus)
+			}
+		}
+		if err == nil {
+			err = validateImportRow(&row)
+		}
+		row.err = err
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// jsonFieldTypes returns the type of each field of a struct by its JSON name.
+func jsonFieldTypes(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// importConverter converts CSV cells into JSON values of the right type.
+type importConverter struct {
+	timeFormat string
+	location   *time.Location
+}
+
+// values returns the JSON values of the mapped fields that have a non-empty cell.
+func (c importConverter) values(mapping map[string]string, types map[string]reflect.Type, cell func(string) string) (map[string]any, error) {
+	values := map[string]any{}
+	for field, column := range mapping {
+		text := cell(column)
+		if text == "" {
+			continue
+		}
+		value, err := c.convert(text, types[field])
+		if err != nil {
+			return nil, fmt.Errorf("%s (column %q): %w", field, column, err)
+		}
+		values[field] = value
+	}
+	return values, nil
+}
+
+var (
+	timestampType  = reflect.TypeOf(firstdue.Timestamp{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+func (c importConverter) convert(text string, t reflect.Type) (any, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timestampType:
+		value, err := time.ParseInLocation(c.timeFormat, text, c.location)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q: expected the format %q", text, c.timeFormat)
+		}
+		return firstdue.Timestamp(value), nil
+	case t == rawMessageType:
+		if json.Valid([]byte(text)) {
+			return json.RawMessage(text), nil
+		}
+		return text, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return text, nil
+	case reflect.Bool:
+		return strconv.ParseBool(text)
+	case reflect.Int, reflect.Int64, reflect.Uint64:
+		return strconv.ParseInt(text, 10, 64)
+	case reflect.Float64:
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, err
+		}
+		if t == reflect.TypeOf(firstdue.StringFloat64(0)) {
+			return firstdue.StringFloat64(value), nil
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("unsupported field type %s", t)
+}
+
+// decode populates the target from the values.
+func (c importConverter) decode(values map[string]any, target any) error {
+	contents, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(contents, target)
+}
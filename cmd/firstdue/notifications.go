@@ -0,0 +1,397 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+type NotificationsCommand struct {
+	Get       *NotificationsGetCommand       `arg:"subcommand" help:"Get an NFIRS notification"`
+	Create    *NotificationsCreateCommand    `arg:"subcommand" help:"Create an NFIRS notification"`
+	Update    *NotificationsUpdateCommand    `arg:"subcommand" help:"Update an NFIRS notification"`
+	Delete    *NotificationsDeleteCommand    `arg:"subcommand" help:"Delete an NFIRS notification"`
+	Apparatus *NotificationsApparatusCommand `arg:"subcommand" help:"NFIRS notification apparatus commands"`
+	Import    *NotificationsImportCommand    `arg:"subcommand" help:"Create or update NFIRS notifications in bulk from a CSV or NDJSON file"`
+}
+
+type NotificationsGetCommand struct {
+	ID             uint64 `arg:"--id" help:"ID of the notification"`
+	DispatchNumber string `arg:"--dispatch-number" help:"Dispatch number of the notification"`
+}
+
+type NotificationsCreateCommand struct {
+	File string `arg:"--file" placeholder:"FILE" help:"JSON or YAML file with the notification (- for stdin); flags override its values"`
+	NotificationFields
+}
+
+type NotificationsUpdateCommand struct {
+	ID     uint64 `arg:"--id" help:"ID of the notification"`
+	Number string `arg:"--number" help:"Number of the notification"`
+	File   string `arg:"--file" placeholder:"FILE" help:"JSON or YAML file with the notification (- for stdin); flags override its values"`
+	NotificationFields
+}
+
+type NotificationsDeleteCommand struct {
+	ID     uint64 `arg:"--id" help:"ID of the notification"`
+	Number string `arg:"--number" help:"Number of the notification"`
+}
+
+// NotificationFields are the flags that set the fields of a notification.
+//
+// Only the flags that are given are applied.
+type NotificationFields struct {
+	DispatchNumber     *string             `arg:"--dispatch-number" help:"Dispatch number"`
+	IncidentNumber     *string             `arg:"--incident-number" help:"Incident number"`
+	DispatchType       *string             `arg:"--dispatch-type" help:"Dispatch type"`
+	IncidentTypeCode   *string             `arg:"--incident-type-code" help:"Dispatch incident type code"`
+	AlarmAt            *firstdue.Timestamp `arg:"--alarm-at" help:"Alarm time (RFC 3339)"`
+	DispatchNotifiedAt *firstdue.Timestamp `arg:"--dispatch-notified-at" help:"Dispatch notified time (RFC 3339)"`
+	CallCompletedAt    *firstdue.Timestamp `arg:"--call-completed-at" help:"Call completed time (RFC 3339)"`
+	Alarms             *int                `arg:"--alarms" help:"Number of alarms"`
+	Address            *string             `arg:"--address" help:"Address"`
+	CrossStreets       *string             `arg:"--cross-streets" help:"Cross streets"`
+	City               *string             `arg:"--city" help:"City"`
+	StateCode          *string             `arg:"--state" help:"State code"`
+	ZipCode            *string             `arg:"--zip" help:"ZIP code"`
+	Latitude           *float64            `arg:"--latitude" help:"Latitude"`
+	Longitude          *float64            `arg:"--longitude" help:"Longitude"`
+	Narratives         *string             `arg:"--narratives" help:"Narratives"`
+	Station            *string             `arg:"--station" help:"Station"`
+	ShiftName          *string             `arg:"--shift" help:"Shift name"`
+}
+
+func (f NotificationFields) apply(notification *firstdue.NfirsNotification) {
+	if f.DispatchNumber != nil {
+		notification.DispatchNumber = *f.DispatchNumber
+	}
+	if f.IncidentNumber != nil {
+		notification.IncidentNumber = *f.IncidentNumber
+	}
+	if f.DispatchType != nil {
+		notification.DispatchType = *f.DispatchType
+	}
+	if f.IncidentTypeCode != nil {
+		notification.DispatchIncidentTypeCode = *f.IncidentTypeCode
+	}
+	if f.AlarmAt != nil {
+		notification.AlarmAt = *f.AlarmAt
+	}
+	if f.DispatchNotifiedAt != nil {
+		notification.DispatchNotifiedAt = *f.DispatchNotifiedAt
+	}
+	if f.CallCompletedAt != nil {
+		notification.CallCompletedAt = *f.CallCompletedAt
+	}
+	if f.Alarms != nil {
+		notification.Alarms = *f.Alarms
+	}
+	if f.Address != nil {
+		notification.Address = *f.Address
+	}
+	if f.CrossStreets != nil {
+		notification.CrossStreets = *f.CrossStreets
+	}
+	if f.City != nil {
+		notification.City = *f.City
+	}
+	if f.StateCode != nil {
+		notification.StateCode = *f.StateCode
+	}
+	if f.ZipCode != nil {
+		notification.ZipCode = f.ZipCode
+	}
+	if f.Latitude != nil {
+		latitude := firstdue.StringFloat64(*f.Latitude)
+		notification.Latitude = &latitude
+	}
+	if f.Longitude != nil {
+		longitude := firstdue.StringFloat64(*f.Longitude)
+		notification.Longitude = &longitude
+	}
+	if f.Narratives != nil {
+		notification.Narratives = f.Narratives
+	}
+	if f.Station != nil {
+		notification.Station = f.Station
+	}
+	if f.ShiftName != nil {
+		notification.ShiftName = f.ShiftName
+	}
+}
+
+type NotificationsApparatusCommand struct {
+	Add    *NotificationsApparatusAddCommand    `arg:"subcommand" help:"Add an apparatus to an NFIRS notification"`
+	Update *NotificationsApparatusUpdateCommand `arg:"subcommand" help:"Update an apparatus on an NFIRS notification"`
+	Remove *NotificationsApparatusRemoveCommand `arg:"subcommand" help:"Remove an apparatus from an NFIRS notification"`
+}
+
+type NotificationsApparatusAddCommand struct {
+	ID     uint64 `arg:"--id" help:"ID of the notification"`
+	Number string `arg:"--number" help:"Number of the notification"`
+	File   string `arg:"--file" placeholder:"FILE" help:"JSON or YAML file with the apparatus (- for stdin); flags override its values"`
+	ApparatusFields
+}
+
+type NotificationsApparatusUpdateCommand struct {
+	ID          uint64 `arg:"--id" help:"ID of the notification"`
+	ApparatusID uint64 `arg:"--apparatus-id" help:"ID of the apparatus (when using --id)"`
+	Number      string `arg:"--number" help:"Number of the notification; the apparatus is identified by --unit-code"`
+	File        string `arg:"--file" placeholder:"FILE" help:"JSON or YAML file with the apparatus (- for stdin); flags override its values"`
+	ApparatusFields
+}
+
+type NotificationsApparatusRemoveCommand struct {
+	ID          uint64 `arg:"--id" help:"ID of the notification"`
+	ApparatusID uint64 `arg:"--apparatus-id" help:"ID of the apparatus (when using --id)"`
+	Number      string `arg:"--number" help:"Number of the notification"`
+	UnitCode    string `arg:"--unit-code" help:"Unit code of the apparatus (when using --number)"`
+}
+
+// ApparatusFields are the flags that set the fields of a notification apparatus.
+//
+// Only the flags that are given are applied.
+type ApparatusFields struct {
+	UnitCode               *string             `arg:"--unit-code" help:"Unit code"`
+	IsAid                  *bool               `arg:"--is-aid" help:"The apparatus is from another department"`
+	DispatchAt             *firstdue.Timestamp `arg:"--dispatch-at" help:"Dispatch time (RFC 3339)"`
+	DispatchAcknowledgedAt *firstdue.Timestamp `arg:"--acknowledged-at" help:"Dispatch acknowledged time (RFC 3339)"`
+	EnrouteAt              *firstdue.Timestamp `arg:"--enroute-at" help:"En route time (RFC 3339)"`
+	ArriveAt               *firstdue.Timestamp `arg:"--arrive-at" help:"Arrival time (RFC 3339)"`
+	ClearAt                *firstdue.Timestamp `arg:"--clear-at" help:"Clear time (RFC 3339)"`
+	BackInServiceAt        *firstdue.Timestamp `arg:"--back-in-service-at" help:"Back in service time (RFC 3339)"`
+	CanceledAt             *firstdue.Timestamp `arg:"--canceled-at" help:"Canceled time (RFC 3339)"`
+	CanceledStageCode      *string             `arg:"--canceled-stage-code" help:"Canceled stage code"`
+}
+
+func (f ApparatusFields) apply(apparatus *firstdue.NfirsNotificationApparatus) {
+	if f.UnitCode != nil {
+		apparatus.UnitCode = *f.UnitCode
+	}
+	if f.IsAid != nil {
+		apparatus.IsAid = *f.IsAid
+	}
+	for _, field := range []struct {
+		source *firstdue.Timestamp
+		target *firstdue.Timestamp
+	}{
+		{f.DispatchAt, &apparatus.DispatchAt},
+		{f.DispatchAcknowledgedAt, &apparatus.DispatchAcknowledgedAt},
+		{f.EnrouteAt, &apparatus.EnrouteAt},
+		{f.ArriveAt, &apparatus.ArriveAt},
+		{f.ClearAt, &apparatus.ClearAt},
+		{f.BackInServiceAt, &apparatus.BackInServiceAt},
+		{f.CanceledAt, &apparatus.CanceledAt},
+	} {
+		if field.source != nil {
+			*field.target = *field.source
+		}
+	}
+	if f.CanceledStageCode != nil {
+		apparatus.CanceledStageCode = *f.CanceledStageCode
+	}
+}
+
+func runNotifications(ctx context.Context, args Args, config *Config) error {
+	switch {
+	case args.Notifications.Get != nil:
+		command := args.Notifications.Get
+		if (command.ID == 0) == (command.DispatchNumber == "") {
+			return fmt.Errorf("exactly one of --id or --dispatch-number is required")
+		}
+		client, err := newClient(ctx, args, config)
+		if err != nil {
+			return err
+		}
+		if command.ID != 0 {
+			output, err := client.GetNfirsNotificationsID(ctx, command.ID)
+			if err != nil {
+				return fmt.Errorf("error getting notification: %w", err)
+			}
+			return printOutput(args, output)
+		}
+		output, err := client.GetNfirsNotificationsDispatchNumberID(ctx, command.DispatchNumber, firstdue.GetNfirsNotificationsDispatchNumberIDRequest{})
+		if err != nil {
+			return fmt.Errorf("error getting notification: %w", err)
+		}
+		return printOutput(args, output)
+	case args.Notifications.Create != nil:
+		command := args.Notifications.Create
+		var notification firstdue.NfirsNotification
+		if command.File != "" {
+			err := readInputFile(command.File, &notification)
+			if err != nil {
+				return err
+			}
+		}
+		command.NotificationFields.apply(&notification)
+		client, err := newClient(ctx, args, config)
+		if err != nil {
+			return err
+		}
+		output, err := client.PostNfirsNotifications(ctx, firstdue.PostNfirsNotificationsRequest(notification))
+		if err != nil {
+			return fmt.Errorf("error creating notification: %w", err)
+		}
+		return printOutput(args, output)
+	case args.Notifications.Update != nil:
+		command := args.Notifications.Update
+		if (command.ID == 0) == (command.Number == "") {
+			return fmt.Errorf("exactly one of --id or --number is required")
+		}
+		client, err := newClient(ctx, args, config)
+		if err != nil {
+			return err
+		}
+		var notification firstdue.NfirsNotification
+		switch {
+		case command.File != "":
+			err := readInputFile(command.File, &notification)
+			if err != nil {
+				return err
+			}
+		case command.ID != 0:
+			// Start from the current notification so that only the given flags are changed.
+			output, err := client.GetNfirsNotificationsID(ctx, command.ID)
+			if err != nil {
+				return fmt.Errorf("error getting notification: %w", err)
+			}
+			notification = firstdue.NfirsNotification(output)
+		default:
+			return fmt.Errorf("--file is required when updating by --number")
+		}
+		command.NotificationFields.apply(&notification)
+		if command.ID != 0 {
+			err = client.PutNfirsNotificationsID(ctx, command.ID, firstdue.PutNfirsNotificationsIDRequest(notification))
+		} else {
+			err = client.PutNfirsNotificationsNumberID(ctx, command.Number, firstdue.PutNfirsNotificationsNumberIDRequest(notification))
+		}
+		if err != nil {
+			return fmt.Errorf("error updating notification: %w", err)
+		}
+		return nil
+	case args.Notifications.Delete != nil:
+		command := args.Notifications.Delete
+		if (command.ID == 0) == (command.Number == "") {
+			return fmt.Errorf("exactly one of --id or --number is required")
+		}
+		client, err := newClient(ctx, args, config)
+		if err != nil {
+			return err
+		}
+		if command.ID != 0 {
+			err = client.DeleteNfirsNotificationsID(ctx, command.ID)
+		} else {
+			err = client.DeleteNfirsNotificationsNumberID(ctx, command.Number)
+		}
+		if err != nil {
+			return fmt.Errorf("error deleting notification: %w", err)
+		}
+		return nil
+	case args.Notifications.Apparatus != nil:
+		return runNotificationsApparatus(ctx, args, config)
+	case args.Notifications.Import != nil:
+		return runNotificationsImport(ctx, args, config)
+	default:
+		return errUsage
+	}
+}
+
+func runNotificationsApparatus(ctx context.Context, args Args, config *Config) error {
+	switch {
+	case args.Notifications.Apparatus.Add != nil:
+		command := args.Notifications.Apparatus.Add
+		if (command.ID == 0) == (command.Number == "") {
+			return fmt.Errorf("exactly one of --id or --number is required")
+		}
+		var apparatus firstdue.NfirsNotificationApparatus
+		if command.File != "" {
+			err := readInputFile(command.File, &apparatus)
+			if err != nil {
+				return err
+			}
+		}
+		command.ApparatusFields.apply(&apparatus)
+		client, err := newClient(ctx, args, config)
+		if err != nil {
+			return err
+		}
+		if command.ID != 0 {
+			output, err := client.PostNfirsNotificationsIDApparatuses(ctx, command.ID, firstdue.PostNfirsNotificationsIDApparatusesRequest(apparatus))
+			if err != nil {
+				return fmt.Errorf("error adding apparatus: %w", err)
+			}
+			return printOutput(args, output)
+		}
+		err = client.PostNfirsNotificationsNumberIDApparatuses(ctx, command.Number, firstdue.PostNfirsNotificationsNumberIDApparatusesRequest(apparatus))
+		if err != nil {
+			return fmt.Errorf("error adding apparatus: %w", err)
+		}
+		return nil
+	case args.Notifications.Apparatus.Update != nil:
+		command := args.Notifications.Apparatus.Update
+		switch {
+		case command.ID != 0 && command.Number == "":
+			if command.ApparatusID == 0 {
+				return fmt.Errorf("--apparatus-id is required when using --id")
+			}
+		case command.ID == 0 && command.Number != "":
+			if command.UnitCode == nil {
+				return fmt.Errorf("--unit-code is required when using --number")
+			}
+		default:
+			return fmt.Errorf("exactly one of --id or --number is required")
+		}
+		var apparatus firstdue.NfirsNotificationApparatus
+		if command.File != "" {
+			err := readInputFile(command.File, &apparatus)
+			if err != nil {
+				return err
+			}
+		}
+		command.ApparatusFields.apply(&apparatus)
+		client, err := newClient(ctx, args, config)
+		if err != nil {
+			return err
+		}
+		if command.ID != 0 {
+			err = client.PutNfirsNotificationsIDApparatusesID(ctx, command.ID, command.ApparatusID, firstdue.PutNfirsNotificationsIDApparatusesIDRequest(apparatus))
+		} else {
+			err = client.PutNfirsNotificationsNumberIDApparatusesCodeID(ctx, command.Number, *command.UnitCode, firstdue.PutNfirsNotificationsNumberIDApparatusesCodeIDRequest(apparatus))
+		}
+		if err != nil {
+			return fmt.Errorf("error updating apparatus: %w", err)
+		}
+		return nil
+	case args.Notifications.Apparatus.Remove != nil:
+		command := args.Notifications.Apparatus.Remove
+		switch {
+		case command.ID != 0 && command.Number == "":
+			if command.ApparatusID == 0 {
+				return fmt.Errorf("--apparatus-id is required when using --id")
+			}
+		case command.ID == 0 && command.Number != "":
+			if command.UnitCode == "" {
+				return fmt.Errorf("--unit-code is required when using --number")
+			}
+		default:
+			return fmt.Errorf("exactly one of --id or --number is required")
+		}
+		client, err := newClient(ctx, args, config)
+		if err != nil {
+			return err
+		}
+		if command.ID != 0 {
+			err = client.DeleteNfirsNotificationsIDApparatusesID(ctx, command.ID, command.ApparatusID)
+		} else {
+			err = client.DeleteNfirsNotificationsNumberIDApparatusesCodeID(ctx, command.Number, command.UnitCode)
+		}
+		if err != nil {
+			return fmt.Errorf("error removing apparatus: %w", err)
+		}
+		return nil
+	default:
+		return errUsage
+	}
+}
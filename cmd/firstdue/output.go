@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/tekkamanendless/firstdue"
+	"github.com/tekkamanendless/firstdue/geo"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats.
+const (
+	OutputJSON     = "json"
+	OutputNDJSON   = "ndjson"
+	OutputYAML     = "yaml"
+	OutputTable    = "table"
+	OutputCSV      = "csv"
+	OutputTemplate = "template"
+	OutputGeoJSON  = "geojson"
+	OutputKML      = "kml"
+)
+
+// OutputOptions are the global flags that control how results are printed.
+type OutputOptions struct {
+	Output    string   `arg:"--output,-o,env:FIRSTDUE_OUTPUT" default:"json" choices:"json,ndjson,yaml,table,csv,template,geojson,kml" help:"Output format: json, ndjson, yaml, table, csv, template, or (for dispatches and notifications) geojson or kml"`
+	Columns   []string `arg:"--columns,separate" help:"Columns to include in table and CSV output (repeatable; defaults to all)"`
+	Template  string   `arg:"--template" help:"Go template to execute for each item when using \"--output template\"; fields use their JSON names, such as {{.unit_code}}"`
+	Unlocated bool     `arg:"--unlocated" help:"Keep the records without usable coordinates in geojson and kml output, flagged with \"location_status\", instead of skipping them"`
+}
+
+// orderedObject is a JSON object that remembers the order of its keys.
+type orderedObject struct {
+	keys   []string
+	values map[string]any
+}
+
+// MarshalJSON encodes the object with its keys in their original order.
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("{")
+	for i, key := range o.keys {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+		keyContents, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		valueContents, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(keyContents)
+		buffer.WriteString(":")
+		buffer.Write(valueContents)
+	}
+	buffer.WriteString("}")
+	return buffer.Bytes(), nil
+}
+
+// printOutput prints the value in the format selected by the global flags.
+//
+// The value is first converted to JSON so that every format uses the API's field names.  A list (or an object
+// with a "list" field, as the paged endpoints return) is printed one item per row.
+func printOutput(args Args, value any) error {
+	return writeOutput(os.Stdout, args.OutputOptions, value)
+}
+
+func writeOutput(w io.Writer, options OutputOptions, value any) error {
+	if options.Output == OutputGeoJSON || options.Output == OutputKML {
+		return writeGeoOutput(w, options, value)
+	}
+
+	contents, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error formatting response: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+	document, err := decodeOrdered(decoder)
+	if err != nil {
+		return fmt.Errorf("error formatting response: %w", err)
+	}
+
+	switch options.Output {
+	case OutputJSON, "":
+		var buffer bytes.Buffer
+		err := json.Indent(&buffer, contents, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error formatting JSON response: %w", err)
+		}
+		fmt.Fprintln(w, buffer.String())
+	case OutputNDJSON:
+		for _, row := range outputRows(document) {
+			contents, err := json.Marshal(row)
+			if err != nil {
+				return fmt.Errorf("error formatting JSON response: %w", err)
+			}
+			fmt.Fprintln(w, string(contents))
+		}
+	case OutputYAML:
+		contents, err := yaml.Marshal(toYAMLNode(document))
+		if err != nil {
+			return fmt.Errorf("error formatting YAML response: %w", err)
+		}
+		fmt.Fprint(w, string(contents))
+	case OutputTable, OutputCSV:
+		rows := outputRows(document)
+		columns := options.Columns
+		if len(columns) == 0 {
+			columns = outputColumns(rows)
+		}
+		if options.Output == OutputCSV {
+			writer := csv.NewWriter(w)
+			writer.Write(columns)
+			for _, row := range rows {
+				writer.Write(outputCells(row, columns))
+			}
+			writer.Flush()
+			return writer.Error()
+		}
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		var headers []string
+		for _, column := range columns {
+			headers = append(headers, strings.ToUpper(column))
+		}
+		fmt.Fprintln(writer, strings.Join(headers, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(writer, strings.Join(outputCells(row, columns), "\t"))
+		}
+		return writer.Flush()
+	case OutputTemplate:
+		if options.Template == "" {
+			return fmt.Errorf("--template is required when using \"--output template\"")
+		}
+		tmpl, err := template.New("output").Parse(options.Template)
+		if err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+		for _, row := range outputRows(document) {
+			var buffer bytes.Buffer
+			err := tmpl.Execute(&buffer, toPlain(row))
+			if err != nil {
+				return fmt.Errorf("error executing template: %w", err)
+			}
+			if !bytes.HasSuffix(buffer.Bytes(), []byte("\n")) {
+				buffer.WriteString("\n")
+			}
+			w.Write(buffer.Bytes())
+		}
+	default:
+		return fmt.Errorf("unsupported output format: %q", options.Output)
+	}
+	return nil
+}
+
+// writeGeoOutput prints dispatches or notifications as a GeoJSON feature collection or a KML document.
+func writeGeoOutput(w io.Writer, options OutputOptions, value any) error {
+	geoOptions := geo.Options{IncludeMissing: options.Unlocated}
+	var collection geo.FeatureCollection
+	var name string
+	switch value := value.(type) {
+	case firstdue.GetDispatchesResponse:
+		collection, name = geo.Dispatches(value, geoOptions), "Dispatches"
+	case []firstdue.GetDispatchesResponseDispatch:
+		collection, name = geo.Dispatches(value, geoOptions), "Dispatches"
+	case firstdue.GetDispatchesResponseDispatch:
+		collection, name = geo.Dispatches([]firstdue.GetDispatchesResponseDispatch{value}, geoOptions), "Dispatches"
+	case firstdue.PostDispatchesResponse:
+		collection, name = geo.Dispatches([]firstdue.GetDispatchesResponseDispatch{firstdue.GetDispatchesResponseDispatch(value)}, geoOptions), "Dispatches"
+	case watchEvent:
+		collection, name = geo.Dispatches([]firstdue.GetDispatchesResponseDispatch{value.GetDispatchesResponseDispatch}, geoOptions), "Dispatches"
+	case firstdue.NfirsNotification:
+		collection, name = geo.Notifications([]firstdue.NfirsNotificationRecord{{Notification: value}}, geoOptions), "NFIRS notifications"
+	case firstdue.GetNfirsNotificationsIDResponse:
+		collection, name = geo.Notifications([]firstdue.NfirsNotificationRecord{{Notification: firstdue.NfirsNotification(value)}}, geoOptions), "NFIRS notifications"
+	case firstdue.GetNfirsNotificationsDispatchNumberIDResponse:
+		collection, name = geo.Notifications([]firstdue.NfirsNotificationRecord{{Notification: firstdue.NfirsNotification(value)}}, geoOptions), "NFIRS notifications"
+	case firstdue.NfirsNotificationRecord:
+		collection, name = geo.Notifications([]firstdue.NfirsNotificationRecord{value}, geoOptions), "NFIRS notifications"
+	case []firstdue.NfirsNotificationRecord:
+		collection, name = geo.Notifications(value, geoOptions), "NFIRS notifications"
+	default:
+		return fmt.Errorf("the %q output format is only supported for dispatches and notifications", options.Output)
+	}
+
+	if options.Output == OutputKML {
+		err := geo.WriteKML(w, name, collection)
+		if err != nil {
+			return fmt.Errorf("error formatting KML response: %w", err)
+		}
+		return nil
+	}
+	err := geo.WriteGeoJSON(w, collection)
+	if err != nil {
+		return fmt.Errorf("error formatting GeoJSON response: %w", err)
+	}
+	return nil
+}
+
+// decodeOrdered decodes the next JSON value, keeping the order of object keys.
+//
+// Objects are returned as *orderedObject, arrays as []any, and numbers as json.Number.
+func decodeOrdered(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := &orderedObject{values: map[string]any{}}
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key := keyToken.(string)
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := object.values[key]; !exists {
+				object.keys = append(object.keys, key)
+			}
+			object.values[key] = value
+		}
+		_, err := decoder.Token() // The closing brace.
+		return object, err
+	case json.Delim('['):
+		list := []any{}
+		for decoder.More() {
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := decoder.Token() // The closing bracket.
+		return list, err
+	}
+	return token, nil
+}
+
+// outputRows returns the items to print one per row.
+func outputRows(document any) []any {
+	switch document := document.(type) {
+	case []any:
+		return document
+	case *orderedObject:
+		if list, ok := document.values["list"].([]any); ok {
+			return list
+		}
+	}
+	return []any{document}
+}
+
+// outputColumns returns the keys of all of the rows, in the order that they first appear.
+func outputColumns(rows []any) []string {
+	var columns []string
+	seen := map[string]bool{}
+	for _, row := range rows {
+		object, ok := row.(*orderedObject)
+		if !ok {
+			continue
+		}
+		for _, key := range object.keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+	}
+	if len(columns) == 0 {
+		columns = []string{"value"}
+	}
+	return columns
+}
+
+// outputCells returns the text of each column of the row.
+func outputCells(row any, columns []string) []string {
+	cells := make([]string, len(columns))
+	object, ok := row.(*orderedObject)
+	if !ok {
+		if len(cells) > 0 {
+			cells[0] = formatCell(row)
+		}
+		return cells
+	}
+	for i, column := range columns {
+		cells[i] = formatCell(object.values[column])
+	}
+	return cells
+}
+
+// formatCell formats a single value for a table or CSV cell.
+//
+// Lists of plain values are joined with commas; anything more complicated is printed as compact JSON.
+func formatCell(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return fmt.Sprintf("%t", value)
+	case []any:
+		var parts []string
+		for _, item := range value {
+			switch item.(type) {
+			case *orderedObject, []any:
+				contents, _ := json.Marshal(value)
+				return string(contents)
+			}
+			parts = append(parts, formatCell(item))
+		}
+		return strings.Join(parts, ",")
+	}
+	contents, _ := json.Marshal(value)
+	return string(contents)
+}
+
+// toPlain converts ordered objects into plain maps, for use with templates.
+func toPlain(value any) any {
+	switch value := value.(type) {
+	case *orderedObject:
+		result := map[string]any{}
+		for key, v := range value.values {
+			result[key] = toPlain(v)
+		}
+		return result
+	case []any:
+		result := make([]any, len(value))
+		for i, v := range value {
+			result[i] = toPlain(v)
+		}
+		return result
+	}
+	return value
+}
+
+// toYAMLNode converts a decoded JSON value into a YAML node, keeping the order of object keys.
+func toYAMLNode(value any) *yaml.Node {
+	switch value := value.(type) {
+	case *orderedObject:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, key := range value.keys {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, toYAMLNode(value.values[key]))
+		}
+		return node
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, v := range value {
+			node.Content = append(node.Content, toYAMLNode(v))
+		}
+		return node
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(value.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value.String()}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprintf("%t", value)}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprintf("%v", value)}
+}
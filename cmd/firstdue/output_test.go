@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// outputItem is a single object with the kinds of values that the formats treat differently.
+type outputItem struct {
+	ID       int         `json:"id"`
+	Name     string      `json:"name"`
+	Codes    []string    `json:"codes"`
+	Location outputPoint `json:"location"`
+	Note     *string     `json:"note"`
+	Active   bool        `json:"active"`
+}
+
+type outputPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+func TestWriteOutput(t *testing.T) {
+	list := firstdue.GetStationsResponse{
+		List:  []firstdue.GetStationsResponseStation{{UUID: "s-1", Name: "Station 1"}, {UUID: "s-2", Name: "Station, 2"}},
+		Total: 2,
+	}
+	item := outputItem{ID: 7, Name: "Engine 7", Codes: []string{"E7", "E7A"}, Location: outputPoint{X: 1.5, Y: -2}, Active: true}
+
+	for _, test := range []struct {
+		name    string
+		options OutputOptions
+		value   any
+		want    string
+	}{
+		{
+			"json list", OutputOptions{Output: OutputJSON}, list,
+			`{
+  "list": [
+    {
+      "uuid": "s-1",
+      "name": "Station 1"
+    },
+    {
+      "uuid": "s-2",
+      "name": "Station, 2"
+    }
+  ],
+  "total": 2
+}
+`,
+		},
+		{
+			"json object", OutputOptions{}, item,
+			`{
+  "id": 7,
+  "name": "Engine 7",
+  "codes": [
+    "E7",
+    "E7A"
+  ],
+  "location": {
+    "x": 1.5,
+    "y": -2
+  },
+  "note": null,
+  "active": true
+}
+`,
+		},
+		{
+			"ndjson list", OutputOptions{Output: OutputNDJSON}, list,
+			`{"uuid":"s-1","name":"Station 1"}
+{"uuid":"s-2","name":"Station, 2"}
+`,
+		},
+		{
+			"ndjson object", OutputOptions{Output: OutputNDJSON}, item,
+			`{"id":7,"name":"Engine 7","codes":["E7","E7A"],"location":{"x":1.5,"y":-2},"note":null,"active":true}
+`,
+		},
+		{
+			"yaml list", OutputOptions{Output: OutputYAML}, list,
+			`list:
+    - uuid: s-1
+      name: Station 1
+    - uuid: s-2
+      name: Station, 2
+total: 2
+`,
+		},
+		{
+			"yaml object", OutputOptions{Output: OutputYAML}, item,
+			`id: 7
+name: Engine 7
+codes:
+    - E7
+    - E7A
+location:
+    x: 1.5
+    y: -2
+note: null
+active: true
+`,
+		},
+		{
+			"table list", OutputOptions{Output: OutputTable}, list,
+			`UUID  NAME
+s-1   Station 1
+s-2   Station, 2
+`,
+		},
+		{
+			"table object", OutputOptions{Output: OutputTable}, item,
+			`ID  NAME      CODES   LOCATION          NOTE  ACTIVE
+7   Engine 7  E7,E7A  {"x":1.5,"y":-2}        true
+`,
+		},
+		{
+			"csv list", OutputOptions{Output: OutputCSV}, list,
+			`uuid,name
+s-1,Station 1
+s-2,"Station, 2"
+`,
+		},
+		{
+			"csv object", OutputOptions{Output: OutputCSV}, item,
+			`id,name,codes,location,note,active
+7,Engine 7,"E7,E7A","{""x"":1.5,""y"":-2}",,true
+`,
+		},
+		{
+			"table columns", OutputOptions{Output: OutputTable, Columns: []string{"name", "missing", "uuid"}}, list,
+			`NAME        MISSING  UUID
+Station 1            s-1
+Station, 2           s-2
+`,
+		},
+		{
+			"csv columns", OutputOptions{Output: OutputCSV, Columns: []string{"active", "id"}}, item,
+			`active,id
+true,7
+`,
+		},
+		{
+			"csv values", OutputOptions{Output: OutputCSV}, []string{"E1", "L1"},
+			`value
+E1
+L1
+`,
+		},
+		{
+			"template list", OutputOptions{Output: OutputTemplate, Template: "{{.uuid}}: {{.name}}"}, list,
+			`s-1: Station 1
+s-2: Station, 2
+`,
+		},
+	} {
+		var buffer bytes.Buffer
+		if err := writeOutput(&buffer, test.options, test.value); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if got := buffer.String(); got != test.want {
+			t.Errorf("%s: got:\n%s\nwant:\n%s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestWriteOutputErrors(t *testing.T) {
+	for _, test := range []struct {
+		options OutputOptions
+		want    string
+	}{
+		{OutputOptions{Output: "xml"}, `unsupported output format: "xml"`},
+		{OutputOptions{Output: OutputTemplate}, "--template is required"},
+		{OutputOptions{Output: OutputTemplate, Template: "{{.name"}, "invalid template"},
+		{OutputOptions{Output: OutputGeoJSON}, `the "geojson" output format is only supported for dispatches and notifications`},
+	} {
+		err := writeOutput(&bytes.Buffer{}, test.options, outputItem{})
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%+v: got %v, want %q", test.options, err, test.want)
+		}
+	}
+}
+
+func TestWriteGeoOutput(t *testing.T) {
+	dispatches := []firstdue.GetDispatchesResponseDispatch{
+		{ID: 42, Type: "FIRE", Address: "1 MAIN ST", Latitude: 38.9, Longitude: -77.03},
+		{ID: 43, Type: "EMS", Address: "2 OAK AVE"},
+	}
+	latitude, longitude := firstdue.StringFloat64(39.7817), firstdue.StringFloat64(-89.6501)
+	record := firstdue.NfirsNotificationRecord{Notification: firstdue.NfirsNotification{ID: 7, DispatchType: "FIRE", Address: "1 MAIN ST", Latitude: &latitude, Longitude: &longitude}}
+
+	for _, test := range []struct {
+		name     string
+		options  OutputOptions
+		value    any
+		contains []string
+		excludes []string
+	}{
+		{
+			"geojson dispatches",
+			OutputOptions{Output: OutputGeoJSON},
+			dispatches,
+			[]string{`"type": "FeatureCollection"`, `"id": 42`, `"coordinates": [`},
+			[]string{`"id": 43`},
+		},
+		{
+			"geojson unlocated dispatches",
+			OutputOptions{Output: OutputGeoJSON, Unlocated: true},
+			firstdue.GetDispatchesResponse(dispatches),
+			[]string{`"id": 42`, `"id": 43`, `"geometry": null`, `"location_status": "zero"`},
+			nil,
+		},
+		{
+			"geojson watch event",
+			OutputOptions{Output: OutputGeoJSON},
+			watchEvent{Event: "new", GetDispatchesResponseDispatch: dispatches[0]},
+			[]string{`"id": 42`},
+			nil,
+		},
+		{
+			"kml dispatches",
+			OutputOptions{Output: OutputKML},
+			dispatches,
+			[]string{"<name>Dispatches</name>", `<Placemark id="dispatch-42">`, "<coordinates>-77.03,38.9</coordinates>"},
+			[]string{"dispatch-43"},
+		},
+		{
+			"kml notification",
+			OutputOptions{Output: OutputKML},
+			record,
+			[]string{"<name>NFIRS notifications</name>", `<Placemark id="notification-7">`, "<coordinates>-89.6501,39.7817</coordinates>"},
+			nil,
+		},
+		{
+			"kml unlocated notifications",
+			OutputOptions{Output: OutputKML, Unlocated: true},
+			[]firstdue.NfirsNotificationRecord{record, {Notification: firstdue.NfirsNotification{ID: 8, DispatchType: "EMS"}}},
+			[]string{`<Placemark id="notification-8">`, "<value>missing</value>"},
+			nil,
+		},
+	} {
+		var buffer bytes.Buffer
+		if err := writeOutput(&buffer, test.options, test.value); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		got := buffer.String()
+		for _, want := range test.contains {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: %q is missing from:\n%s", test.name, want, got)
+			}
+		}
+		for _, unwanted := range test.excludes {
+			if strings.Contains(got, unwanted) {
+				t.Errorf("%s: %q is in:\n%s", test.name, unwanted, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+type StationsCommand struct {
+	List *StationsListCommand `arg:"subcommand" help:"List stations"`
+}
+
+type StationsListCommand struct {
+	Page    int    `arg:"--page" help:"Page number"`
+	PerPage int    `arg:"--per-page" help:"Number of stations per page"`
+	Name    string `arg:"--name" help:"Only list stations with this name"`
+	All     bool   `arg:"--all" help:"List the stations on every page"`
+}
+
+func runStations(ctx context.Context, args Args, config *Config) error {
+	switch {
+	case args.Stations.List != nil:
+		client, err := newClient(ctx, args, config)
+		if err != nil {
+			return err
+		}
+		input := firstdue.GetStationsRequest{
+			Page:    args.Stations.List.Page,
+			PerPage: args.Stations.List.PerPage,
+			Name:    args.Stations.List.Name,
+		}
+		if args.Stations.List.All {
+			output, err := client.GetAllStations(ctx, input)
+			if err != nil {
+				return fmt.Errorf("error listing stations: %w", err)
+			}
+			return printOutput(args, output)
+		}
+		output, err := client.GetStations(ctx, input)
+		if err != nil {
+			return fmt.Errorf("error listing stations: %w", err)
+		}
+		return printOutput(args, output)
+	default:
+		return errUsage
+	}
+}
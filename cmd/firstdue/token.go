@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// tokenExpiryMargin is how long before its expiry a cached token stops being used.
+const tokenExpiryMargin = 2 * time.Minute
+
+// cachedToken is an access token that is saved between runs.
+type cachedToken struct {
+	BaseURL     string    `json:"baseURL"`
+	Username    string    `json:"username"`
+	AccessToken string    `json:"accessToken"`
+	TokenType   string    `json:"tokenType"`
+	Scope       string    `json:"scope"`
+	IssuedAt    time.Time `json:"issuedAt"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// valid returns true if the token belongs to the account and is not about to expire.
+func (t cachedToken) valid(account Account) bool {
+	return t.AccessToken != "" &&
+		t.BaseURL == account.BaseURL &&
+		t.Username == account.Username &&
+		time.Now().Before(t.ExpiresAt.Add(-tokenExpiryMargin))
+}
+
+// tokenFilePath returns the path of the token cache for the named configuration.
+func tokenFilePath(name string) (string, error) {
+	directory, err := configDirectory()
+	if err != nil {
+		return "", err
+	}
+	directory += string(os.PathSeparator) + "tokens"
+	err = os.MkdirAll(directory, 0700)
+	if err != nil {
+		return "", fmt.Errorf("error creating token directory: %w", err)
+	}
+	return directory + string(os.PathSeparator) + url.PathEscape(name) + ".json", nil
+}
+
+// loadToken returns the cached token for the named configuration.
+//
+// If there is no cached token, the zero value is returned.
+func loadToken(name string) (cachedToken, error) {
+	var token cachedToken
+	path, err := tokenFilePath(name)
+	if err != nil {
+		return token, err
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return token, nil
+		}
+		return token, fmt.Errorf("error reading token cache: %w", err)
+	}
+	err = json.Unmarshal(contents, &token)
+	if err != nil {
+		// A corrupt cache is the same as no cache.
+		return cachedToken{}, nil
+	}
+	return token, nil
+}
+
+// saveToken saves the token for the named configuration.
+func saveToken(name string, token cachedToken) error {
+	path, err := tokenFilePath(name)
+	if err != nil {
+		return err
+	}
+	contents, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(path, contents, 0600)
+	if err != nil {
+		return fmt.Errorf("error writing token cache: %w", err)
+	}
+	return nil
+}
+
+// deleteToken removes the cached token for the named configuration.
+func deleteToken(name string) error {
+	path, err := tokenFilePath(name)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing token cache: %w", err)
+	}
+	return nil
+}
+
+// tokenSource provides the access token for a configuration, authenticating only when the cached token is missing,
+// about to expire, or rejected by the server.
+type tokenSource struct {
+	name    string
+	account Account
+	options []firstdue.ClientOption // Options for the client that is used to authenticate.
+
+	mutex sync.Mutex
+	token cachedToken
+}
+
+// Token returns a usable access token.
+func (s *tokenSource) Token(ctx context.Context) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.token.valid(s.account) {
+		return s.token.AccessToken, nil
+	}
+	token, err := loadToken(s.name)
+	if err != nil {
+		return "", err
+	}
+	if token.valid(s.account) {
+		s.token = token
+		return s.token.AccessToken, nil
+	}
+	return s.refresh(ctx)
+}
+
+// Refresh authenticates again, replacing the cached token.
+func (s *tokenSource) Refresh(ctx context.Context, rejected string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.token.AccessToken != rejected && s.token.valid(s.account) {
+		// Another request has already refreshed the token.
+		return s.token.AccessToken, nil
+	}
+	return s.refresh(ctx)
+}
+
+func (s *tokenSource) refresh(ctx context.Context) (string, error) {
+	password, err := s.account.password(s.name)
+	if err != nil {
+		return "", err
+	}
+	issuedAt := time.Now()
+	output, err := firstdue.NewClient(s.options...).PostAuthToken(ctx, firstdue.PostAuthTokenRequest{
+		GrantType: "client_credentials",
+		Email:     s.account.Username,
+		Password:  password,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error authenticating: %w", err)
+	}
+	s.token = cachedToken{
+		BaseURL:     s.account.BaseURL,
+		Username:    s.account.Username,
+		AccessToken: output.AccessToken,
+		TokenType:   output.TokenType,
+		Scope:       output.Scope,
+		IssuedAt:    issuedAt,
+		ExpiresAt:   issuedAt.Add(time.Duration(output.ExpiresIn) * time.Second),
+	}
+	err = saveToken(s.name, s.token)
+	if err != nil {
+		// The token still works for this run.
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return s.token.AccessToken, nil
+}
+
+// tokenTransport adds the access token to every request, and authenticates again and retries once if the
+// server rejects the token.
+type tokenTransport struct {
+	source *tokenSource
+	base   http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	token, err := t.source.Token(request.Context())
+	if err != nil {
+		return nil, err
+	}
+	response, err := t.base.RoundTrip(withBearerToken(request, token))
+	if err != nil || response.StatusCode != http.StatusUnauthorized {
+		return response, err
+	}
+	if request.Body != nil && request.GetBody == nil {
+		return response, nil // The request cannot be replayed.
+	}
+
+	token, err = t.source.Refresh(request.Context(), token)
+	if err != nil {
+		return response, nil
+	}
+	retry := withBearerToken(request, token)
+	if request.GetBody != nil {
+		retry.Body, err = request.GetBody()
+		if err != nil {
+			return response, nil
+		}
+	}
+	response.Body.Close()
+	return t.base.RoundTrip(retry)
+}
+
+// withBearerToken returns a copy of the request with the given access token.
+func withBearerToken(request *http.Request, token string) *http.Request {
+	request = request.Clone(request.Context())
+	request.Header.Set("Authorization", "Bearer "+token)
+	return request
+}
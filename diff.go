@@ -0,0 +1,250 @@
+package firstdue
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NfirsNotificationRecord is an NFIRS notification along with its apparatus records.
+type NfirsNotificationRecord struct {
+	Notification NfirsNotification            `json:"notification"`
+	Apparatuses  []NfirsNotificationApparatus `json:"apparatuses,omitempty"`
+}
+
+// FieldChange describes a single field that differs between two values.
+//
+// The field is identified by its JSON name.  An empty value (a nil pointer, a zero value, or a zero timestamp)
+// is reported as nil.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// ApparatusChange describes how a single apparatus differs between two notifications.
+type ApparatusChange struct {
+	UnitCode string        `json:"unit_code"`
+	Action   string        `json:"action"`            // One of the ApparatusChange* constants.
+	Changes  []FieldChange `json:"changes,omitempty"` // Only set for ApparatusChangeChanged.
+}
+
+const (
+	ApparatusChangeAdded   = "added"
+	ApparatusChangeRemoved = "removed"
+	ApparatusChangeChanged = "changed"
+)
+
+// NotificationDiff is the set of differences between two NFIRS notifications.
+type NotificationDiff struct {
+	Fields      []FieldChange     `json:"fields,omitempty"`
+	Apparatuses []ApparatusChange `json:"apparatuses,omitempty"`
+}
+
+// IsEmpty returns true if there are no differences.
+func (d NotificationDiff) IsEmpty() bool {
+	return len(d.Fields) == 0 && len(d.Apparatuses) == 0
+}
+
+// String renders the differences as human-readable text, one change per line.
+func (d NotificationDiff) String() string {
+	var b strings.Builder
+	for _, change := range d.Fields {
+		fmt.Fprintf(&b, "~ %s: %s -> %s\n", change.Field, formatDiffValue(change.Old), formatDiffValue(change.New))
+	}
+	for _, apparatus := range d.Apparatuses {
+		switch apparatus.Action {
+		case ApparatusChangeAdded:
+			fmt.Fprintf(&b, "+ apparatus %s\n", apparatus.UnitCode)
+		case ApparatusChangeRemoved:
+			fmt.Fprintf(&b, "- apparatus %s\n", apparatus.UnitCode)
+		default:
+			fmt.Fprintf(&b, "~ apparatus %s\n", apparatus.UnitCode)
+			for _, change := range apparatus.Changes {
+				fmt.Fprintf(&b, "    ~ %s: %s -> %s\n", change.Field, formatDiffValue(change.Old), formatDiffValue(change.New))
+			}
+		}
+	}
+	return b.String()
+}
+
+// DiffNotifications returns the field-level differences between two notifications.
+//
+// Nil pointers and empty values are treated as equal, and timestamps are compared by instant rather than by
+// representation.  The server-assigned "id" field is ignored.  Apparatuses are matched by unit code,
+// case-insensitively and ignoring surrounding whitespace.  If a unit code appears more than once, its occurrences are
+// matched in order, so an extra occurrence on either side is reported as added or removed.
+func DiffNotifications(a, b NfirsNotificationRecord) NotificationDiff {
+	var diff NotificationDiff
+	for _, change := range diffStructFields(reflect.ValueOf(a.Notification), reflect.ValueOf(b.Notification)) {
+		if change.Field == "id" {
+			continue
+		}
+		diff.Fields = append(diff.Fields, change)
+	}
+
+	aMap := indexApparatuses(a.Apparatuses)
+	bMap := indexApparatuses(b.Apparatuses)
+	var keys []apparatusKey
+	for key := range aMap {
+		keys = append(keys, key)
+	}
+	for key := range bMap {
+		if _, exists := aMap[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].unitCode != keys[j].unitCode {
+			return keys[i].unitCode < keys[j].unitCode
+		}
+		return keys[i].occurrence < keys[j].occurrence
+	})
+
+	for _, key := range keys {
+		aApparatus, aExists := aMap[key]
+		bApparatus, bExists := bMap[key]
+		switch {
+		case !aExists:
+			diff.Apparatuses = append(diff.Apparatuses, ApparatusChange{UnitCode: bApparatus.UnitCode, Action: ApparatusChangeAdded})
+		case !bExists:
+			diff.Apparatuses = append(diff.Apparatuses, ApparatusChange{UnitCode: aApparatus.UnitCode, Action: ApparatusChangeRemoved})
+		default:
+			var changes []FieldChange
+			for _, change := range diffStructFields(reflect.ValueOf(aApparatus), reflect.ValueOf(bApparatus)) {
+				if change.Field == "unit_code" {
+					continue // Only the case or the whitespace differs.
+				}
+				changes = append(changes, change)
+			}
+			if len(changes) > 0 {
+				diff.Apparatuses = append(diff.Apparatuses, ApparatusChange{UnitCode: aApparatus.UnitCode, Action: ApparatusChangeChanged, Changes: changes})
+			}
+		}
+	}
+	return diff
+}
+
+// apparatusKey identifies an apparatus in a notification: the nth occurrence of its normalized unit code.
+type apparatusKey struct {
+	unitCode   string
+	occurrence int
+}
+
+// indexApparatuses maps each apparatus to its key.
+func indexApparatuses(apparatuses []NfirsNotificationApparatus) map[apparatusKey]NfirsNotificationApparatus {
+	index := map[apparatusKey]NfirsNotificationApparatus{}
+	counts := map[string]int{}
+	for _, apparatus := range apparatuses {
+		unitCode := normalizeUnitCode(apparatus.UnitCode)
+		index[apparatusKey{unitCode: unitCode, occurrence: counts[unitCode]}] = apparatus
+		counts[unitCode]++
+	}
+	return index
+}
+
+// normalizeUnitCode returns the canonical form of a unit code for comparisons.
+func normalizeUnitCode(unitCode string) string {
+	return strings.ToUpper(strings.TrimSpace(unitCode))
+}
+
+var (
+	timestampType      = reflect.TypeOf(Timestamp{})
+	jsonRawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// diffStructFields compares each exported field of two structs of the same type.
+func diffStructFields(a, b reflect.Value) []FieldChange {
+	var changes []FieldChange
+	t := a.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		aValue, aEmpty := normalizeDiffValue(a.Field(i))
+		bValue, bEmpty := normalizeDiffValue(b.Field(i))
+		if aEmpty && bEmpty {
+			continue
+		}
+		if !aEmpty && !bEmpty && diffValuesEqual(aValue, bValue) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: name, Old: aValue, New: bValue})
+	}
+	return changes
+}
+
+// normalizeDiffValue dereferences pointers and reports whether the value is empty.
+//
+// Empty values are returned as nil.  Raw JSON is compacted so that whitespace does not matter.
+func normalizeDiffValue(v reflect.Value) (any, bool) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, true
+		}
+		v = v.Elem()
+	}
+	switch v.Type() {
+	case timestampType:
+		t := v.Interface().(Timestamp)
+		if t.IsZero() {
+			return nil, true
+		}
+		return t, false
+	case jsonRawMessageType:
+		raw := bytes.TrimSpace(v.Bytes())
+		if len(raw) == 0 || bytes.Equal(raw, []byte("null")) || bytes.Equal(raw, []byte(`""`)) {
+			return nil, true
+		}
+		var buffer bytes.Buffer
+		if err := json.Compact(&buffer, raw); err != nil {
+			return json.RawMessage(raw), false
+		}
+		return json.RawMessage(buffer.Bytes()), false
+	}
+	if v.IsZero() {
+		return nil, true
+	}
+	return v.Interface(), false
+}
+
+// diffValuesEqual compares two normalized, non-empty values.
+func diffValuesEqual(a, b any) bool {
+	switch a := a.(type) {
+	case Timestamp:
+		b, ok := b.(Timestamp)
+		return ok && time.Time(a).Equal(time.Time(b))
+	case json.RawMessage:
+		b, ok := b.(json.RawMessage)
+		return ok && bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// formatDiffValue formats a normalized value for the text rendering of a diff.
+func formatDiffValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "(empty)"
+	case Timestamp:
+		return time.Time(v).Format(time.RFC3339)
+	case json.RawMessage:
+		return string(v)
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprintf("%v", v)
+}
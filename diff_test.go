@@ -0,0 +1,141 @@
+package firstdue_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+func TestDiffNotificationsFields(t *testing.T) {
+	empty := ""
+	place := "CITY HALL"
+	zero := firstdue.StringFloat64(0)
+	latitude := firstdue.StringFloat64(38.9)
+	alarm := time.Date(2026, 10, 19, 13, 30, 0, 0, time.UTC)
+	chicago := time.FixedZone("CDT", -5*60*60)
+
+	a := firstdue.NfirsNotificationRecord{Notification: firstdue.NfirsNotification{
+		ID:             1,
+		DispatchNumber: "26-1",
+		AlarmAt:        firstdue.Timestamp(alarm),
+		PlaceName:      nil,
+		BusinessName:   nil,
+		Latitude:       nil,
+		AidFDIDNumber:  json.RawMessage(`null`),
+		AidFDIDNumbers: json.RawMessage(`[1, 2]`),
+	}}
+	b := firstdue.NfirsNotificationRecord{Notification: firstdue.NfirsNotification{
+		ID:              2, // Ignored.
+		DispatchNumber:  "26-1",
+		AlarmAt:         firstdue.Timestamp(alarm.In(chicago)),    // The same instant.
+		PlaceName:       &empty,                                   // Nil and empty are equal.
+		BusinessName:    &place,                                   // Changed.
+		Latitude:        &zero,                                    // Nil and zero are equal.
+		AidFDIDNumber:   nil,                                      // Null and missing are equal.
+		AidFDIDNumbers:  json.RawMessage("[1,2]"),                 // Only the whitespace differs.
+		CallCompletedAt: firstdue.Timestamp(alarm.Add(time.Hour)), // Changed.
+	}}
+	diff := firstdue.DiffNotifications(a, b)
+	want := []firstdue.FieldChange{
+		{Field: "business_name", Old: nil, New: "CITY HALL"},
+		{Field: "call_completed_at", Old: nil, New: firstdue.Timestamp(alarm.Add(time.Hour))},
+	}
+	if !reflect.DeepEqual(diff.Fields, want) || len(diff.Apparatuses) != 0 {
+		t.Errorf("got %+v, want %+v", diff, want)
+	}
+
+	a.Notification.Latitude = &latitude
+	a.Notification.BusinessName = &place
+	a.Notification.CallCompletedAt = b.Notification.CallCompletedAt
+	diff = firstdue.DiffNotifications(a, b)
+	want = []firstdue.FieldChange{{Field: "latitude", Old: firstdue.StringFloat64(38.9), New: nil}}
+	if !reflect.DeepEqual(diff.Fields, want) {
+		t.Errorf("got %+v, want %+v", diff.Fields, want)
+	}
+
+	if diff := firstdue.DiffNotifications(b, b); !diff.IsEmpty() {
+		t.Errorf("expected no differences, got %+v", diff)
+	}
+}
+
+func TestDiffNotificationsApparatuses(t *testing.T) {
+	dispatched := firstdue.Timestamp(time.Date(2026, 10, 19, 13, 30, 0, 0, time.UTC))
+	arrived := firstdue.Timestamp(time.Date(2026, 10, 19, 13, 36, 0, 0, time.UTC))
+	a := firstdue.NfirsNotificationRecord{Apparatuses: []firstdue.NfirsNotificationApparatus{
+		{UnitCode: "E1", DispatchAt: dispatched},
+		{UnitCode: "L2", DispatchAt: dispatched},
+		{UnitCode: "M3", DispatchAt: dispatched},
+	}}
+	b := firstdue.NfirsNotificationRecord{Apparatuses: []firstdue.NfirsNotificationApparatus{
+		{UnitCode: "m3 ", DispatchAt: dispatched},                   // Only the case and whitespace differ.
+		{UnitCode: "l2", DispatchAt: dispatched, ArriveAt: arrived}, // Changed.
+		{UnitCode: "BC4", DispatchAt: dispatched},                   // Added.
+	}}
+	diff := firstdue.DiffNotifications(a, b)
+	want := []firstdue.ApparatusChange{
+		{UnitCode: "BC4", Action: firstdue.ApparatusChangeAdded},
+		{UnitCode: "E1", Action: firstdue.ApparatusChangeRemoved},
+		{UnitCode: "L2", Action: firstdue.ApparatusChangeChanged, Changes: []firstdue.FieldChange{{Field: "arrive_at", Old: nil, New: arrived}}},
+	}
+	if !reflect.DeepEqual(diff.Apparatuses, want) || len(diff.Fields) != 0 {
+		t.Errorf("got %+v, want %+v", diff.Apparatuses, want)
+	}
+
+	// A unit code that appears more than once is matched in order, so the extra one is reported.
+	a.Apparatuses = []firstdue.NfirsNotificationApparatus{{UnitCode: "E1"}, {UnitCode: "e1 ", DispatchAt: dispatched}}
+	b.Apparatuses = []firstdue.NfirsNotificationApparatus{{UnitCode: "E1"}}
+	diff = firstdue.DiffNotifications(a, b)
+	want = []firstdue.ApparatusChange{{UnitCode: "e1 ", Action: firstdue.ApparatusChangeRemoved}}
+	if !reflect.DeepEqual(diff.Apparatuses, want) {
+		t.Errorf("got %+v, want %+v", diff.Apparatuses, want)
+	}
+	diff = firstdue.DiffNotifications(b, a)
+	want = []firstdue.ApparatusChange{{UnitCode: "e1 ", Action: firstdue.ApparatusChangeAdded}}
+	if !reflect.DeepEqual(diff.Apparatuses, want) {
+		t.Errorf("got %+v, want %+v", diff.Apparatuses, want)
+	}
+}
+
+func TestNotificationDiffRendering(t *testing.T) {
+	arrived := firstdue.Timestamp(time.Date(2026, 10, 19, 13, 36, 0, 0, time.UTC))
+	diff := firstdue.NotificationDiff{
+		Fields: []firstdue.FieldChange{
+			{Field: "address", Old: "1 MAIN ST", New: "2 MAIN ST"},
+			{Field: "alarms", Old: nil, New: 2},
+		},
+		Apparatuses: []firstdue.ApparatusChange{
+			{UnitCode: "BC4", Action: firstdue.ApparatusChangeAdded},
+			{UnitCode: "E1", Action: firstdue.ApparatusChangeRemoved},
+			{UnitCode: "L2", Action: firstdue.ApparatusChangeChanged, Changes: []firstdue.FieldChange{{Field: "arrive_at", Old: nil, New: arrived}}},
+		},
+	}
+
+	want := `~ address: "1 MAIN ST" -> "2 MAIN ST"
+~ alarms: (empty) -> 2
++ apparatus BC4
+- apparatus E1
+~ apparatus L2
+    ~ arrive_at: (empty) -> 2026-10-19T13:36:00Z
+`
+	if got := diff.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	contents, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON := `{"fields":[{"field":"address","old":"1 MAIN ST","new":"2 MAIN ST"},{"field":"alarms","old":null,"new":2}],` +
+		`"apparatuses":[{"unit_code":"BC4","action":"added"},{"unit_code":"E1","action":"removed"},` +
+		`{"unit_code":"L2","action":"changed","changes":[{"field":"arrive_at","old":null,"new":"2026-10-19T13:36:00Z"}]}]}`
+	if string(contents) != wantJSON {
+		t.Errorf("got  %s\nwant %s", contents, wantJSON)
+	}
+
+	if got := (firstdue.NotificationDiff{}).String(); got != "" {
+		t.Errorf("got %q for an empty diff", got)
+	}
+}
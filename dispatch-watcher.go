@@ -0,0 +1,196 @@
+package firstdue
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// Dispatch event types.
+const (
+	DispatchEventNew     = "new"
+	DispatchEventUpdated = "updated"
+)
+
+// ErrRetryLater can be returned (or wrapped) by the handler given to DispatchWatcher.Run to leave the event, and the
+// ones after it, for the next poll instead of stopping.
+var ErrRetryLater = errors.New("retry at the next poll")
+
+// DispatchEvent is a dispatch that was seen for the first time, or that changed since it was last seen.
+type DispatchEvent struct {
+	Type     string                        `json:"event"` // One of the DispatchEvent* constants.
+	Dispatch GetDispatchesResponseDispatch `json:"dispatch"`
+
+	fingerprint string // The hash of the dispatch, recorded when the event is acknowledged.
+}
+
+// DispatchWatcherState is the progress of a DispatchWatcher.
+//
+// It can be saved and given to a new watcher so that it resumes where the old one stopped.
+type DispatchWatcherState struct {
+	HighWaterMark Timestamp      `json:"high_water_mark"` // The latest creation time of any dispatch seen.
+	Fingerprints  map[int]string `json:"fingerprints"`    // Maps a dispatch ID to a hash of its contents when it was last seen.
+	Created       map[int]int64  `json:"created"`         // Maps a dispatch ID to its creation time (Unix seconds), for pruning.
+}
+
+// DispatchWatcher polls for new and updated dispatches.
+//
+// The API only filters dispatches by creation time, so each poll looks back a little before the high-water mark
+// in order to notice dispatches that were updated after they were created.
+type DispatchWatcher struct {
+	client   *Client
+	lookback time.Duration
+	state    DispatchWatcherState
+}
+
+// NewDispatchWatcher returns a watcher that starts from the given state.
+//
+// Dispatches created within the lookback window before the high-water mark are polled again to detect updates.
+// If the state has no high-water mark, the first poll reports the dispatches that the API returns by default.
+func NewDispatchWatcher(client *Client, state DispatchWatcherState, lookback time.Duration) *DispatchWatcher {
+	if state.Fingerprints == nil {
+		state.Fingerprints = map[int]string{}
+	}
+	if state.Created == nil {
+		state.Created = map[int]int64{}
+	}
+	return &DispatchWatcher{
+		client:   client,
+		lookback: lookback,
+		state:    state,
+	}
+}
+
+// State returns a copy of the current state, for saving.
+func (w *DispatchWatcher) State() DispatchWatcherState {
+	state := DispatchWatcherState{
+		HighWaterMark: w.state.HighWaterMark,
+		Fingerprints:  map[int]string{},
+		Created:       map[int]int64{},
+	}
+	for id, fingerprint := range w.state.Fingerprints {
+		state.Fingerprints[id] = fingerprint
+	}
+	for id, created := range w.state.Created {
+		state.Created[id] = created
+	}
+	return state
+}
+
+// Poll fetches the dispatches on every page and returns the ones that are new or have changed, oldest first.
+//
+// An event is only recorded in the state once it is given to Acknowledge; until then, every poll returns it again.
+// Acknowledge the events in order, and stop at the first one that cannot be handled, so that the high-water mark
+// never passes an event that was not delivered.
+func (w *DispatchWatcher) Poll(ctx context.Context) ([]DispatchEvent, error) {
+	input := GetDispatchesRequest{}
+	if !w.state.HighWaterMark.IsZero() {
+		input.Since = Timestamp(time.Time(w.state.HighWaterMark).Add(-w.lookback))
+	}
+	dispatches, err := w.client.GetAllDispatches(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("dispatchwatcher: %w", err)
+	}
+	sort.SliceStable(dispatches, func(i, j int) bool {
+		return time.Time(dispatches[i].CreatedAt).Before(time.Time(dispatches[j].CreatedAt))
+	})
+
+	var events []DispatchEvent
+	for _, dispatch := range dispatches {
+		fingerprint, err := dispatchFingerprint(dispatch)
+		if err != nil {
+			return nil, fmt.Errorf("dispatchwatcher: %w", err)
+		}
+
+		previous, seen := w.state.Fingerprints[dispatch.ID]
+		switch {
+		case !seen:
+			events = append(events, DispatchEvent{Type: DispatchEventNew, Dispatch: dispatch, fingerprint: fingerprint})
+		case previous != fingerprint:
+			events = append(events, DispatchEvent{Type: DispatchEventUpdated, Dispatch: dispatch, fingerprint: fingerprint})
+		}
+	}
+
+	// Forget the dispatches that are too old to be polled again.
+	cutoff := time.Time(w.state.HighWaterMark).Add(-w.lookback).Unix()
+	for id, created := range w.state.Created {
+		if created < cutoff {
+			delete(w.state.Created, id)
+			delete(w.state.Fingerprints, id)
+		}
+	}
+
+	return events, nil
+}
+
+// Acknowledge records that the event was handled, so that the dispatch is not reported again until it changes.
+// Acknowledging an event again has no effect.
+func (w *DispatchWatcher) Acknowledge(event DispatchEvent) {
+	dispatch := event.Dispatch
+	fingerprint := event.fingerprint
+	if fingerprint == "" {
+		// The event did not come from Poll.
+		fingerprint, _ = dispatchFingerprint(dispatch)
+	}
+	w.state.Fingerprints[dispatch.ID] = fingerprint
+	w.state.Created[dispatch.ID] = time.Time(dispatch.CreatedAt).Unix()
+	if time.Time(dispatch.CreatedAt).After(time.Time(w.state.HighWaterMark)) {
+		w.state.HighWaterMark = dispatch.CreatedAt
+	}
+}
+
+// dispatchFingerprint returns a hash of the dispatch's contents.
+func dispatchFingerprint(dispatch GetDispatchesResponseDispatch) (string, error) {
+	contents, err := json.Marshal(dispatch)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(contents)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// Run polls at the given interval until the context is canceled, calling the handler for each event.
+//
+// Poll failures are logged and retried at the next interval.  Each event is acknowledged once the handler returns
+// nil; the handler may also acknowledge it itself, for example to save the state before the next event.  If the
+// handler returns an error that wraps ErrRetryLater, the error is logged and the event and the ones after it are left
+// for the next poll.  If it returns any other error, Run stops and returns it, and the event stays unacknowledged.
+// When the context is canceled, Run returns nil.
+func (w *DispatchWatcher) Run(ctx context.Context, interval time.Duration, handler func(DispatchEvent) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		events, err := w.Poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			slog.WarnContext(ctx, "Could not poll for dispatches.", "error", err)
+		}
+		for _, event := range events {
+			err := handler(event)
+			if errors.Is(err, ErrRetryLater) {
+				if ctx.Err() == nil {
+					slog.WarnContext(ctx, "Could not handle a dispatch event; retrying at the next poll.", "dispatch", event.Dispatch.ID, "error", err)
+				}
+				break
+			}
+			if err != nil {
+				return err
+			}
+			w.Acknowledge(event)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package firstdue_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+	"github.com/tekkamanendless/firstdue/mock"
+)
+
+func TestDispatchWatcher(t *testing.T) {
+	ctx := context.Background()
+	server := mock.NewServer(mock.Config{Seed: 1})
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	client := firstdue.NewClient(firstdue.WithBaseURL(httpServer.URL), firstdue.WithToken("test"))
+
+	// More dispatches than fit on one page of the API.
+	start := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	for i := range 45 {
+		server.AddDispatch(firstdue.GetDispatchesResponseDispatch{Type: "EMS", Address: "1 MAIN ST", CreatedAt: firstdue.Timestamp(start.Add(time.Duration(i) * time.Minute))})
+	}
+
+	watcher := firstdue.NewDispatchWatcher(client, firstdue.DispatchWatcherState{HighWaterMark: firstdue.Timestamp(start)}, time.Hour)
+	events, err := watcher.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(events) != 45 || events[0].Type != firstdue.DispatchEventNew {
+		t.Fatalf("got %d events, want 45 new ones", len(events))
+	}
+
+	// Only the acknowledged events are recorded, so the rest come back.
+	for _, event := range events[:10] {
+		watcher.Acknowledge(event)
+	}
+	if want := events[9].Dispatch.CreatedAt; !time.Time(watcher.State().HighWaterMark).Equal(time.Time(want)) {
+		t.Errorf("got high-water mark %v, want %v", time.Time(watcher.State().HighWaterMark), time.Time(want))
+	}
+	events, err = watcher.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(events) != 35 || !time.Time(events[0].Dispatch.CreatedAt).Equal(start.Add(10*time.Minute)) {
+		t.Fatalf("got %d events, want the last 35", len(events))
+	}
+	for _, event := range events {
+		watcher.Acknowledge(event)
+	}
+
+	// A restarted watcher picks up only what changed.
+	server.AddDispatch(firstdue.GetDispatchesResponseDispatch{Type: "FIRE", Address: "2 OAK AVE", CreatedAt: firstdue.Timestamp(start.Add(time.Hour))})
+	watcher = firstdue.NewDispatchWatcher(client, watcher.State(), time.Hour)
+	events, err = watcher.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(events) != 1 || events[0].Dispatch.Type != "FIRE" {
+		t.Fatalf("got %d events, want the new dispatch", len(events))
+	}
+}
+
+func TestDispatchWatcherRunRetry(t *testing.T) {
+	server := mock.NewServer(mock.Config{Seed: 1})
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	client := firstdue.NewClient(firstdue.WithBaseURL(httpServer.URL), firstdue.WithToken("test"))
+
+	start := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		server.AddDispatch(firstdue.GetDispatchesResponseDispatch{Type: "EMS", Address: "1 MAIN ST", CreatedAt: firstdue.Timestamp(start.Add(time.Duration(i) * time.Minute))})
+	}
+
+	// The second event fails once, so it and the third one are handled again at the next poll.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watcher := firstdue.NewDispatchWatcher(client, firstdue.DispatchWatcherState{HighWaterMark: firstdue.Timestamp(start)}, time.Hour)
+	var handled []int
+	failed := false
+	err := watcher.Run(ctx, time.Millisecond, func(event firstdue.DispatchEvent) error {
+		if len(handled) == 1 && !failed {
+			failed = true
+			return fmt.Errorf("webhook: %w", firstdue.ErrRetryLater)
+		}
+		handled = append(handled, event.Dispatch.ID)
+		if len(handled) == 3 {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(handled) != 3 || handled[0] == handled[1] || handled[1] == handled[2] {
+		t.Errorf("got dispatches %v, want each of the 3 once", handled)
+	}
+
+	// Any other error stops Run.
+	watcher = firstdue.NewDispatchWatcher(client, firstdue.DispatchWatcherState{HighWaterMark: firstdue.Timestamp(start)}, time.Hour)
+	stop := errors.New("stop")
+	if err := watcher.Run(context.Background(), time.Millisecond, func(firstdue.DispatchEvent) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("got %v, want %v", err, stop)
+	}
+}
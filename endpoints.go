@@ -0,0 +1,55 @@
+package firstdue
+
+// Endpoint describes an API endpoint that this package knows about.
+//
+// Path parameters are written in braces, such as "{id}".
+type Endpoint struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+	Assumed     bool   `json:"assumed,omitempty"` // If true, the endpoint is not in the published API documentation; its shape is a guess that the mock server implements.
+}
+
+// Endpoints is the catalog of the known API endpoints.
+//
+// The assumed endpoints follow the conventions of the documented ones, but they have not been checked against the
+// real API, so their paths, parameters, and fields may differ.
+var Endpoints = []Endpoint{
+	{"POST", "/v1/auth/token", "Request an access token", false},
+	{"GET", "/v1/dispatches", "List dispatches", false},
+	{"POST", "/v1/dispatches", "Create a dispatch", true},
+	{"GET", "/v1/stations", "List stations", false},
+	{"GET", "/v1/apparatuses", "List apparatuses", false},
+	{"GET", "/v1/logs/settings", "Get the connector log settings", false},
+	{"POST", "/v1/logs", "Send a log message", false},
+	{"POST", "/v1/logs/batch", "Send a batch of log messages", false},
+	{"POST", "/v1/nfirs-notifications", "Create an NFIRS notification", false},
+	{"GET", "/v1/nfirs-notifications/{id}", "Get an NFIRS notification", false},
+	{"PUT", "/v1/nfirs-notifications/{id}", "Update an NFIRS notification", false},
+	{"DELETE", "/v1/nfirs-notifications/{id}", "Delete an NFIRS notification", false},
+	{"GET", "/v1/nfirs-notifications/dispatch-number/{dispatch_number}", "Get an NFIRS notification by dispatch number", false},
+	{"PUT", "/v1/nfirs-notifications/number/{number}", "Update an NFIRS notification by number", false},
+	{"DELETE", "/v1/nfirs-notifications/number/{number}", "Delete an NFIRS notification by number", false},
+	{"POST", "/v1/nfirs-notifications/{id}/apparatuses", "Add an apparatus to an NFIRS notification", false},
+	{"PUT", "/v1/nfirs-notifications/{id}/apparatuses/{apparatus_id}", "Update an apparatus on an NFIRS notification", false},
+	{"DELETE", "/v1/nfirs-notifications/{id}/apparatuses/{apparatus_id}", "Remove an apparatus from an NFIRS notification", false},
+	{"POST", "/v1/nfirs-notifications/number/{number}/apparatuses", "Add an apparatus to an NFIRS notification by number", false},
+	{"PUT", "/v1/nfirs-notifications/number/{number}/apparatuses/code/{unit_code}", "Update an apparatus on an NFIRS notification by number and unit code", false},
+	{"DELETE", "/v1/nfirs-notifications/number/{number}/apparatuses/code/{unit_code}", "Remove an apparatus from an NFIRS notification by number and unit code", false},
+	{"GET", "/v1/hydrants", "List hydrants", true},
+	{"POST", "/v1/hydrants", "Create a hydrant", true},
+	{"GET", "/v1/hydrants/{id}", "Get a hydrant", true},
+	{"PUT", "/v1/hydrants/{id}", "Update a hydrant", true},
+	{"GET", "/v1/hydrants/{id}/flow-tests", "List the flow tests of a hydrant", true},
+	{"POST", "/v1/hydrants/{id}/flow-tests", "Add a flow test to a hydrant", true},
+	{"PUT", "/v1/hydrants/{id}/flow-tests/{flow_test_id}", "Update a flow test of a hydrant", true},
+	{"GET", "/v1/occupancies", "List occupancies", true},
+	{"POST", "/v1/occupancies", "Create an occupancy", true},
+	{"GET", "/v1/occupancies/{id}", "Get an occupancy", true},
+	{"PUT", "/v1/occupancies/{id}", "Update an occupancy", true},
+	{"DELETE", "/v1/occupancies/{id}", "Delete an occupancy", true},
+	{"GET", "/v1/inspections", "List inspections", true},
+	{"POST", "/v1/inspections", "Create an inspection", true},
+	{"GET", "/v1/inspections/{id}", "Get an inspection", true},
+	{"PUT", "/v1/inspections/{id}", "Update an inspection", true},
+}
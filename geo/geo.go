@@ -0,0 +1,183 @@
+// Package geo exports dispatches and NFIRS notifications as GeoJSON feature collections and KML documents, so that
+// they can be put on a map.
+//
+// Each dispatch or notification becomes a point feature with its type, address, units, and times as properties.  A
+// record without usable coordinates (missing, 0,0, or out of range) is skipped, or, if the options ask for it, kept
+// without a geometry and flagged with the "location_status" property.
+package geo
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// Location statuses, as given in the "location_status" property of a flagged feature.
+const (
+	LocationMissing = "missing"
+	LocationZero    = "zero"
+	LocationInvalid = "invalid"
+)
+
+// Options controls the export.
+type Options struct {
+	IncludeMissing bool // Keep the records without usable coordinates, without a geometry, instead of skipping them.
+}
+
+// FeatureCollection is a GeoJSON feature collection (RFC 7946).
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON feature.
+type Feature struct {
+	Type       string         `json:"type"`
+	ID         any            `json:"id,omitempty"`
+	Geometry   *Geometry      `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Geometry is a GeoJSON point.
+type Geometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"` // Longitude, then latitude.
+}
+
+// Name returns the feature's display name: its type and address.
+func (f Feature) Name() string {
+	name, _ := f.Properties["type"].(string)
+	if address, _ := f.Properties["address"].(string); address != "" {
+		if name != "" {
+			name += " at "
+		}
+		name += address
+	}
+	return name
+}
+
+// NewFeatureCollection returns an empty feature collection.
+func NewFeatureCollection() FeatureCollection {
+	return FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+}
+
+// Add adds a feature at the coordinates, or handles the missing coordinates according to the options.  It returns
+// false if the feature was skipped.
+func (c *FeatureCollection) Add(id any, latitude *float64, longitude *float64, properties map[string]any, options Options) bool {
+	feature := Feature{Type: "Feature", ID: id, Properties: properties}
+	status := locationStatus(latitude, longitude)
+	if status == "" {
+		feature.Geometry = &Geometry{Type: "Point", Coordinates: []float64{*longitude, *latitude}}
+	} else {
+		if !options.IncludeMissing {
+			return false
+		}
+		feature.Properties["location_status"] = status
+	}
+	c.Features = append(c.Features, feature)
+	return true
+}
+
+// WriteGeoJSON writes the collection as indented GeoJSON.
+func WriteGeoJSON(w io.Writer, collection FeatureCollection) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(collection)
+}
+
+// locationStatus returns the reason the coordinates cannot be used, or "" if they can.
+func locationStatus(latitude *float64, longitude *float64) string {
+	switch {
+	case latitude == nil || longitude == nil:
+		return LocationMissing
+	case *latitude == 0 || *longitude == 0:
+		// Either coordinate being exactly zero means a placeholder, such as 0,0 or an empty string that decoded as 0.
+		return LocationZero
+	case math.IsNaN(*latitude) || math.IsNaN(*longitude) || math.Abs(*latitude) > 90 || math.Abs(*longitude) > 180:
+		return LocationInvalid
+	}
+	return ""
+}
+
+// Dispatches returns the dispatches as a feature collection.
+func Dispatches(dispatches []firstdue.GetDispatchesResponseDispatch, options Options) FeatureCollection {
+	collection := NewFeatureCollection()
+	for _, dispatch := range dispatches {
+		properties := map[string]any{
+			"kind":               "dispatch",
+			"type":               dispatch.Type,
+			"incident_type_code": dispatch.IncidentTypeCode,
+			"message":            dispatch.Message,
+			"address":            dispatch.Address,
+			"address2":           dispatch.Address2,
+			"city":               dispatch.City,
+			"state_code":         dispatch.StateCode,
+			"units":              nonNil(dispatch.UnitCodes),
+			"status_code":        dispatch.StatusCode,
+			"xref_id":            dispatch.XrefID,
+			"created_at":         formatTime(dispatch.CreatedAt),
+		}
+		// The dispatch API uses 0,0 for a dispatch without a location.
+		latitude, longitude := dispatch.Latitude, dispatch.Longitude
+		collection.Add(dispatch.ID, &latitude, &longitude, properties, options)
+	}
+	return collection
+}
+
+// Notifications returns the notification records as a feature collection.
+func Notifications(records []firstdue.NfirsNotificationRecord, options Options) FeatureCollection {
+	collection := NewFeatureCollection()
+	for _, record := range records {
+		n := record.Notification
+		units := []string{}
+		for _, apparatus := range record.Apparatuses {
+			units = append(units, apparatus.UnitCode)
+		}
+		properties := map[string]any{
+			"kind":                        "notification",
+			"dispatch_number":             n.DispatchNumber,
+			"incident_number":             n.IncidentNumber,
+			"type":                        n.DispatchType,
+			"dispatch_incident_type_code": n.DispatchIncidentTypeCode,
+			"address":                     n.Address,
+			"cross_streets":               n.CrossStreets,
+			"city":                        n.City,
+			"state_code":                  n.StateCode,
+			"units":                       units,
+			"alarm_at":                    formatTime(n.AlarmAt),
+			"dispatch_notified_at":        formatTime(n.DispatchNotifiedAt),
+			"call_completed_at":           formatTime(n.CallCompletedAt),
+		}
+		// The notification's unit is part of the address, such as an apartment, and not an apparatus.
+		if n.Unit != nil {
+			properties["address_unit"] = *n.Unit
+		}
+		if n.PSAPAnsweredAt != nil {
+			properties["psap_answered_at"] = formatTime(*n.PSAPAnsweredAt)
+		}
+		var id any
+		if n.ID != 0 {
+			id = n.ID
+		}
+		collection.Add(id, (*float64)(n.Latitude), (*float64)(n.Longitude), properties, options)
+	}
+	return collection
+}
+
+// formatTime formats a timestamp as RFC 3339, or returns nil for the zero time.
+func formatTime(t firstdue.Timestamp) any {
+	if t.IsZero() {
+		return nil
+	}
+	return time.Time(t).Format(time.RFC3339)
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
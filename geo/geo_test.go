@@ -0,0 +1,170 @@
+package geo
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+func TestNotifications(t *testing.T) {
+	latitude, longitude := firstdue.StringFloat64(39.7817), firstdue.StringFloat64(-89.6501)
+	zero := firstdue.StringFloat64(0)
+	apartment := "Apt 2"
+	records := []firstdue.NfirsNotificationRecord{
+		{
+			Notification: firstdue.NfirsNotification{ID: 1, DispatchType: "EMS", Address: "55 ELM AVE", Unit: &apartment, Latitude: &latitude, Longitude: &longitude},
+			Apparatuses:  []firstdue.NfirsNotificationApparatus{{UnitCode: "M2"}, {UnitCode: "E3"}},
+		},
+		// The address unit is not an apparatus, even when there are none.
+		{Notification: firstdue.NfirsNotification{ID: 2, Address: "9 OAK ST", Unit: &apartment, Latitude: &latitude, Longitude: &longitude}},
+		// A coordinate that is exactly zero is a placeholder.
+		{Notification: firstdue.NfirsNotification{ID: 3, Address: "1 MAIN ST", Latitude: &zero, Longitude: &longitude}},
+	}
+
+	collection := Notifications(records, Options{})
+	if len(collection.Features) != 2 {
+		t.Fatalf("expected 2 features, found %d", len(collection.Features))
+	}
+	first := collection.Features[0]
+	if !reflect.DeepEqual(first.Properties["units"], []string{"M2", "E3"}) || first.Properties["address_unit"] != "Apt 2" {
+		t.Errorf("unexpected properties: %+v", first.Properties)
+	}
+	if first.Geometry == nil || first.Geometry.Coordinates[0] != -89.6501 || first.Name() != "EMS at 55 ELM AVE" {
+		t.Errorf("unexpected feature: %+v", first)
+	}
+	if second := collection.Features[1]; !reflect.DeepEqual(second.Properties["units"], []string{}) || second.Properties["address_unit"] != "Apt 2" {
+		t.Errorf("unexpected properties: %+v", second.Properties)
+	}
+
+	collection = Notifications(records, Options{IncludeMissing: true})
+	if third := collection.Features[2]; len(collection.Features) != 3 || third.Geometry != nil || third.Properties["location_status"] != LocationZero {
+		t.Errorf("unexpected features: %+v", collection.Features)
+	}
+}
+
+func TestDispatches(t *testing.T) {
+	created := firstdue.Timestamp(time.Date(2026, 10, 19, 13, 30, 0, 0, time.UTC))
+	dispatches := []firstdue.GetDispatchesResponseDispatch{
+		{ID: 1, Type: "FIRE", Message: "STRUCTURE FIRE", Address: "1 MAIN ST", Latitude: 38.9, Longitude: -77.03, UnitCodes: []string{"E1", "L1"}, CreatedAt: created},
+		{ID: 2, Type: "EMS", Address: "2 OAK AVE", Latitude: 0, Longitude: 0},        // The API's placeholder for no location.
+		{ID: 3, Type: "EMS", Address: "3 ELM ST", Latitude: 95, Longitude: -77.03},   // Out of range.
+		{ID: 4, Type: "SERVICE", Address: "4 PARK LN", Latitude: 38.9, Longitude: 0}, // Half of a placeholder.
+	}
+
+	collection := Dispatches(dispatches, Options{})
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 1 {
+		t.Fatalf("got %+v, want the one located dispatch", collection)
+	}
+	feature := collection.Features[0]
+	if feature.ID != 1 || feature.Geometry == nil || !reflect.DeepEqual(feature.Geometry.Coordinates, []float64{-77.03, 38.9}) {
+		t.Errorf("unexpected feature: %+v", feature)
+	}
+	if feature.Properties["kind"] != "dispatch" || feature.Properties["created_at"] != "2026-10-19T13:30:00Z" || !reflect.DeepEqual(feature.Properties["units"], []string{"E1", "L1"}) {
+		t.Errorf("unexpected properties: %+v", feature.Properties)
+	}
+	if _, flagged := feature.Properties["location_status"]; flagged {
+		t.Errorf("a located dispatch is flagged: %+v", feature.Properties)
+	}
+
+	collection = Dispatches(dispatches, Options{IncludeMissing: true})
+	var statuses []any
+	for _, feature := range collection.Features[1:] {
+		if feature.Geometry != nil {
+			t.Errorf("dispatch %v has a geometry", feature.ID)
+		}
+		statuses = append(statuses, feature.Properties["location_status"])
+	}
+	if want := []any{LocationZero, LocationInvalid, LocationZero}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("got statuses %v, want %v", statuses, want)
+	}
+	// A dispatch without units or a time still has those properties.
+	if second := collection.Features[1]; !reflect.DeepEqual(second.Properties["units"], []string{}) || second.Properties["created_at"] != nil {
+		t.Errorf("unexpected properties: %+v", second.Properties)
+	}
+
+	// A missing geometry is written as null, as GeoJSON requires.
+	var buffer bytes.Buffer
+	if err := WriteGeoJSON(&buffer, collection); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Features []map[string]json.RawMessage `json:"features"`
+	}
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil || len(decoded.Features) != 4 || string(decoded.Features[1]["geometry"]) != "null" {
+		t.Errorf("unexpected GeoJSON (%v):\n%s", err, buffer.String())
+	}
+}
+
+func TestWriteKML(t *testing.T) {
+	latitude, longitude := firstdue.StringFloat64(39.7817), firstdue.StringFloat64(-89.6501)
+	alarm := firstdue.Timestamp(time.Date(2026, 10, 19, 13, 30, 0, 0, time.UTC))
+	notified := firstdue.Timestamp(time.Date(2026, 10, 19, 13, 31, 0, 0, time.UTC))
+	collection := Notifications([]firstdue.NfirsNotificationRecord{
+		{Notification: firstdue.NfirsNotification{ID: 7, DispatchType: "FIRE", Address: "1 MAIN ST", AlarmAt: alarm, DispatchNotifiedAt: notified, Latitude: &latitude, Longitude: &longitude}},
+		{Notification: firstdue.NfirsNotification{DispatchType: "EMS", Address: "2 OAK AVE", DispatchNotifiedAt: notified}}, // No ID and no location.
+	}, Options{IncludeMissing: true})
+	dispatches := Dispatches([]firstdue.GetDispatchesResponseDispatch{
+		{ID: 42, Type: "ALARM", Message: "FIRE ALARM", Latitude: 38.9, Longitude: -77.03, CreatedAt: alarm},
+	}, Options{})
+	collection.Features = append(collection.Features, dispatches.Features...)
+
+	var buffer bytes.Buffer
+	if err := WriteKML(&buffer, "Incidents", collection); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buffer.String(), xml.Header) {
+		t.Errorf("the document has no XML header:\n%s", buffer.String())
+	}
+	var document kmlDocument
+	if err := xml.Unmarshal(buffer.Bytes(), &document); err != nil {
+		t.Fatalf("xml.Unmarshal: %v\n%s", err, buffer.String())
+	}
+	if document.Document.Name != "Incidents" || len(document.Document.Placemarks) != 3 {
+		t.Fatalf("unexpected document:\n%s", buffer.String())
+	}
+
+	for i, want := range []struct {
+		id          string
+		name        string
+		description string
+		when        string // The time stamp, or empty if there is none.
+		point       string // The coordinates, or empty if there is no point.
+	}{
+		{"notification-7", "FIRE at 1 MAIN ST", "", "2026-10-19T13:30:00Z", "-89.6501,39.7817"},
+		{"", "EMS at 2 OAK AVE", "", "2026-10-19T13:31:00Z", ""},
+		{"dispatch-42", "ALARM", "FIRE ALARM", "2026-10-19T13:30:00Z", "-77.03,38.9"},
+	} {
+		placemark := document.Document.Placemarks[i]
+		var when, point string
+		if placemark.TimeStamp != nil {
+			when = placemark.TimeStamp.When
+		}
+		if placemark.Point != nil {
+			point = placemark.Point.Coordinates
+		}
+		if placemark.ID != want.id || placemark.Name != want.name || placemark.Description != want.description || when != want.when || point != want.point {
+			t.Errorf("placemark %d: got %+v (when %q, point %q), want %+v", i, placemark, when, point, want)
+		}
+	}
+
+	// The properties become extended data, in order and without the empty ones.
+	var names []string
+	data := map[string]string{}
+	for _, d := range document.Document.Placemarks[1].ExtendedData {
+		names = append(names, d.Name)
+		data[d.Name] = d.Value
+	}
+	if !sort.StringsAreSorted(names) || data["location_status"] != LocationMissing || data["kind"] != "notification" {
+		t.Errorf("unexpected extended data: %+v", document.Document.Placemarks[1].ExtendedData)
+	}
+	if _, ok := data["alarm_at"]; ok {
+		t.Errorf("the empty alarm time is in the extended data")
+	}
+}
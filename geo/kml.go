@@ -0,0 +1,109 @@
+package geo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// kmlDocument is a KML 2.2 document.
+type kmlDocument struct {
+	XMLName  xml.Name `xml:"http://www.opengis.net/kml/2.2 kml"`
+	Document struct {
+		Name       string         `xml:"name"`
+		Placemarks []kmlPlacemark `xml:"Placemark"`
+	} `xml:"Document"`
+}
+
+type kmlPlacemark struct {
+	ID           string    `xml:"id,attr,omitempty"`
+	Name         string    `xml:"name"`
+	Description  string    `xml:"description,omitempty"`
+	TimeStamp    *kmlWhen  `xml:"TimeStamp,omitempty"`
+	ExtendedData []kmlData `xml:"ExtendedData>Data"`
+	Point        *kmlPoint `xml:"Point,omitempty"`
+}
+
+type kmlWhen struct {
+	When string `xml:"when"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+// WriteKML writes the collection as a KML document.
+//
+// Each feature becomes a placemark; the properties become its extended data, and the first of its times becomes its
+// time stamp.  Features without a geometry are placemarks without a point.
+func WriteKML(w io.Writer, name string, collection FeatureCollection) error {
+	var document kmlDocument
+	document.Document.Name = name
+	for _, feature := range collection.Features {
+		placemark := kmlPlacemark{Name: feature.Name()}
+		if feature.ID != nil {
+			placemark.ID = fmt.Sprintf("%v-%v", feature.Properties["kind"], feature.ID)
+		}
+		if message, _ := feature.Properties["message"].(string); message != "" {
+			placemark.Description = message
+		}
+		for _, key := range []string{"created_at", "alarm_at", "dispatch_notified_at"} {
+			if when, _ := feature.Properties[key].(string); when != "" {
+				placemark.TimeStamp = &kmlWhen{When: when}
+				break
+			}
+		}
+
+		keys := make([]string, 0, len(feature.Properties))
+		for key := range feature.Properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := kmlValue(feature.Properties[key])
+			if value == "" {
+				continue
+			}
+			placemark.ExtendedData = append(placemark.ExtendedData, kmlData{Name: key, Value: value})
+		}
+
+		if feature.Geometry != nil {
+			placemark.Point = &kmlPoint{Coordinates: strconv.FormatFloat(feature.Geometry.Coordinates[0], 'f', -1, 64) + "," + strconv.FormatFloat(feature.Geometry.Coordinates[1], 'f', -1, 64)}
+		}
+		document.Document.Placemarks = append(document.Document.Placemarks, placemark)
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(document)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// kmlValue formats a property value as text.
+func kmlValue(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []string:
+		return strings.Join(value, ", ")
+	}
+	return fmt.Sprintf("%v", value)
+}
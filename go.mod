@@ -7,6 +7,12 @@ require (
 	github.com/google/go-querystring v1.1.0
 	github.com/kirsle/configdir v0.0.0-20170128060238-e45d2f54772f
 	github.com/tekkamanendless/httperror v1.0.1
+	golang.org/x/term v0.40.0
+	golang.org/x/text v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require github.com/alexflint/go-scalar v1.2.0 // indirect
+require (
+	github.com/alexflint/go-scalar v1.2.0 // indirect
+	golang.org/x/sys v0.41.0 // indirect
+)
@@ -0,0 +1,43 @@
+// Package charset decodes the legacy character sets that CAD systems and mail clients commonly declare.
+package charset
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// encodings are the supported character sets by their lowercase names and aliases.
+//
+// US-ASCII is decoded as Windows-1252, its superset, so that stray high bytes in mislabeled documents still come out
+// as the characters that were most likely meant.
+var encodings = map[string]encoding.Encoding{
+	"iso-8859-1":   charmap.ISO8859_1,
+	"iso8859-1":    charmap.ISO8859_1,
+	"latin1":       charmap.ISO8859_1,
+	"latin-1":      charmap.ISO8859_1,
+	"iso-8859-15":  charmap.ISO8859_15,
+	"latin9":       charmap.ISO8859_15,
+	"windows-1252": charmap.Windows1252,
+	"cp1252":       charmap.Windows1252,
+	"us-ascii":     charmap.Windows1252,
+	"ascii":        charmap.Windows1252,
+}
+
+// NewReader returns a reader that decodes the input from the named character set to UTF-8.
+//
+// It has the signature of xml.Decoder.CharsetReader.  UTF-8 input is returned as is.
+func NewReader(name string, input io.Reader) (io.Reader, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "utf-8" || name == "utf8" {
+		return input, nil
+	}
+	e, ok := encodings[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported charset: %s", name)
+	}
+	return e.NewDecoder().Reader(input), nil
+}
@@ -0,0 +1,360 @@
+// Package mapping converts incident payloads from CAD systems into NFIRS notifications using a declarative mapping.
+//
+// A mapping names the source of each notification field, and optionally the list of apparatuses and the source of
+// each apparatus field.  It is usually written in YAML:
+//
+//	format: json
+//	notification:
+//	  dispatch_number: incident.number
+//	  alarm_at:
+//	    path: incident.times.alarm
+//	    transforms:
+//	      - time: {layout: "01/02/2006 15:04:05", zone: America/Chicago}
+//	  dispatch_type:
+//	    path: incident.type
+//	    transforms:
+//	      - trim
+//	      - upper
+//	      - lookup: {table: {STRUCTURE: FIRE, MEDICAL: EMS}}
+//	    default: OTHER
+//	apparatuses:
+//	  path: units
+//	  fields:
+//	    unit_code: id
+//	    dispatch_at: times.dispatched
+//
+// The targets are the JSON names of the fields of firstdue.NfirsNotification and
+// firstdue.NfirsNotificationApparatus.  The paths of the apparatus fields are relative to each element of the
+// apparatus list.
+//
+// For JSON payloads, a path is a simple JSONPath: a sequence of object keys and array indexes such as
+// "$.incident.units[0].id" (the "$." is optional).  For XML payloads, a path is an XPath expression such as
+// "/Incident/Units/Unit[@primary='true']/Code" or "@id"; see parseXPath for the supported subset.
+//
+// The value at the path goes through the transforms in order; if it is then empty, the default is used.  Finally it
+// is converted to the type of the target field.  Times that have not been parsed by a time transform must be in
+// RFC 3339 format or Unix time.
+package mapping
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+	"gopkg.in/yaml.v3"
+)
+
+// Payload formats.
+const (
+	FormatJSON = "json"
+	FormatXML  = "xml"
+)
+
+// Mapping describes how to build a notification from a payload.
+type Mapping struct {
+	Format       string            `yaml:"format,omitempty" json:"format,omitempty"` // One of the Format* constants; by default, it is detected from the payload.
+	Notification map[string]Field  `yaml:"notification" json:"notification"`
+	Apparatuses  *ApparatusMapping `yaml:"apparatuses,omitempty" json:"apparatuses,omitempty"`
+}
+
+// ApparatusMapping describes how to build the apparatuses of a notification from a payload.
+type ApparatusMapping struct {
+	Path   string           `yaml:"path" json:"path"`     // The path of the list of apparatuses.
+	Fields map[string]Field `yaml:"fields" json:"fields"` // The paths are relative to each element of the list.
+}
+
+// Field describes the source of a single target field.
+//
+// In YAML or JSON, a field may also be written as just its path.
+type Field struct {
+	Path       string      `yaml:"path,omitempty" json:"path,omitempty"`
+	Transforms []Transform `yaml:"transforms,omitempty" json:"transforms,omitempty"`
+	Default    string      `yaml:"default,omitempty" json:"default,omitempty"` // Used when the value is missing, null, or empty after the transforms.
+}
+
+// UnmarshalYAML allows a field to be written as just its path.
+func (f *Field) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		f.Path = node.Value
+		return nil
+	}
+	type plain Field
+	return node.Decode((*plain)(f))
+}
+
+// UnmarshalJSON allows a field to be written as just its path.
+func (f *Field) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		f.Path = path
+		return nil
+	}
+	type plain Field
+	return json.Unmarshal(data, (*plain)(f))
+}
+
+// Load reads a mapping from a YAML or JSON file.
+func Load(path string) (*Mapping, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading mapping: %w", err)
+	}
+	return Parse(contents)
+}
+
+// Parse parses a YAML or JSON mapping and validates it.
+func Parse(contents []byte) (*Mapping, error) {
+	var m Mapping
+	err := yaml.Unmarshal(contents, &m)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing mapping: %w", err)
+	}
+	err = m.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+var (
+	notificationFields = fieldTypes(reflect.TypeOf(firstdue.NfirsNotification{}))
+	apparatusFields    = fieldTypes(reflect.TypeOf(firstdue.NfirsNotificationApparatus{}))
+)
+
+// Validate checks that every target is a field of its type and that every field has a source, and prepares the
+// transforms for use.
+func (m *Mapping) Validate() error {
+	var errs []error
+	switch m.Format {
+	case "", FormatJSON, FormatXML:
+	default:
+		errs = append(errs, fmt.Errorf("format: unsupported format %q", m.Format))
+	}
+	validate := func(prefix string, fields map[string]Field, types map[string]reflect.Type) {
+		for _, name := range sortedKeys(fields) {
+			field := fields[name]
+			if _, ok := types[name]; !ok {
+				errs = append(errs, fmt.Errorf("%s%s: unknown field", prefix, name))
+			}
+			produces := field.Path != "" || field.Default != ""
+			for i := range field.Transforms {
+				transform := &field.Transforms[i]
+				err := transform.compile()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s%s.transforms[%d]: %w", prefix, name, i, err))
+				}
+				if transform.Concat != nil || transform.Default != nil {
+					produces = true
+				}
+			}
+			if !produces {
+				errs = append(errs, fmt.Errorf("%s%s: a path, a default, or a concat transform is required", prefix, name))
+			}
+		}
+	}
+	if _, ok := m.Notification["dispatch_number"]; !ok {
+		errs = append(errs, fmt.Errorf("notification.dispatch_number: the dispatch number must be mapped"))
+	}
+	validate("notification.", m.Notification, notificationFields)
+	if m.Apparatuses != nil {
+		if m.Apparatuses.Path == "" {
+			errs = append(errs, fmt.Errorf("apparatuses.path: the path of the list is required"))
+		}
+		if _, ok := m.Apparatuses.Fields["unit_code"]; !ok {
+			errs = append(errs, fmt.Errorf("apparatuses.fields.unit_code: the unit code must be mapped"))
+		}
+		validate("apparatuses.fields.", m.Apparatuses.Fields, apparatusFields)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid mapping: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Apply builds a notification record from a JSON or XML payload.
+//
+// Every field is attempted, and all of the problems are returned together along with the fields that could be
+// built; use Problems to list them.
+func (m *Mapping) Apply(payload []byte) (firstdue.NfirsNotificationRecord, error) {
+	var record firstdue.NfirsNotificationRecord
+
+	document, err := m.parse(payload)
+	if err != nil {
+		return record, fmt.Errorf("error parsing payload: %w", err)
+	}
+
+	errs := build(document, "notification.", m.Notification, notificationFields, &record.Notification)
+	if m.Apparatuses != nil {
+		items, err := document.list(m.Apparatuses.Path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("apparatuses: %w", err))
+		}
+		for i, item := range items {
+			var apparatus firstdue.NfirsNotificationApparatus
+			errs = append(errs, build(item, fmt.Sprintf("apparatuses[%d].", i), m.Apparatuses.Fields, apparatusFields, &apparatus)...)
+			record.Apparatuses = append(record.Apparatuses, apparatus)
+		}
+	}
+	if len(errs) == 0 {
+		errs = append(errs, Check(record)...)
+	}
+	return record, errors.Join(errs...)
+}
+
+// parse parses the payload in the mapping's format.
+func (m *Mapping) parse(payload []byte) (source, error) {
+	format := m.Format
+	if format == "" {
+		format = FormatJSON
+		if trimmed := bytes.TrimLeft(payload, "\ufeff \t\r\n"); len(trimmed) > 0 && trimmed[0] == '<' {
+			format = FormatXML
+		}
+	}
+	if format == FormatXML {
+		node, err := parseXML(payload)
+		if err != nil {
+			return nil, err
+		}
+		return xmlSource{node: node}, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(payload, []byte("\ufeff"))))
+	decoder.UseNumber()
+	var document any
+	err := decoder.Decode(&document)
+	if err != nil {
+		return nil, err
+	}
+	return jsonSource{document: document}, nil
+}
+
+// Problems returns the individual problems of an error returned by Apply, one per line.
+func Problems(err error) []string {
+	if err == nil {
+		return nil
+	}
+	var problems []string
+	for _, line := range strings.Split(err.Error(), "\n") {
+		if line != "" {
+			problems = append(problems, line)
+		}
+	}
+	return problems
+}
+
+// Check returns the problems with a notification record that would make the API reject it.
+func Check(record firstdue.NfirsNotificationRecord) []error {
+	var errs []error
+	if strings.TrimSpace(record.Notification.DispatchNumber) == "" {
+		errs = append(errs, fmt.Errorf("notification.dispatch_number: the dispatch number is empty"))
+	}
+	if time.Time(record.Notification.AlarmAt).IsZero() {
+		errs = append(errs, fmt.Errorf("notification.alarm_at: the alarm time is empty"))
+	}
+	seen := map[string]bool{}
+	for i, apparatus := range record.Apparatuses {
+		unitCode := strings.ToUpper(strings.TrimSpace(apparatus.UnitCode))
+		switch {
+		case unitCode == "":
+			errs = append(errs, fmt.Errorf("apparatuses[%d].unit_code: the unit code is empty", i))
+		case seen[unitCode]:
+			errs = append(errs, fmt.Errorf("apparatuses[%d].unit_code: unit %s appears more than once", i, apparatus.UnitCode))
+		}
+		seen[unitCode] = true
+	}
+	return errs
+}
+
+// build sets the fields of the target from the source.
+//
+// The fields that can be built are set even if others cannot.  Each problem is prefixed with the prefix and the
+// field's name.
+func build(s source, prefix string, fields map[string]Field, types map[string]reflect.Type, target any) []error {
+	var errs []error
+	values := map[string]any{}
+	for _, name := range sortedKeys(fields) {
+		value, err := fields[name].evaluate(s)
+		if err == nil && isEmpty(value) {
+			continue
+		}
+		if err == nil {
+			value, err = convert(value, types[name])
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %w", prefix, name, err))
+			continue
+		}
+		values[name] = value
+	}
+	contents, err := json.Marshal(values)
+	if err == nil {
+		err = json.Unmarshal(contents, target)
+	}
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", strings.TrimSuffix(prefix, "."), err))
+	}
+	return errs
+}
+
+// evaluate returns the value of the field from the source, before it is converted to the target's type.
+func (f Field) evaluate(s source) (any, error) {
+	var value any
+	if f.Path != "" {
+		var err error
+		value, _, err = s.value(f.Path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for i := range f.Transforms {
+		var err error
+		value, err = f.Transforms[i].apply(value, s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if isEmpty(value) && f.Default != "" {
+		value = f.Default
+	}
+	return value, nil
+}
+
+// isEmpty returns true for missing values, nulls, and blank strings.
+func isEmpty(value any) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(value) == ""
+	}
+	return false
+}
+
+// fieldTypes returns the type of each field of a struct by its JSON name.
+func fieldTypes(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" || name == "id" {
+			continue
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
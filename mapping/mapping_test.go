@@ -0,0 +1,168 @@
+package mapping
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testMapping = `
+notification:
+  dispatch_number: incident.number
+  alarm_at: incident.alarm
+  alarms: incident.alarms
+  latitude: incident.location.lat
+  dispatch_type:
+    path: incident.type
+    transforms:
+      - trim
+      - upper
+    default: OTHER
+apparatuses:
+  path: incident.units
+  fields:
+    unit_code: id
+    dispatch_at: dispatched
+`
+
+func parseMapping(t *testing.T, contents string) *Mapping {
+	t.Helper()
+	m, err := Parse([]byte(contents))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return m
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		contents string
+		want     string
+	}{
+		{"format", "format: csv\nnotification:\n  dispatch_number: n\n", "format: unsupported format"},
+		{"unknown notification field", "notification:\n  dispatch_number: n\n  color: c\n", "notification.color: unknown field"},
+		{"no source", "notification:\n  dispatch_number: {transforms: [trim]}\n", "notification.dispatch_number: a path, a default, or a concat transform is required"},
+		{"no dispatch number", "notification:\n  address: a\n", "notification.dispatch_number: the dispatch number must be mapped"},
+		{"bad transform", "notification:\n  dispatch_number: {path: n, transforms: [{regex: {pattern: '('}}]}\n", "notification.dispatch_number"},
+		{"no apparatus list", "notification:\n  dispatch_number: n\napparatuses:\n  fields:\n    unit_code: id\n", "apparatuses.path: the path of the list is required"},
+		{"no unit code", "notification:\n  dispatch_number: n\napparatuses:\n  path: units\n  fields:\n    dispatch_at: t\n", "apparatuses.fields.unit_code: the unit code must be mapped"},
+		{"unknown apparatus field", "notification:\n  dispatch_number: n\napparatuses:\n  path: units\n  fields:\n    unit_code: id\n    color: c\n", "apparatuses.fields.color: unknown field"},
+		{"unknown transform", "notification:\n  dispatch_number: {path: n, transforms: [lower]}\n", `unknown transform "lower"`},
+	} {
+		_, err := Parse([]byte(test.contents))
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got %v, want %q", test.name, err, test.want)
+		}
+	}
+
+	// A default or a concatenation is enough without a path.
+	parseMapping(t, "notification:\n  dispatch_number: {default: UNKNOWN}\n  address: {transforms: [{concat: {paths: [a, b]}}]}\n")
+}
+
+func TestApply(t *testing.T) {
+	m := parseMapping(t, testMapping)
+	record, err := m.Apply([]byte(`{"incident": {"number": "26-1", "alarm": "2026-10-19T08:30:00Z", "alarms": "2", "type": " fire ",
+		"location": {"lat": 38.9}, "units": [{"id": "E1", "dispatched": 1792398600}, {"id": "L2"}]}}`))
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	n := record.Notification
+	if n.DispatchNumber != "26-1" || n.Alarms != 2 || n.DispatchType != "FIRE" || n.Latitude == nil || *n.Latitude != 38.9 || n.Longitude != nil {
+		t.Errorf("unexpected notification: %+v", n)
+	}
+	if want := time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC); !time.Time(n.AlarmAt).Equal(want) {
+		t.Errorf("got alarm_at %v, want %v", n.AlarmAt, want)
+	}
+	if len(record.Apparatuses) != 2 || record.Apparatuses[1].UnitCode != "L2" || !time.Time(record.Apparatuses[0].DispatchAt).Equal(time.Unix(1792398600, 0)) {
+		t.Errorf("unexpected apparatuses: %+v", record.Apparatuses)
+	}
+
+	// The default is used when the value is missing.
+	record, err = m.Apply([]byte(`{"incident": {"number": "26-2", "alarm": 1792398600}}`))
+	if err != nil || record.Notification.DispatchType != "OTHER" || len(record.Apparatuses) != 0 {
+		t.Errorf("got %+v (%v)", record, err)
+	}
+}
+
+func TestApplyXML(t *testing.T) {
+	m := parseMapping(t, `
+notification:
+  dispatch_number: /Incident/@number
+  alarm_at: /Incident/Times/Alarm
+  address: {path: //Location/Street, transforms: [{concat: {paths: [//Location/City], separator: ", "}}]}
+apparatuses:
+  path: /Incident/Units/Unit
+  fields:
+    unit_code: Code
+    is_aid: "@aid"
+`)
+	record, err := m.Apply([]byte(`<Incident number="26-1"><Times><Alarm>2026-10-19T08:30:00Z</Alarm></Times>
+		<Location><Street>1 Main St</Street><City>Springfield</City></Location>
+		<Units><Unit><Code>E1</Code></Unit><Unit aid="true"><Code>M9</Code></Unit></Units></Incident>`))
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if record.Notification.DispatchNumber != "26-1" || record.Notification.Address != "1 Main St, Springfield" {
+		t.Errorf("unexpected notification: %+v", record.Notification)
+	}
+	if len(record.Apparatuses) != 2 || record.Apparatuses[0].IsAid || !record.Apparatuses[1].IsAid || record.Apparatuses[1].UnitCode != "M9" {
+		t.Errorf("unexpected apparatuses: %+v", record.Apparatuses)
+	}
+}
+
+func TestApplyProblems(t *testing.T) {
+	m := parseMapping(t, testMapping)
+	for _, test := range []struct {
+		name    string
+		payload string
+		want    []string
+	}{
+		{
+			"conversion",
+			`{"incident": {"number": "26-1", "alarm": "yesterday", "alarms": "many", "location": {"lat": "north"}, "units": [{"id": "E1", "dispatched": "soon"}]}}`,
+			[]string{
+				`notification.alarm_at: invalid time "yesterday": expected RFC 3339`,
+				`notification.alarms: invalid integer "many"`,
+				`notification.latitude: invalid number "north"`,
+				`apparatuses[0].dispatch_at: invalid time "soon": expected RFC 3339`,
+			},
+		},
+		{
+			"not a list",
+			`{"incident": {"number": "26-1", "alarm": "2026-10-19T08:30:00Z", "units": {"id": "E1"}}}`,
+			[]string{"apparatuses: incident.units is not a list"},
+		},
+		{
+			"not a value",
+			`{"incident": {"number": {"year": 26}, "alarm": "2026-10-19T08:30:00Z"}}`,
+			[]string{"notification.dispatch_number: expected a value, not an object"},
+		},
+		{
+			"check",
+			`{"incident": {"number": " ", "units": [{"id": "E1"}, {"id": ""}, {"id": "e1"}]}}`,
+			[]string{
+				"notification.dispatch_number: the dispatch number is empty",
+				"notification.alarm_at: the alarm time is empty",
+				"apparatuses[1].unit_code: the unit code is empty",
+				"apparatuses[2].unit_code: unit e1 appears more than once",
+			},
+		},
+	} {
+		_, err := m.Apply([]byte(test.payload))
+		if got := Problems(err); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+
+	if _, err := m.Apply([]byte(`{"incident": `)); err == nil || !strings.HasPrefix(err.Error(), "error parsing payload") {
+		t.Errorf("got %v", err)
+	}
+	if _, err := m.Apply([]byte(`<Incident>`)); err == nil || !strings.HasPrefix(err.Error(), "error parsing payload") {
+		t.Errorf("got %v", err)
+	}
+	if problems := Problems(nil); problems != nil {
+		t.Errorf("got %q", problems)
+	}
+}
@@ -0,0 +1,264 @@
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Transform is a single step that changes a value on its way from the payload to the notification.
+//
+// Exactly one of the fields is set.  In YAML, a transform is written as its name ("trim", "upper") or as a map with a
+// single key:
+//
+//	transforms:
+//	  - trim
+//	  - upper
+//	  - time: {layout: "01/02/2006 15:04:05", zone: America/Chicago}
+//	  - lookup: {table: {STRUC: "111", VEH: "131"}, default: "100"}
+//	  - regex: {pattern: "^Box (\\d+)", group: 1}
+//	  - concat: {paths: [location.city, location.state], separator: ", "}
+//	  - default: UNKNOWN
+type Transform struct {
+	Trim    bool             `yaml:"trim,omitempty" json:"trim,omitempty"`       // Remove the leading and trailing whitespace.
+	Upper   bool             `yaml:"upper,omitempty" json:"upper,omitempty"`     // Convert to upper case.
+	Time    *TimeTransform   `yaml:"time,omitempty" json:"time,omitempty"`       // Parse a time.
+	Lookup  *LookupTransform `yaml:"lookup,omitempty" json:"lookup,omitempty"`   // Replace the value using a table.
+	Regex   *RegexTransform  `yaml:"regex,omitempty" json:"regex,omitempty"`     // Capture part of the value.
+	Concat  *ConcatTransform `yaml:"concat,omitempty" json:"concat,omitempty"`   // Append the values at other paths.
+	Default *string          `yaml:"default,omitempty" json:"default,omitempty"` // Use this if the value is empty.
+}
+
+// TimeTransform parses a time.
+type TimeTransform struct {
+	Layout  string   `yaml:"layout,omitempty" json:"layout,omitempty"`   // A Go time layout or a name such as "RFC3339" or "unix"; defaults to RFC 3339.
+	Layouts []string `yaml:"layouts,omitempty" json:"layouts,omitempty"` // Layouts to try in order, for senders that are not consistent.
+	Zone    string   `yaml:"zone,omitempty" json:"zone,omitempty"`       // The IANA time zone of times without an offset; defaults to UTC.
+
+	location *time.Location
+}
+
+// LookupTransform replaces a value using a table.
+type LookupTransform struct {
+	Table      map[string]string `yaml:"table" json:"table"`
+	Default    *string           `yaml:"default,omitempty" json:"default,omitempty"` // Used for values that are not in the table; otherwise they are left alone.
+	IgnoreCase bool              `yaml:"ignore_case,omitempty" json:"ignore_case,omitempty"`
+}
+
+// RegexTransform replaces a value with part of it.  A value that does not match becomes empty.
+type RegexTransform struct {
+	Pattern string `yaml:"pattern" json:"pattern"`
+	Group   *int   `yaml:"group,omitempty" json:"group,omitempty"` // The capture group to keep; defaults to 1 if the pattern has groups and 0 otherwise.
+
+	regexp *regexp.Regexp
+}
+
+// ConcatTransform appends the values at other paths to the value, skipping the empty ones.
+type ConcatTransform struct {
+	Paths     []string `yaml:"paths" json:"paths"`
+	Separator *string  `yaml:"separator,omitempty" json:"separator,omitempty"` // Defaults to a single space.
+}
+
+// UnmarshalYAML allows the transforms without options to be written as just their names.
+func (t *Transform) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return t.setName(node.Value)
+	}
+	type plain Transform
+	return node.Decode((*plain)(t))
+}
+
+// UnmarshalJSON allows the transforms without options to be written as just their names.
+func (t *Transform) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		return t.setName(name)
+	}
+	type plain Transform
+	return json.Unmarshal(data, (*plain)(t))
+}
+
+func (t *Transform) setName(name string) error {
+	switch name {
+	case "trim":
+		t.Trim = true
+	case "upper":
+		t.Upper = true
+	default:
+		return fmt.Errorf("unknown transform %q", name)
+	}
+	return nil
+}
+
+// timeLayouts are the names that may be used instead of a Go time layout.
+var timeLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+}
+
+// compile checks the transform and prepares it for use.
+func (t *Transform) compile() error {
+	count := 0
+	for _, set := range []bool{t.Trim, t.Upper, t.Time != nil, t.Lookup != nil, t.Regex != nil, t.Concat != nil, t.Default != nil} {
+		if set {
+			count++
+		}
+	}
+	if count != 1 {
+		return fmt.Errorf("a transform must have exactly one of trim, upper, time, lookup, regex, concat, or default")
+	}
+
+	switch {
+	case t.Time != nil:
+		location := time.UTC
+		if t.Time.Zone != "" {
+			var err error
+			location, err = time.LoadLocation(t.Time.Zone)
+			if err != nil {
+				return fmt.Errorf("time: invalid zone %q: %w", t.Time.Zone, err)
+			}
+		}
+		t.Time.location = location
+	case t.Regex != nil:
+		re, err := regexp.Compile(t.Regex.Pattern)
+		if err != nil {
+			return fmt.Errorf("regex: %w", err)
+		}
+		if t.Regex.Group != nil && (*t.Regex.Group < 0 || *t.Regex.Group > re.NumSubexp()) {
+			return fmt.Errorf("regex: the pattern has no group %d", *t.Regex.Group)
+		}
+		t.Regex.regexp = re
+	case t.Concat != nil:
+		if len(t.Concat.Paths) == 0 {
+			return fmt.Errorf("concat: at least one path is required")
+		}
+	}
+	return nil
+}
+
+// apply runs the transform on a value.  The source is used to look up the paths of a concatenation.
+func (t *Transform) apply(value any, s source) (any, error) {
+	switch {
+	case t.Trim:
+		if isEmpty(value) {
+			return value, nil
+		}
+		return strings.TrimSpace(toText(value)), nil
+	case t.Upper:
+		if isEmpty(value) {
+			return value, nil
+		}
+		return strings.ToUpper(toText(value)), nil
+	case t.Default != nil:
+		if isEmpty(value) {
+			return *t.Default, nil
+		}
+		return value, nil
+	case t.Time != nil:
+		if isEmpty(value) {
+			return value, nil
+		}
+		return t.Time.parse(value)
+	case t.Lookup != nil:
+		if isEmpty(value) {
+			return value, nil
+		}
+		text := strings.TrimSpace(toText(value))
+		if replacement, ok := t.Lookup.Table[text]; ok {
+			return replacement, nil
+		}
+		if t.Lookup.IgnoreCase {
+			for _, key := range sortedKeys(t.Lookup.Table) {
+				if strings.EqualFold(key, text) {
+					return t.Lookup.Table[key], nil
+				}
+			}
+		}
+		if t.Lookup.Default != nil {
+			return *t.Lookup.Default, nil
+		}
+		return value, nil
+	case t.Regex != nil:
+		if isEmpty(value) {
+			return value, nil
+		}
+		group := 0
+		if t.Regex.Group != nil {
+			group = *t.Regex.Group
+		} else if t.Regex.regexp.NumSubexp() > 0 {
+			group = 1
+		}
+		match := t.Regex.regexp.FindStringSubmatch(toText(value))
+		if match == nil {
+			return nil, nil
+		}
+		return match[group], nil
+	case t.Concat != nil:
+		separator := " "
+		if t.Concat.Separator != nil {
+			separator = *t.Concat.Separator
+		}
+		var parts []string
+		if !isEmpty(value) {
+			parts = append(parts, toText(value))
+		}
+		for _, path := range t.Concat.Paths {
+			other, _, err := s.value(path)
+			if err != nil {
+				return nil, err
+			}
+			if !isEmpty(other) {
+				parts = append(parts, toText(other))
+			}
+		}
+		if len(parts) == 0 {
+			return nil, nil
+		}
+		return strings.Join(parts, separator), nil
+	}
+	return value, nil
+}
+
+// parse parses a time with each of the layouts in turn.
+func (t *TimeTransform) parse(value any) (any, error) {
+	text := strings.TrimSpace(toText(value))
+	layouts := t.Layouts
+	if t.Layout != "" {
+		layouts = append([]string{t.Layout}, layouts...)
+	}
+	if len(layouts) == 0 {
+		layouts = []string{time.RFC3339}
+	}
+	for _, layout := range layouts {
+		switch layout {
+		case "unix", "unix_ms":
+			number, err := strconv.ParseInt(text, 10, 64)
+			if err != nil {
+				continue
+			}
+			if layout == "unix_ms" {
+				return time.UnixMilli(number).In(t.location), nil
+			}
+			return time.Unix(number, 0).In(t.location), nil
+		}
+		if named, ok := timeLayouts[layout]; ok {
+			layout = named
+		}
+		parsed, err := time.ParseInLocation(layout, text, t.location)
+		if err == nil {
+			return parsed, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid time %q: expected %s", text, strings.Join(layouts, " or "))
+}
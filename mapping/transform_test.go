@@ -0,0 +1,158 @@
+package mapping
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseTransform parses and compiles a transform written in YAML.
+func parseTransform(text string) (*Transform, error) {
+	var transform Transform
+	if err := yaml.Unmarshal([]byte(text), &transform); err != nil {
+		return nil, err
+	}
+	if err := transform.compile(); err != nil {
+		return nil, err
+	}
+	return &transform, nil
+}
+
+func TestLookup(t *testing.T) {
+	decoder := json.NewDecoder(strings.NewReader(`{"incident": {"number": "26-1", "type": null, "units": [{"id": "E1"}, {"id": "L2"}], "alarms": 2}}`))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		path  string
+		want  any
+		found bool
+	}{
+		{"incident.number", "26-1", true},
+		{"$.incident.number", "26-1", true},
+		{"incident.units[1].id", "L2", true},
+		{"$.incident.units.0.id", "E1", true},
+		{"incident['units'][0]['id']", "E1", true},
+		{`incident["alarms"]`, json.Number("2"), true},
+		{"incident.type", nil, true},
+		{"incident.units[2].id", nil, false},
+		{"incident.units[-1]", nil, false},
+		{"incident.units.first", nil, false},
+		{"incident.number.digits", nil, false},
+		{"incident.missing", nil, false},
+	} {
+		got, found := Lookup(document, test.path)
+		if found != test.found || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %#v, %t; want %#v, %t", test.path, got, found, test.want, test.found)
+		}
+	}
+	if got, found := Lookup(document, "$"); !found || !reflect.DeepEqual(got, document) {
+		t.Errorf("$: got %#v", got)
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+	s := jsonSource{document: map[string]any{"city": "Springfield", "state": "IL", "blank": " "}}
+
+	for _, test := range []struct {
+		transform string
+		value     any
+		want      any
+	}{
+		{"trim", "  E1 ", "E1"},
+		{"trim", nil, nil},
+		{"upper", "e1", "E1"},
+		{"upper", json.Number("12"), "12"},
+		{"default: OTHER", "", "OTHER"},
+		{"default: OTHER", "FIRE", "FIRE"},
+		{`time: {layout: "01/02/2006 15:04:05", zone: America/Chicago}`, "10/19/2026 08:30:00", time.Date(2026, 10, 19, 8, 30, 0, 0, chicago)},
+		{`time: {layouts: ["2006-01-02 15:04", "01/02/2006 15:04"]}`, "10/19/2026 08:30", time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)},
+		{"time: {layout: DateTime}", "2026-10-19 08:30:00", time.Date(2026, 10, 19, 8, 30, 0, 0, time.UTC)},
+		{"time: {}", "2026-10-19T08:30:00-05:00", time.Date(2026, 10, 19, 13, 30, 0, 0, time.UTC)},
+		{"time: {layout: unix}", json.Number("1792398600"), time.Unix(1792398600, 0).UTC()},
+		{"time: {layout: unix_ms}", "1792398600500", time.UnixMilli(1792398600500).UTC()},
+		{"time: {layout: unix}", "", ""},
+		{`lookup: {table: {STRUC: "111"}}`, "STRUC", "111"},
+		{`lookup: {table: {STRUC: "111"}}`, " STRUC ", "111"},
+		{`lookup: {table: {STRUC: "111"}}`, "VEH", "VEH"},
+		{`lookup: {table: {STRUC: "111"}}`, "struc", "struc"},
+		{`lookup: {table: {STRUC: "111"}, ignore_case: true}`, "struc", "111"},
+		{`lookup: {table: {STRUC: "111"}, default: "100"}`, "VEH", "100"},
+		{`regex: {pattern: "^Box (\\d+)"}`, "Box 42 North", "42"},
+		{`regex: {pattern: "^Box (\\d+)", group: 0}`, "Box 42 North", "Box 42"},
+		{`regex: {pattern: "\\d+"}`, "Box 42", "42"},
+		{`regex: {pattern: "^Box (\\d+)"}`, "Station 7", nil},
+		{"concat: {paths: [city, state]}", "1 Main St", "1 Main St Springfield IL"},
+		{`concat: {paths: [city, missing, state], separator: ", "}`, nil, "Springfield, IL"},
+		{"concat: {paths: [blank, missing]}", "", nil},
+	} {
+		transform, err := parseTransform(test.transform)
+		if err != nil {
+			t.Errorf("%s: %v", test.transform, err)
+			continue
+		}
+		got, err := transform.apply(test.value, s)
+		if err != nil {
+			t.Errorf("%s: %v: %v", test.transform, test.value, err)
+			continue
+		}
+		if gotTime, ok := got.(time.Time); ok {
+			if wantTime, ok := test.want.(time.Time); !ok || !gotTime.Equal(wantTime) {
+				t.Errorf("%s: %v: got %v, want %v", test.transform, test.value, got, test.want)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: %v: got %#v, want %#v", test.transform, test.value, got, test.want)
+		}
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	for _, test := range []struct {
+		transform string
+		want      string
+	}{
+		{"lowercase", `unknown transform "lowercase"`},
+		{"{}", "exactly one of"},
+		{"{trim: true, upper: true}", "exactly one of"},
+		{"time: {zone: Nowhere/Special}", "time: invalid zone"},
+		{`regex: {pattern: "("}`, "regex: error parsing regexp"},
+		{`regex: {pattern: "(\\d+)", group: 2}`, "regex: the pattern has no group 2"},
+		{`regex: {pattern: "\\d+", group: -1}`, "regex: the pattern has no group -1"},
+		{"concat: {paths: []}", "concat: at least one path is required"},
+	} {
+		if _, err := parseTransform(test.transform); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got %v, want %q", test.transform, err, test.want)
+		}
+	}
+
+	for _, value := range []any{"yesterday", "10/19/2026", json.Number("1.5")} {
+		transform, err := parseTransform(`time: {layouts: ["01/02/2006 15:04", unix]}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := transform.apply(value, nil); err == nil || !strings.Contains(err.Error(), "expected 01/02/2006 15:04 or unix") {
+			t.Errorf("%v: got %v", value, err)
+		}
+	}
+
+	// The name of a transform is accepted in JSON too.
+	var transforms []Transform
+	if err := json.Unmarshal([]byte(`["upper", {"default": "X"}]`), &transforms); err != nil || !transforms[0].Upper || *transforms[1].Default != "X" {
+		t.Errorf("got %+v (%v)", transforms, err)
+	}
+	if err := json.Unmarshal([]byte(`["lower"]`), &transforms); err == nil {
+		t.Errorf("expected an error for an unknown transform")
+	}
+}
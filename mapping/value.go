@@ -0,0 +1,202 @@
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// source is a parsed payload that paths are evaluated against.
+type source interface {
+	// value returns the value at the path, and whether it exists.
+	value(path string) (any, bool, error)
+	// list returns the elements at the path, each as a source for relative paths.
+	list(path string) ([]source, error)
+}
+
+// jsonSource is a JSON payload decoded with json.Number for numbers; its paths are simple JSONPaths.
+type jsonSource struct {
+	document any
+}
+
+func (s jsonSource) value(path string) (any, bool, error) {
+	value, ok := Lookup(s.document, path)
+	return value, ok, nil
+}
+
+func (s jsonSource) list(path string) ([]source, error) {
+	value, ok := Lookup(s.document, path)
+	if !ok || value == nil {
+		return nil, nil
+	}
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a list", path)
+	}
+	var sources []source
+	for _, item := range items {
+		sources = append(sources, jsonSource{document: item})
+	}
+	return sources, nil
+}
+
+// Lookup returns the value at the path in a decoded JSON document, and whether it exists.
+//
+// The path is a sequence of object keys and array indexes separated by dots, such as "incident.units[0].id" or
+// "incident.units.0.id".  A leading "$" or "$." is ignored.
+func Lookup(document any, path string) (any, bool) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return document, true
+	}
+	current := document
+	for _, part := range splitPath(path) {
+		switch value := current.(type) {
+		case map[string]any:
+			next, ok := value[part]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil, false
+			}
+			current = value[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// splitPath splits a path into its keys and indexes.
+func splitPath(path string) []string {
+	var parts []string
+	for _, part := range strings.Split(path, ".") {
+		for {
+			open := strings.Index(part, "[")
+			if open < 0 {
+				break
+			}
+			close := strings.Index(part[open:], "]")
+			if close < 0 {
+				break
+			}
+			if open > 0 {
+				parts = append(parts, part[:open])
+			}
+			parts = append(parts, strings.Trim(part[open+1:open+close], `"'`))
+			part = part[open+close+1:]
+		}
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+var (
+	timestampType     = reflect.TypeOf(firstdue.Timestamp{})
+	stringFloat64Type = reflect.TypeOf(firstdue.StringFloat64(0))
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+)
+
+// convert converts a decoded JSON value into a value that encodes as JSON for the target type.
+func convert(value any, t reflect.Type) (any, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	text := toText(value)
+
+	if parsed, ok := value.(time.Time); ok {
+		switch {
+		case t == timestampType:
+			return firstdue.Timestamp(parsed), nil
+		case t.Kind() == reflect.String:
+			return parsed.Format(time.RFC3339), nil
+		}
+		return nil, fmt.Errorf("a time cannot be used for a %s field", t)
+	}
+
+	switch {
+	case t == timestampType:
+		if number, ok := value.(json.Number); ok {
+			seconds, err := number.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("invalid Unix time %s", number)
+			}
+			return firstdue.Timestamp(time.Unix(seconds, 0).UTC()), nil
+		}
+		if seconds, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64); err == nil {
+			return firstdue.Timestamp(time.Unix(seconds, 0).UTC()), nil
+		}
+		parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q: expected RFC 3339", text)
+		}
+		return firstdue.Timestamp(parsed), nil
+	case t == stringFloat64Type:
+		number, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", text)
+		}
+		return firstdue.StringFloat64(number), nil
+	case t == rawMessageType:
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if _, ok := value.(map[string]any); ok {
+			return nil, fmt.Errorf("expected a value, not an object")
+		}
+		if _, ok := value.([]any); ok {
+			return nil, fmt.Errorf("expected a value, not a list")
+		}
+		return text, nil
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", text)
+		}
+		return b, nil
+	case reflect.Int, reflect.Int64, reflect.Uint64:
+		number, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", text)
+		}
+		return number, nil
+	case reflect.Float64:
+		number, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", text)
+		}
+		return number, nil
+	}
+	return nil, fmt.Errorf("unsupported field type %s", t)
+}
+
+// toText returns the text form of a scalar value.
+func toText(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	case nil:
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,367 @@
+package mapping
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tekkamanendless/firstdue/internal/charset"
+)
+
+// xmlNode is an element, attribute, or text node of a parsed XML document.
+//
+// Names are local names; namespaces are ignored, so "nena:Incident" and "Incident" are the same element.
+type xmlNode struct {
+	name     string // The local name; "@name" for attributes; empty for the document and text nodes.
+	text     string // The character data directly inside the node, or the value of an attribute or text node.
+	attrs    []xml.Attr
+	children []*xmlNode
+	parent   *xmlNode
+}
+
+// parseXML parses an XML document and returns its document node, whose only child is the root element.
+func parseXML(payload []byte) (*xmlNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(payload))
+	decoder.CharsetReader = charset.NewReader
+	document := &xmlNode{}
+	current := document
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: token.Name.Local, attrs: token.Attr, parent: current}
+			current.children = append(current.children, node)
+			current = node
+		case xml.EndElement:
+			current = current.parent
+		case xml.CharData:
+			current.text += string(token)
+		}
+	}
+	if len(document.children) == 0 {
+		return nil, fmt.Errorf("the document has no root element")
+	}
+	return document, nil
+}
+
+// content returns the text of the node and all of its descendants, trimmed.
+func (n *xmlNode) content() string {
+	if len(n.children) == 0 {
+		return strings.TrimSpace(n.text)
+	}
+	var builder strings.Builder
+	var walk func(*xmlNode)
+	walk = func(node *xmlNode) {
+		builder.WriteString(node.text)
+		for _, child := range node.children {
+			walk(child)
+		}
+	}
+	walk(n)
+	return strings.TrimSpace(builder.String())
+}
+
+func (n *xmlNode) root() *xmlNode {
+	for n.parent != nil {
+		n = n.parent
+	}
+	return n
+}
+
+// xpathStep is a single location step of an XPath expression.
+type xpathStep struct {
+	axis       string // One of "child", "descendant", "self", "parent", "attribute", or "text".
+	name       string // The local name, or "*".
+	predicates []string
+}
+
+// parseXPath parses the supported subset of XPath: absolute and relative location paths made of element names, "*",
+// ".", "..", "//", "@attribute", and "text()" steps, with predicates that are a position ("[1]", "[last()]"), an
+// existence test ("[@type]", "[Code]"), or an equality test against a string literal ("[@type='Fire']",
+// "[Code='E1']").
+func parseXPath(path string) (bool, []xpathStep, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return false, nil, fmt.Errorf("the path is empty")
+	}
+	absolute := strings.HasPrefix(path, "/")
+	var steps []xpathStep
+	i := 0
+	for i < len(path) {
+		axis := "child"
+		if strings.HasPrefix(path[i:], "//") {
+			axis = "descendant"
+			i += 2
+		} else if path[i] == '/' {
+			i++
+		}
+		if i >= len(path) {
+			if axis == "descendant" || len(steps) > 0 {
+				return false, nil, fmt.Errorf("the path %q ends with a slash", path)
+			}
+			break // Just "/", the document itself.
+		}
+
+		// Read the step up to the next slash that is not inside a predicate.
+		start := i
+		depth := 0
+		var quote byte
+		for i < len(path) {
+			c := path[i]
+			switch {
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case c == '\'' || c == '"':
+				quote = c
+			case c == '[':
+				depth++
+			case c == ']':
+				depth--
+			case c == '/' && depth == 0:
+				goto done
+			}
+			i++
+		}
+	done:
+		if quote != 0 || depth != 0 {
+			return false, nil, fmt.Errorf("the path %q has an unterminated predicate", path)
+		}
+		step, err := parseXPathStep(path[start:i])
+		if err != nil {
+			return false, nil, fmt.Errorf("the path %q is not supported: %w", path, err)
+		}
+		if axis == "descendant" {
+			if step.axis != "child" {
+				// "//@id" and "//text()" search the descendants and then take the step from each.
+				steps = append(steps, xpathStep{axis: "descendant-or-self", name: "*"})
+			} else {
+				step.axis = "descendant"
+			}
+		}
+		steps = append(steps, step)
+	}
+	return absolute, steps, nil
+}
+
+func parseXPathStep(text string) (xpathStep, error) {
+	var step xpathStep
+	name := text
+	if open := strings.Index(text, "["); open >= 0 {
+		name = text[:open]
+		rest := text[open:]
+		for rest != "" {
+			if rest[0] != '[' {
+				return step, fmt.Errorf("unexpected %q", rest)
+			}
+			end := -1
+			var quote byte
+			for j := 1; j < len(rest) && end < 0; j++ {
+				switch c := rest[j]; {
+				case quote != 0:
+					if c == quote {
+						quote = 0
+					}
+				case c == '\'' || c == '"':
+					quote = c
+				case c == ']':
+					end = j
+				}
+			}
+			if end < 0 {
+				return step, fmt.Errorf("unterminated predicate")
+			}
+			step.predicates = append(step.predicates, strings.TrimSpace(rest[1:end]))
+			rest = rest[end+1:]
+		}
+	}
+	name = strings.TrimSpace(name)
+	switch {
+	case name == ".":
+		step.axis = "self"
+	case name == "..":
+		step.axis = "parent"
+	case name == "text()":
+		step.axis = "text"
+	case strings.HasPrefix(name, "@"):
+		step.axis = "attribute"
+		step.name = localName(name[1:])
+	case name == "":
+		return step, fmt.Errorf("empty step")
+	case strings.ContainsAny(name, "()=<>|/"):
+		return step, fmt.Errorf("unsupported step %q", name)
+	default:
+		step.axis = "child"
+		step.name = localName(name)
+	}
+	return step, nil
+}
+
+// localName strips the namespace prefix from a name.
+func localName(name string) string {
+	if _, local, ok := strings.Cut(name, ":"); ok {
+		return local
+	}
+	return name
+}
+
+// selectXPath returns the nodes selected by the path from the context node.
+func selectXPath(context *xmlNode, path string) ([]*xmlNode, error) {
+	absolute, steps, err := parseXPath(path)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []*xmlNode{context}
+	if absolute {
+		nodes = []*xmlNode{context.root()}
+	}
+	for _, step := range steps {
+		var next []*xmlNode
+		seen := map[*xmlNode]bool{}
+		for _, node := range nodes {
+			candidates := step.candidates(node)
+			for _, predicate := range step.predicates {
+				candidates, err = filterXPath(candidates, predicate)
+				if err != nil {
+					return nil, fmt.Errorf("the path %q is not supported: %w", path, err)
+				}
+			}
+			for _, candidate := range candidates {
+				if !seen[candidate] {
+					seen[candidate] = true
+					next = append(next, candidate)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes, nil
+}
+
+func (s xpathStep) candidates(node *xmlNode) []*xmlNode {
+	matches := func(n *xmlNode) bool {
+		return n.name != "" && !strings.HasPrefix(n.name, "@") && (s.name == "*" || n.name == s.name)
+	}
+	var candidates []*xmlNode
+	switch s.axis {
+	case "self":
+		candidates = append(candidates, node)
+	case "parent":
+		if node.parent != nil {
+			candidates = append(candidates, node.parent)
+		}
+	case "child":
+		for _, child := range node.children {
+			if matches(child) {
+				candidates = append(candidates, child)
+			}
+		}
+	case "descendant", "descendant-or-self":
+		var walk func(*xmlNode)
+		walk = func(n *xmlNode) {
+			for _, child := range n.children {
+				if matches(child) {
+					candidates = append(candidates, child)
+				}
+				walk(child)
+			}
+		}
+		if s.axis == "descendant-or-self" {
+			candidates = append(candidates, node)
+		}
+		walk(node)
+	case "attribute":
+		for _, attr := range node.attrs {
+			if s.name == "*" || attr.Name.Local == s.name {
+				candidates = append(candidates, &xmlNode{name: "@" + attr.Name.Local, text: attr.Value, parent: node})
+			}
+		}
+	case "text":
+		if strings.TrimSpace(node.text) != "" {
+			candidates = append(candidates, &xmlNode{text: node.text, parent: node})
+		}
+	}
+	return candidates
+}
+
+// filterXPath applies a predicate to the candidates of a step.
+func filterXPath(candidates []*xmlNode, predicate string) ([]*xmlNode, error) {
+	if predicate == "last()" {
+		if len(candidates) == 0 {
+			return nil, nil
+		}
+		return candidates[len(candidates)-1:], nil
+	}
+	if position, err := strconv.Atoi(predicate); err == nil {
+		if position < 1 || position > len(candidates) {
+			return nil, nil
+		}
+		return candidates[position-1 : position], nil
+	}
+
+	left, right, equality := strings.Cut(predicate, "=")
+	left = strings.TrimSpace(left)
+	var literal string
+	if equality {
+		right = strings.TrimSpace(right)
+		if len(right) < 2 || (right[0] != '\'' && right[0] != '"') || right[len(right)-1] != right[0] {
+			return nil, fmt.Errorf("unsupported predicate [%s]: the value must be a quoted string", predicate)
+		}
+		literal = right[1 : len(right)-1]
+	}
+	step, err := parseXPathStep(left)
+	if err != nil || (step.axis != "child" && step.axis != "attribute") || len(step.predicates) > 0 {
+		return nil, fmt.Errorf("unsupported predicate [%s]", predicate)
+	}
+
+	var filtered []*xmlNode
+	for _, candidate := range candidates {
+		for _, match := range step.candidates(candidate) {
+			if !equality || match.content() == literal {
+				filtered = append(filtered, candidate)
+				break
+			}
+		}
+	}
+	return filtered, nil
+}
+
+// xmlSource is an XML payload; its paths are XPath expressions.
+type xmlSource struct {
+	node *xmlNode
+}
+
+func (s xmlSource) value(path string) (any, bool, error) {
+	nodes, err := selectXPath(s.node, path)
+	if err != nil || len(nodes) == 0 {
+		return nil, false, err
+	}
+	text := nodes[0].content()
+	if !utf8.ValidString(text) {
+		return nil, false, fmt.Errorf("the value at %s is not valid UTF-8", path)
+	}
+	return text, true, nil
+}
+
+func (s xmlSource) list(path string) ([]source, error) {
+	nodes, err := selectXPath(s.node, path)
+	if err != nil {
+		return nil, err
+	}
+	var sources []source
+	for _, node := range nodes {
+		sources = append(sources, xmlSource{node: node})
+	}
+	return sources, nil
+}
@@ -0,0 +1,143 @@
+package mapping
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testXML = `<?xml version="1.0"?>
+<Incident id="I1" xmlns:n="urn:example">
+  <n:Number>26-1</n:Number>
+  <Units>
+    <Unit primary="true"><Code>E1</Code></Unit>
+    <Unit><Code>L2</Code></Unit>
+    <Unit type="aid"><Code>M3</Code></Unit>
+  </Units>
+  <Notes> smoke showing </Notes>
+</Incident>`
+
+// contents returns the content of each node.
+func contents(nodes []*xmlNode) []string {
+	var values []string
+	for _, node := range nodes {
+		values = append(values, node.content())
+	}
+	return values
+}
+
+func TestParseXPath(t *testing.T) {
+	for _, test := range []struct {
+		path     string
+		absolute bool
+		axes     []string
+	}{
+		{"/Incident/Units", true, []string{"child", "child"}},
+		{"Units/Unit", false, []string{"child", "child"}},
+		{"//Code", true, []string{"descendant"}},
+		{"Units//Code", false, []string{"child", "descendant"}},
+		{"//@id", true, []string{"descendant-or-self", "attribute"}},
+		{"./Code/text()", false, []string{"self", "child", "text"}},
+		{"../Unit[@type='a/b'][1]", false, []string{"parent", "child"}},
+		{"/", true, nil},
+	} {
+		absolute, steps, err := parseXPath(test.path)
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+			continue
+		}
+		var axes []string
+		for _, step := range steps {
+			axes = append(axes, step.axis)
+		}
+		if absolute != test.absolute || !reflect.DeepEqual(axes, test.axes) {
+			t.Errorf("%s: got absolute %t and axes %v, want %t and %v", test.path, absolute, axes, test.absolute, test.axes)
+		}
+	}
+}
+
+func TestSelectXPath(t *testing.T) {
+	document, err := parseXML([]byte(testXML))
+	if err != nil {
+		t.Fatalf("parseXML: %v", err)
+	}
+	for _, test := range []struct {
+		path string
+		want []string
+	}{
+		{"/Incident/Number", []string{"26-1"}},
+		{"/Incident/n:Number", []string{"26-1"}},
+		{"/Incident/@id", []string{"I1"}},
+		{"//Code", []string{"E1", "L2", "M3"}},
+		{"//@primary", []string{"true"}},
+		{"/Incident/Units/Unit[2]/Code", []string{"L2"}},
+		{"/Incident/Units/Unit[last()]/Code", []string{"M3"}},
+		{"/Incident/Units/Unit[5]/Code", nil},
+		{"/Incident/Units/*[1]/Code", []string{"E1"}},
+		{"/Incident/Units/Unit[@primary='true']/Code", []string{"E1"}},
+		{`//Unit[@type="aid"]/Code`, []string{"M3"}},
+		{"//Unit[@type]/Code", []string{"M3"}},
+		{"//Unit[Code='L2']", []string{"L2"}},
+		{"//Unit[Code='X9']", nil},
+		{"/Incident/Notes", []string{"smoke showing"}},
+		{"/Incident/Notes/text()", []string{"smoke showing"}},
+		{"/Incident/Units/text()", nil},
+		{"/Incident/Missing", nil},
+	} {
+		nodes, err := selectXPath(document, test.path)
+		if err != nil {
+			t.Errorf("%s: %v", test.path, err)
+			continue
+		}
+		if got := contents(nodes); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %q, want %q", test.path, got, test.want)
+		}
+	}
+
+	// Relative paths are evaluated from the context node, and absolute ones from the document.
+	units, err := selectXPath(document, "/Incident/Units/Unit")
+	if err != nil || len(units) != 3 {
+		t.Fatalf("got %d units (%v)", len(units), err)
+	}
+	for path, want := range map[string]string{
+		"Code":              "E1",
+		"./Code":            "E1",
+		"@primary":          "true",
+		"../Unit[3]/Code":   "M3",
+		"/Incident/@id":     "I1",
+		"../../n:Number":    "26-1",
+		"Code/text()":       "E1",
+		"../Unit[2]/*[1]":   "L2",
+		"//Unit[1]/Code[1]": "E1",
+	} {
+		nodes, err := selectXPath(units[0], path)
+		if err != nil || len(nodes) == 0 || nodes[0].content() != want {
+			t.Errorf("%s: got %q (%v), want %q", path, contents(nodes), err, want)
+		}
+	}
+}
+
+func TestSelectXPathErrors(t *testing.T) {
+	document, err := parseXML([]byte(testXML))
+	if err != nil {
+		t.Fatalf("parseXML: %v", err)
+	}
+	for _, path := range []string{
+		"",
+		"/Incident/",
+		"//",
+		"/Incident[@id='I1'",
+		"/Incident[@id='I1]",
+		"/Incident]",
+		"/Incident/count(Units)",
+		"/Incident[position()=1]",
+		"/Incident[@id=I1]",
+		"/Incident[Units/Unit]",
+	} {
+		if nodes, err := selectXPath(document, path); err == nil {
+			t.Errorf("%q: expected an error, got %q", path, contents(nodes))
+		}
+	}
+	if _, err := parseXML([]byte("<!-- nothing -->")); err == nil {
+		t.Errorf("expected an error for a document without a root element")
+	}
+}
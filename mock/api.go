@@ -0,0 +1,396 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// defaultPerPage is the page size when the request does not give one.
+const defaultPerPage = 20
+
+// registerAPI adds the API endpoints to the mux.
+func (s *Server) registerAPI(mux *http.ServeMux) {
+	mux.HandleFunc("POST /v1/auth/token", s.postAuthToken)
+	mux.HandleFunc("GET /v1/stations", s.authenticated(s.getStations))
+	mux.HandleFunc("GET /v1/apparatuses", s.authenticated(s.getApparatuses))
+	mux.HandleFunc("GET /v1/dispatches", s.authenticated(s.getDispatches))
+	mux.HandleFunc("POST /v1/dispatches", s.authenticated(s.postDispatch))
+	mux.HandleFunc("GET /v1/logs/settings", s.authenticated(s.getLogsSettings))
+	mux.HandleFunc("POST /v1/logs", s.authenticated(s.postLogs))
+	mux.HandleFunc("POST /v1/logs/batch", s.authenticated(s.postLogsBatch))
+
+	mux.HandleFunc("POST /v1/nfirs-notifications", s.authenticated(s.postNotification))
+	mux.HandleFunc("GET /v1/nfirs-notifications/{id}", s.authenticated(s.byID(s.getNotification)))
+	mux.HandleFunc("PUT /v1/nfirs-notifications/{id}", s.authenticated(s.byID(s.putNotification)))
+	mux.HandleFunc("DELETE /v1/nfirs-notifications/{id}", s.authenticated(s.byID(s.deleteNotification)))
+	mux.HandleFunc("GET /v1/nfirs-notifications/dispatch-number/{number}", s.authenticated(s.byNumber(s.getNotification)))
+	mux.HandleFunc("PUT /v1/nfirs-notifications/number/{number}", s.authenticated(s.byNumber(s.putNotification)))
+	mux.HandleFunc("DELETE /v1/nfirs-notifications/number/{number}", s.authenticated(s.byNumber(s.deleteNotification)))
+
+	mux.HandleFunc("POST /v1/nfirs-notifications/{id}/apparatuses", s.authenticated(s.byID(s.postApparatus)))
+	mux.HandleFunc("PUT /v1/nfirs-notifications/{id}/apparatuses/{apparatus}", s.authenticated(s.byID(s.putApparatus(false))))
+	mux.HandleFunc("DELETE /v1/nfirs-notifications/{id}/apparatuses/{apparatus}", s.authenticated(s.byID(s.deleteApparatus(false))))
+	mux.HandleFunc("POST /v1/nfirs-notifications/number/{number}/apparatuses", s.authenticated(s.byNumber(s.postApparatus)))
+	mux.HandleFunc("PUT /v1/nfirs-notifications/number/{number}/apparatuses/code/{apparatus}", s.authenticated(s.byNumber(s.putApparatus(true))))
+	mux.HandleFunc("DELETE /v1/nfirs-notifications/number/{number}/apparatuses/code/{apparatus}", s.authenticated(s.byNumber(s.deleteApparatus(true))))
+
+	mux.HandleFunc("GET /v1/hydrants", s.authenticated(s.getHydrants))
+	mux.HandleFunc("POST /v1/hydrants", s.authenticated(s.postHydrant))
+	mux.HandleFunc("GET /v1/hydrants/{id}", s.authenticated(s.byHydrantID(s.getHydrant)))
+	mux.HandleFunc("PUT /v1/hydrants/{id}", s.authenticated(s.byHydrantID(s.putHydrant)))
+	mux.HandleFunc("GET /v1/hydrants/{id}/flow-tests", s.authenticated(s.byHydrantID(s.getFlowTests)))
+	mux.HandleFunc("POST /v1/hydrants/{id}/flow-tests", s.authenticated(s.byHydrantID(s.postFlowTest)))
+	mux.HandleFunc("PUT /v1/hydrants/{id}/flow-tests/{flow_test}", s.authenticated(s.byHydrantID(s.putFlowTest)))
+
+	mux.HandleFunc("GET /v1/occupancies", s.authenticated(s.getOccupancies))
+	mux.HandleFunc("POST /v1/occupancies", s.authenticated(s.postOccupancy))
+	mux.HandleFunc("GET /v1/occupancies/{id}", s.authenticated(s.byOccupancyID(s.getOccupancy)))
+	mux.HandleFunc("PUT /v1/occupancies/{id}", s.authenticated(s.byOccupancyID(s.putOccupancy)))
+	mux.HandleFunc("DELETE /v1/occupancies/{id}", s.authenticated(s.byOccupancyID(s.deleteOccupancy)))
+
+	mux.HandleFunc("GET /v1/inspections", s.authenticated(s.getInspections))
+	mux.HandleFunc("POST /v1/inspections", s.authenticated(s.postInspection))
+	mux.HandleFunc("GET /v1/inspections/{id}", s.authenticated(s.byInspectionID(s.getInspection)))
+	mux.HandleFunc("PUT /v1/inspections/{id}", s.authenticated(s.byInspectionID(s.putInspection)))
+}
+
+// authenticated rejects requests without a bearer token.  Any token is accepted.
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			writeError(w, http.StatusUnauthorized, "Your request was made with invalid credentials.")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// notificationHandler handles a request for a single notification; the caller holds the lock.
+type notificationHandler func(w http.ResponseWriter, r *http.Request, notification *storedNotification)
+
+// byID finds the notification by the "id" path parameter.
+func (s *Server) byID(next notificationHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "Notification not found.")
+			return
+		}
+		s.withNotification(w, r, next, func(n *storedNotification) bool {
+			return n.Notification.ID == id
+		})
+	}
+}
+
+// byNumber finds the notification by the "number" path parameter, which is the dispatch number.
+func (s *Server) byNumber(next notificationHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		number := r.PathValue("number")
+		s.withNotification(w, r, next, func(n *storedNotification) bool {
+			return n.Notification.DispatchNumber == number
+		})
+	}
+}
+
+func (s *Server) withNotification(w http.ResponseWriter, r *http.Request, next notificationHandler, match func(*storedNotification) bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, notification := range s.notifications {
+		if match(notification) {
+			next(w, r, notification)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Notification not found.")
+}
+
+func (s *Server) postAuthToken(w http.ResponseWriter, r *http.Request) {
+	var input firstdue.PostAuthTokenRequest
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.Email == "" {
+		writeFieldError(w, "email", "Email cannot be blank.")
+		return
+	}
+	s.mutex.Lock()
+	token := fmt.Sprintf("mock-%d-%d", time.Now().Unix(), s.random.Int63())
+	s.mutex.Unlock()
+	writeJSON(w, http.StatusOK, firstdue.PostAuthTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   3600,
+		Scope:       "mock",
+	})
+}
+
+// page returns the items on the requested page, using the "page" and "per_page" query parameters.
+func page[T any](r *http.Request, items []T) []T {
+	pageNumber, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if perPage < 1 {
+		perPage = defaultPerPage
+	}
+	start := (pageNumber - 1) * perPage
+	if start >= len(items) {
+		return []T{}
+	}
+	return items[start:min(start+perPage, len(items))]
+}
+
+// matchesName returns true if the name contains the "name" query parameter, case-insensitively.
+func matchesName(r *http.Request, name string) bool {
+	filter := r.URL.Query().Get("name")
+	return filter == "" || strings.Contains(strings.ToLower(name), strings.ToLower(filter))
+}
+
+func (s *Server) getStations(w http.ResponseWriter, r *http.Request) {
+	var stations []firstdue.GetStationsResponseStation
+	for _, station := range s.config.Fixtures.Stations {
+		if matchesName(r, station.Name) {
+			stations = append(stations, station)
+		}
+	}
+	writeJSON(w, http.StatusOK, firstdue.GetStationsResponse{List: page(r, stations), Total: len(stations)})
+}
+
+func (s *Server) getApparatuses(w http.ResponseWriter, r *http.Request) {
+	useCode := r.URL.Query().Get("use_code")
+	var apparatuses []firstdue.GetApparatusesResponseApparatus
+	for _, apparatus := range s.config.Fixtures.Apparatuses {
+		if matchesName(r, apparatus.Name) && (useCode == "" || apparatus.UseCode == useCode) {
+			apparatuses = append(apparatuses, apparatus)
+		}
+	}
+	writeJSON(w, http.StatusOK, firstdue.GetApparatusesResponse{List: page(r, apparatuses), Total: len(apparatuses)})
+}
+
+func (s *Server) getDispatches(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeFieldError(w, "since", "Since must be an RFC 3339 time.")
+			return
+		}
+	}
+
+	s.mutex.Lock()
+	var dispatches []firstdue.GetDispatchesResponseDispatch
+	for _, dispatch := range s.dispatches {
+		if !time.Time(dispatch.CreatedAt).Before(since) {
+			dispatches = append(dispatches, dispatch)
+		}
+	}
+	s.mutex.Unlock()
+	writeJSON(w, http.StatusOK, page(r, dispatches))
+}
+
+func (s *Server) postDispatch(w http.ResponseWriter, r *http.Request) {
+	var input firstdue.PostDispatchesRequest
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.Type == "" {
+		writeFieldError(w, "type", "Type cannot be blank.")
+		return
+	}
+	if input.Address == "" {
+		writeFieldError(w, "address", "Address cannot be blank.")
+		return
+	}
+	dispatch := s.AddDispatch(firstdue.GetDispatchesResponseDispatch{
+		Type:             input.Type,
+		Message:          input.Message,
+		Address:          input.Address,
+		Address2:         input.Address2,
+		City:             input.City,
+		StateCode:        input.StateCode,
+		Latitude:         input.Latitude,
+		Longitude:        input.Longitude,
+		UnitCodes:        input.UnitCodes,
+		IncidentTypeCode: input.IncidentTypeCode,
+		StatusCode:       input.StatusCode,
+		XrefID:           input.XrefID,
+		CreatedAt:        input.CreatedAt,
+	})
+	writeJSON(w, http.StatusCreated, dispatch)
+}
+
+func (s *Server) getLogsSettings(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.config.Fixtures.LogSettings)
+}
+
+func (s *Server) postLogs(w http.ResponseWriter, r *http.Request) {
+	var input firstdue.PostLogsRequest
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.addLogs(firstdue.PostLogsBatchRequest{input})
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) postLogsBatch(w http.ResponseWriter, r *http.Request) {
+	var input firstdue.PostLogsBatchRequest
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.addLogs(input)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) addLogs(input firstdue.PostLogsBatchRequest) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, entry := range input {
+		s.logs = append(s.logs, LogEntry{
+			ReceivedAt: time.Now(),
+			Message:    entry.Message,
+			LevelCode:  entry.LevelCode,
+			Category:   entry.Category,
+		})
+	}
+}
+
+func (s *Server) postNotification(w http.ResponseWriter, r *http.Request) {
+	var notification firstdue.NfirsNotification
+	err := json.NewDecoder(r.Body).Decode(&notification)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if notification.DispatchNumber == "" {
+		writeFieldError(w, "dispatch_number", "Dispatch Number cannot be blank.")
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, existing := range s.notifications {
+		if existing.Notification.DispatchNumber == notification.DispatchNumber {
+			writeFieldError(w, "dispatch_number", "Dispatch Number has already been taken.")
+			return
+		}
+	}
+	notification.ID = s.newID()
+	s.notifications = append(s.notifications, &storedNotification{Notification: notification})
+	writeJSON(w, http.StatusCreated, firstdue.PostNfirsNotificationsResponse{ID: firstdue.StringUint64(notification.ID)})
+}
+
+func (s *Server) getNotification(w http.ResponseWriter, r *http.Request, notification *storedNotification) {
+	writeJSON(w, http.StatusOK, notification.Notification)
+}
+
+func (s *Server) putNotification(w http.ResponseWriter, r *http.Request, notification *storedNotification) {
+	var input firstdue.NfirsNotification
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	input.ID = notification.Notification.ID
+	if input.DispatchNumber == "" {
+		input.DispatchNumber = notification.Notification.DispatchNumber
+	}
+	notification.Notification = input
+	writeJSON(w, http.StatusOK, notification.Notification)
+}
+
+func (s *Server) deleteNotification(w http.ResponseWriter, r *http.Request, notification *storedNotification) {
+	for i, n := range s.notifications {
+		if n == notification {
+			s.notifications = append(s.notifications[:i], s.notifications[i+1:]...)
+			break
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) postApparatus(w http.ResponseWriter, r *http.Request, notification *storedNotification) {
+	var input firstdue.NfirsNotificationApparatus
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.UnitCode == "" {
+		writeFieldError(w, "unit_code", "Unit Code cannot be blank.")
+		return
+	}
+	for _, existing := range notification.Apparatuses {
+		if strings.EqualFold(existing.UnitCode, input.UnitCode) {
+			writeFieldError(w, "unit_code", "Unit Code has already been taken.")
+			return
+		}
+	}
+	apparatus := storedApparatus{ID: s.newID(), NfirsNotificationApparatus: input}
+	notification.Apparatuses = append(notification.Apparatuses, apparatus)
+	writeJSON(w, http.StatusCreated, firstdue.PostNfirsNotificationsIDApparatusesResponse{ID: firstdue.StringUint64(apparatus.ID)})
+}
+
+// findApparatus returns the index of the apparatus named by the "apparatus" path parameter, which is either its ID
+// or its unit code.
+func findApparatus(r *http.Request, notification *storedNotification, byUnitCode bool) int {
+	key := r.PathValue("apparatus")
+	for i, apparatus := range notification.Apparatuses {
+		if byUnitCode && strings.EqualFold(apparatus.UnitCode, key) {
+			return i
+		}
+		if !byUnitCode && strconv.FormatUint(apparatus.ID, 10) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Server) putApparatus(byUnitCode bool) notificationHandler {
+	return func(w http.ResponseWriter, r *http.Request, notification *storedNotification) {
+		index := findApparatus(r, notification, byUnitCode)
+		if index < 0 {
+			writeError(w, http.StatusNotFound, "Apparatus not found.")
+			return
+		}
+		var input firstdue.NfirsNotificationApparatus
+		err := json.NewDecoder(r.Body).Decode(&input)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if input.UnitCode == "" {
+			input.UnitCode = notification.Apparatuses[index].UnitCode
+		}
+		notification.Apparatuses[index].NfirsNotificationApparatus = input
+		writeJSON(w, http.StatusOK, notification.Apparatuses[index])
+	}
+}
+
+func (s *Server) deleteApparatus(byUnitCode bool) notificationHandler {
+	return func(w http.ResponseWriter, r *http.Request, notification *storedNotification) {
+		index := findApparatus(r, notification, byUnitCode)
+		if index < 0 {
+			writeError(w, http.StatusNotFound, "Apparatus not found.")
+			return
+		}
+		notification.Apparatuses = append(notification.Apparatuses[:index], notification.Apparatuses[index+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
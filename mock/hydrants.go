@@ -0,0 +1,175 @@
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// hydrantHandler handles a request for a single hydrant; the caller holds the lock.
+type hydrantHandler func(w http.ResponseWriter, r *http.Request, hydrant *firstdue.Hydrant)
+
+// byHydrantID finds the hydrant by the "id" path parameter.
+func (s *Server) byHydrantID(next hydrantHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		for i := range s.hydrants {
+			if s.hydrants[i].ID == id {
+				next(w, r, &s.hydrants[i])
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "Hydrant not found.")
+	}
+}
+
+// clearLocation removes a location unless both coordinates are set and non-zero.
+//
+// A coordinate that is sent as "" decodes as zero, so a location with a zero coordinate is treated as no location
+// at all, the way the dispatch API treats 0,0.
+func clearLocation(latitude **firstdue.FlexibleFloat64, longitude **firstdue.FlexibleFloat64) {
+	if *latitude == nil || *longitude == nil || **latitude == 0 || **longitude == 0 {
+		*latitude = nil
+		*longitude = nil
+	}
+}
+
+func (s *Server) getHydrants(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeFieldError(w, "since", "Since must be an RFC 3339 time.")
+			return
+		}
+	}
+	number := strings.ToLower(r.URL.Query().Get("number"))
+	statusCode := r.URL.Query().Get("status_code")
+
+	s.mutex.Lock()
+	var hydrants []firstdue.Hydrant
+	for _, hydrant := range s.hydrants {
+		if !strings.Contains(strings.ToLower(hydrant.Number), number) {
+			continue
+		}
+		if statusCode != "" && hydrant.StatusCode != statusCode {
+			continue
+		}
+		if time.Time(hydrant.UpdatedAt).Before(since) {
+			continue
+		}
+		hydrants = append(hydrants, hydrant)
+	}
+	s.mutex.Unlock()
+	writeJSON(w, http.StatusOK, firstdue.GetHydrantsResponse{List: page(r, hydrants), Total: len(hydrants)})
+}
+
+func (s *Server) getHydrant(w http.ResponseWriter, r *http.Request, hydrant *firstdue.Hydrant) {
+	writeJSON(w, http.StatusOK, hydrant)
+}
+
+func (s *Server) postHydrant(w http.ResponseWriter, r *http.Request) {
+	var input firstdue.Hydrant
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.Number == "" {
+		writeFieldError(w, "number", "Number cannot be blank.")
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, existing := range s.hydrants {
+		if strings.EqualFold(existing.Number, input.Number) {
+			writeFieldError(w, "number", "Number has already been taken.")
+			return
+		}
+	}
+	now := firstdue.Timestamp(time.Now().UTC().Truncate(time.Second))
+	input.ID = s.newID()
+	input.CreatedAt = now
+	input.UpdatedAt = now
+	clearLocation(&input.Latitude, &input.Longitude)
+	s.hydrants = append(s.hydrants, input)
+	writeJSON(w, http.StatusCreated, firstdue.PostHydrantsResponse{ID: firstdue.StringUint64(input.ID)})
+}
+
+func (s *Server) putHydrant(w http.ResponseWriter, r *http.Request, hydrant *firstdue.Hydrant) {
+	var input firstdue.Hydrant
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	input.ID = hydrant.ID
+	if input.Number == "" {
+		input.Number = hydrant.Number
+	}
+	input.CreatedAt = hydrant.CreatedAt
+	input.UpdatedAt = firstdue.Timestamp(time.Now().UTC().Truncate(time.Second))
+	clearLocation(&input.Latitude, &input.Longitude)
+	*hydrant = input
+	writeJSON(w, http.StatusOK, hydrant)
+}
+
+func (s *Server) getFlowTests(w http.ResponseWriter, r *http.Request, hydrant *firstdue.Hydrant) {
+	var flowTests []firstdue.HydrantFlowTest
+	for _, flowTest := range s.flowTests {
+		if flowTest.HydrantID == hydrant.ID {
+			flowTests = append(flowTests, flowTest)
+		}
+	}
+	writeJSON(w, http.StatusOK, firstdue.GetHydrantsIDFlowTestsResponse{List: page(r, flowTests), Total: len(flowTests)})
+}
+
+func (s *Server) postFlowTest(w http.ResponseWriter, r *http.Request, hydrant *firstdue.Hydrant) {
+	var input firstdue.HydrantFlowTest
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.TestedAt.IsZero() {
+		writeFieldError(w, "tested_at", "Tested At cannot be blank.")
+		return
+	}
+	input.ID = s.newID()
+	input.HydrantID = hydrant.ID
+	input.CreatedAt = firstdue.Timestamp(time.Now().UTC().Truncate(time.Second))
+	s.flowTests = append(s.flowTests, input)
+	writeJSON(w, http.StatusCreated, firstdue.PostHydrantsIDFlowTestsResponse{ID: firstdue.StringUint64(input.ID)})
+}
+
+func (s *Server) putFlowTest(w http.ResponseWriter, r *http.Request, hydrant *firstdue.Hydrant) {
+	for i, flowTest := range s.flowTests {
+		if flowTest.HydrantID != hydrant.ID || strconv.FormatUint(flowTest.ID, 10) != r.PathValue("flow_test") {
+			continue
+		}
+		var input firstdue.HydrantFlowTest
+		err := json.NewDecoder(r.Body).Decode(&input)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		input.ID = flowTest.ID
+		input.HydrantID = hydrant.ID
+		if input.TestedAt.IsZero() {
+			input.TestedAt = flowTest.TestedAt
+		}
+		input.CreatedAt = flowTest.CreatedAt
+		s.flowTests[i] = input
+		writeJSON(w, http.StatusOK, input)
+		return
+	}
+	writeError(w, http.StatusNotFound, "Flow test not found.")
+}
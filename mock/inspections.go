@@ -0,0 +1,166 @@
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// inspectionHandler handles a request for a single inspection; the caller holds the lock.
+type inspectionHandler func(w http.ResponseWriter, r *http.Request, inspection *firstdue.Inspection)
+
+// byInspectionID finds the inspection by the "id" path parameter.
+func (s *Server) byInspectionID(next inspectionHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		for i := range s.inspections {
+			if s.inspections[i].ID == id {
+				next(w, r, &s.inspections[i])
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "Inspection not found.")
+	}
+}
+
+// timeRange parses a date range from the "<name>_after" and "<name>_before" query parameters.  It returns false
+// after writing an error response if either is invalid.
+func timeRange(w http.ResponseWriter, r *http.Request, name string) (after time.Time, before time.Time, ok bool) {
+	for _, bound := range []struct {
+		key   string
+		value *time.Time
+	}{{name + "_after", &after}, {name + "_before", &before}} {
+		value := r.URL.Query().Get(bound.key)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeFieldError(w, bound.key, "Value must be an RFC 3339 time.")
+			return after, before, false
+		}
+		*bound.value = parsed
+	}
+	return after, before, true
+}
+
+// inTimeRange returns true if the time is within the range; an open end matches anything, but a zero time only
+// matches a range that is open at both ends.
+func inTimeRange(t firstdue.Timestamp, after time.Time, before time.Time) bool {
+	if after.IsZero() && before.IsZero() {
+		return true
+	}
+	if t.IsZero() {
+		return false
+	}
+	return !time.Time(t).Before(after) && (before.IsZero() || time.Time(t).Before(before))
+}
+
+func (s *Server) getInspections(w http.ResponseWriter, r *http.Request) {
+	scheduledAfter, scheduledBefore, ok := timeRange(w, r, "scheduled")
+	if !ok {
+		return
+	}
+	completedAfter, completedBefore, ok := timeRange(w, r, "completed")
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+
+	s.mutex.Lock()
+	var inspections []firstdue.Inspection
+	for _, inspection := range s.inspections {
+		if value := query.Get("occupancy_id"); value != "" && strconv.FormatUint(inspection.OccupancyID, 10) != value {
+			continue
+		}
+		if value := query.Get("status_code"); value != "" && inspection.StatusCode != value {
+			continue
+		}
+		if value := query.Get("inspector_uuid"); value != "" && (inspection.InspectorUUID == nil || *inspection.InspectorUUID != value) {
+			continue
+		}
+		if !inTimeRange(inspection.ScheduledAt, scheduledAfter, scheduledBefore) || !inTimeRange(inspection.CompletedAt, completedAfter, completedBefore) {
+			continue
+		}
+		inspections = append(inspections, inspection)
+	}
+	s.mutex.Unlock()
+	writeJSON(w, http.StatusOK, firstdue.GetInspectionsResponse{List: page(r, inspections), Total: len(inspections)})
+}
+
+func (s *Server) getInspection(w http.ResponseWriter, r *http.Request, inspection *firstdue.Inspection) {
+	writeJSON(w, http.StatusOK, inspection)
+}
+
+// validateInspection checks the inspection's occupancy; the caller must hold the lock.  It returns false after
+// writing an error response if the inspection is invalid.
+func (s *Server) validateInspection(w http.ResponseWriter, inspection *firstdue.Inspection) bool {
+	if inspection.OccupancyID == 0 {
+		writeFieldError(w, "occupancy_id", "Occupancy cannot be blank.")
+		return false
+	}
+	for _, occupancy := range s.occupancies {
+		if occupancy.ID == inspection.OccupancyID {
+			for i := range inspection.Violations {
+				if inspection.Violations[i].ID == 0 {
+					inspection.Violations[i].ID = s.newID()
+				}
+			}
+			return true
+		}
+	}
+	writeFieldError(w, "occupancy_id", "Occupancy is invalid.")
+	return false
+}
+
+func (s *Server) postInspection(w http.ResponseWriter, r *http.Request) {
+	var input firstdue.Inspection
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.validateInspection(w, &input) {
+		return
+	}
+	if input.StatusCode == "" {
+		input.StatusCode = "scheduled"
+	}
+	now := firstdue.Timestamp(time.Now().UTC().Truncate(time.Second))
+	input.ID = s.newID()
+	input.CreatedAt = now
+	input.UpdatedAt = now
+	s.inspections = append(s.inspections, input)
+	writeJSON(w, http.StatusCreated, firstdue.PostInspectionsResponse{ID: firstdue.StringUint64(input.ID)})
+}
+
+func (s *Server) putInspection(w http.ResponseWriter, r *http.Request, inspection *firstdue.Inspection) {
+	var input firstdue.Inspection
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.OccupancyID == 0 {
+		input.OccupancyID = inspection.OccupancyID
+	}
+	if !s.validateInspection(w, &input) {
+		return
+	}
+	if input.StatusCode == "" {
+		input.StatusCode = inspection.StatusCode
+	}
+	input.ID = inspection.ID
+	input.CreatedAt = inspection.CreatedAt
+	input.UpdatedAt = firstdue.Timestamp(time.Now().UTC().Truncate(time.Second))
+	*inspection = input
+	writeJSON(w, http.StatusOK, inspection)
+}
@@ -0,0 +1,581 @@
+// Package mock provides a stateful fake of the First Due API for local development and testing.
+//
+// The server implements the endpoints that the firstdue package covers.  It accepts any credentials, keeps the
+// notifications, hydrants, occupancies, inspections, and logs that are sent to it in memory, and serves the
+// stations, apparatuses, and dispatches from a set of fixtures.  It can also generate synthetic dispatches and
+// inject faults.
+//
+// Besides the API, the server has an admin API under "/_admin/":
+//
+//	GET    /_admin/notifications   the notifications, with their apparatuses
+//	GET    /_admin/logs            the log messages that were sent
+//	GET    /_admin/faults          the active faults
+//	POST   /_admin/faults          add a fault (a Fault as JSON)
+//	DELETE /_admin/faults          remove every fault
+//	POST   /_admin/dispatches      add a dispatch (a dispatch as JSON, or an empty body for a synthetic one)
+//	POST   /_admin/reset           reset the state to the fixtures
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// Fixtures is the initial state of the server.
+type Fixtures struct {
+	Stations      []firstdue.GetStationsResponseStation      `json:"stations"`
+	Apparatuses   []firstdue.GetApparatusesResponseApparatus `json:"apparatuses"`
+	Dispatches    []firstdue.GetDispatchesResponseDispatch   `json:"dispatches"`
+	Notifications []firstdue.NfirsNotificationRecord         `json:"notifications"`
+	Hydrants      []firstdue.Hydrant                         `json:"hydrants"`
+	FlowTests     []firstdue.HydrantFlowTest                 `json:"flow_tests"` // Each refers to its hydrant by ID.
+	Occupancies   []firstdue.Occupancy                       `json:"occupancies"`
+	Inspections   []firstdue.Inspection                      `json:"inspections"` // Each refers to its occupancy by ID.
+	LogSettings   firstdue.GetLogsSettingsResponse           `json:"log_settings"`
+}
+
+// LoadFixtures reads the fixtures from a JSON file.
+func LoadFixtures(path string) (Fixtures, error) {
+	var fixtures Fixtures
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return fixtures, fmt.Errorf("error reading fixtures: %w", err)
+	}
+	err = json.Unmarshal(contents, &fixtures)
+	if err != nil {
+		return fixtures, fmt.Errorf("error parsing fixtures: %w", err)
+	}
+	return fixtures, nil
+}
+
+// DefaultFixtures returns a small department with two stations, a handful of apparatuses, a few hydrants, and two
+// pre-planned occupancies with their inspections.
+func DefaultFixtures() Fixtures {
+	unitCode := func(code string) *string {
+		return &code
+	}
+	text := func(value string) *string {
+		return &value
+	}
+	number := func(value float64) *firstdue.FlexibleFloat64 {
+		return (*firstdue.FlexibleFloat64)(&value)
+	}
+	count := func(value int64) *firstdue.FlexibleInt64 {
+		return (*firstdue.FlexibleInt64)(&value)
+	}
+	flag := func(value bool) *bool {
+		return &value
+	}
+	installed := firstdue.Timestamp(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	inspected := firstdue.Timestamp(time.Date(2024, 9, 12, 14, 30, 0, 0, time.UTC))
+	scheduled := firstdue.Timestamp(time.Now().UTC().Truncate(24 * time.Hour).Add(7*24*time.Hour + 9*time.Hour))
+	return Fixtures{
+		Stations: []firstdue.GetStationsResponseStation{
+			{UUID: "8c1c6a4e-0d6f-4a53-9d1a-000000000001", Name: "Station 1"},
+			{UUID: "8c1c6a4e-0d6f-4a53-9d1a-000000000002", Name: "Station 2"},
+		},
+		Apparatuses: []firstdue.GetApparatusesResponseApparatus{
+			{UUID: "5f0e2b7a-3c1d-4e8f-a6b2-000000000001", Name: "Engine 1", UnitCode: unitCode("E1"), UseCode: "1", UseName: "Suppression"},
+			{UUID: "5f0e2b7a-3c1d-4e8f-a6b2-000000000002", Name: "Ladder 1", UnitCode: unitCode("L1"), UseCode: "1", UseName: "Suppression"},
+			{UUID: "5f0e2b7a-3c1d-4e8f-a6b2-000000000003", Name: "Medic 1", UnitCode: unitCode("M1"), UseCode: "2", UseName: "EMS"},
+			{UUID: "5f0e2b7a-3c1d-4e8f-a6b2-000000000004", Name: "Engine 2", UnitCode: unitCode("E2"), UseCode: "1", UseName: "Suppression"},
+			{UUID: "5f0e2b7a-3c1d-4e8f-a6b2-000000000005", Name: "Battalion 1", UnitCode: unitCode("BC1"), UseCode: "0", UseName: "Other"},
+		},
+		Hydrants: []firstdue.Hydrant{
+			{ID: 1, Number: "H-101", HydrantType: "dry_barrel", StatusCode: "in_service", Address: "100 MAIN ST", City: "Washington", StateCode: "DC", Latitude: number(38.8901), Longitude: number(-77.0361), MainSize: number(8), NFPAClass: text("A"), CreatedAt: installed, UpdatedAt: installed},
+			{ID: 2, Number: "H-102", HydrantType: "dry_barrel", StatusCode: "in_service", Address: "250 OAK AVE", City: "Washington", StateCode: "DC", Latitude: number(38.8879), Longitude: number(-77.0324), MainSize: number(6), NFPAClass: text("B"), CreatedAt: installed, UpdatedAt: installed},
+			{ID: 3, Number: "H-103", HydrantType: "flush", StatusCode: "out_of_service", Address: "12 RIVER RD", City: "Washington", StateCode: "DC", Notes: text("Cap seized; reported to the water utility."), CreatedAt: installed, UpdatedAt: installed},
+		},
+		FlowTests: []firstdue.HydrantFlowTest{
+			{ID: 4, HydrantID: 1, TestedAt: installed, TestedBy: text("Engine 1"), StaticPressure: number(72), ResidualPressure: number(58), PitotPressure: number(30), OutletDiameter: number(2.5), FlowRate: number(920), AvailableFlow: number(2100), CreatedAt: installed},
+		},
+		Occupancies: []firstdue.Occupancy{
+			{
+				ID:               5,
+				Name:             "Riverside Hardware",
+				OccupancyType:    "mercantile",
+				StatusCode:       "active",
+				StationUUID:      text("8c1c6a4e-0d6f-4a53-9d1a-000000000001"),
+				Addresses:        []firstdue.OccupancyAddress{{ID: 6, IsPrimary: true, Address: "40 RIVER RD", City: "Washington", StateCode: "DC", Latitude: number(38.8862), Longitude: number(-77.0312)}},
+				Contacts:         []firstdue.OccupancyContact{{ID: 7, Name: "Pat Lee", Role: text("owner"), Phone: text("202-555-0141"), IsEmergency: true}},
+				Hazards:          []firstdue.OccupancyHazard{{ID: 8, HazardType: "hazmat", Description: "Propane cylinder exchange cage", Location: text("Front sidewalk"), Quantity: number(24), Unit: text("cylinders"), UNNumber: text("1075")}},
+				ConstructionType: text("Type III"),
+				Stories:          count(2),
+				SquareFootage:    number(12500),
+				YearBuilt:        count(1962),
+				Sprinklered:      flag(false),
+				FireAlarm:        flag(true),
+				KnoxBoxLocation:  text("Left of the front door"),
+				CreatedAt:        installed,
+				UpdatedAt:        installed,
+			},
+			{
+				ID:               9,
+				Name:             "Oak Avenue Elementary School",
+				OccupancyType:    "educational",
+				StatusCode:       "active",
+				StationUUID:      text("8c1c6a4e-0d6f-4a53-9d1a-000000000002"),
+				Addresses:        []firstdue.OccupancyAddress{{ID: 10, IsPrimary: true, Address: "300 OAK AVE", City: "Washington", StateCode: "DC", Latitude: number(38.8871), Longitude: number(-77.0301)}},
+				Contacts:         []firstdue.OccupancyContact{{ID: 11, Name: "Front office", Role: text("principal"), Phone: text("202-555-0187")}},
+				ConstructionType: text("Type II"),
+				Stories:          count(1),
+				SquareFootage:    number(48000),
+				YearBuilt:        count(1998),
+				OccupantLoad:     count(650),
+				Sprinklered:      flag(true),
+				FireAlarm:        flag(true),
+				CreatedAt:        installed,
+				UpdatedAt:        installed,
+			},
+		},
+		Inspections: []firstdue.Inspection{
+			{
+				ID:             12,
+				OccupancyID:    5,
+				InspectionType: "annual",
+				StatusCode:     "completed",
+				Result:         text("fail"),
+				InspectorName:  text("Inspector Diaz"),
+				ScheduledAt:    inspected,
+				CompletedAt:    inspected,
+				Violations: []firstdue.InspectionViolation{
+					{ID: 13, Code: "IFC 906.1", Description: "Portable fire extinguisher missing near the paint aisle.", Location: text("Aisle 4"), StatusCode: "open", CorrectBy: firstdue.Timestamp(time.Time(inspected).AddDate(0, 0, 30))},
+				},
+				CreatedAt: inspected,
+				UpdatedAt: inspected,
+			},
+			{ID: 14, OccupancyID: 9, InspectionType: "annual", StatusCode: "scheduled", InspectorName: text("Inspector Diaz"), ScheduledAt: scheduled, CreatedAt: inspected, UpdatedAt: inspected},
+		},
+	}
+}
+
+// Config configures a Server.
+type Config struct {
+	Fixtures  Fixtures // The initial state.
+	Seed      int64    // The seed for the synthetic dispatches; if zero, the current time is used.
+	Latitude  float64  // The center of the synthetic dispatches; if both are zero, a default location is used.
+	Longitude float64  // The center of the synthetic dispatches; if both are zero, a default location is used.
+	City      string   // The city of the synthetic dispatches; the default location sets it if it is empty.
+	StateCode string   // The state of the synthetic dispatches; the default location sets it if it is empty.
+}
+
+// Fault makes matching requests fail or slow down.
+type Fault struct {
+	Method     string `json:"method,omitempty"` // The HTTP method to match; if empty, every method matches.
+	PathPrefix string `json:"path"`             // The path prefix to match, such as "/v1/stations".
+	Status     int    `json:"status,omitempty"` // The status to respond with; if zero, the request is only delayed.
+	Message    string `json:"message,omitempty"`
+	Delay      string `json:"delay,omitempty"` // How long to wait before responding, such as "2s".
+	Count      int    `json:"count,omitempty"` // How many requests to affect; if zero, the fault stays until it is removed.
+}
+
+// LogEntry is a log message that was sent to the server.
+type LogEntry struct {
+	ReceivedAt time.Time `json:"received_at"`
+	Message    string    `json:"message"`
+	LevelCode  string    `json:"level_code"`
+	Category   string    `json:"category"`
+}
+
+// storedApparatus is an apparatus on a notification.
+type storedApparatus struct {
+	ID uint64 `json:"id"`
+	firstdue.NfirsNotificationApparatus
+}
+
+// storedNotification is a notification along with its apparatuses.
+type storedNotification struct {
+	Notification firstdue.NfirsNotification `json:"notification"`
+	Apparatuses  []storedApparatus          `json:"apparatuses"`
+}
+
+// Server is a fake First Due API.
+type Server struct {
+	config  Config
+	handler http.Handler
+
+	mutex         sync.Mutex
+	random        *rand.Rand
+	dispatches    []firstdue.GetDispatchesResponseDispatch
+	notifications []*storedNotification
+	hydrants      []firstdue.Hydrant
+	flowTests     []firstdue.HydrantFlowTest
+	occupancies   []firstdue.Occupancy
+	inspections   []firstdue.Inspection
+	logs          []LogEntry
+	faults        []Fault
+	nextID        uint64
+}
+
+// NewServer returns a server with the state from the fixtures.
+func NewServer(config Config) *Server {
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+	if config.Latitude == 0 && config.Longitude == 0 {
+		config.Latitude = 38.8895
+		config.Longitude = -77.0353
+		if config.City == "" && config.StateCode == "" {
+			config.City = "WASHINGTON"
+			config.StateCode = "DC"
+		}
+	}
+	s := &Server{
+		config: config,
+		random: rand.New(rand.NewSource(config.Seed)),
+	}
+	s.reset()
+
+	mux := http.NewServeMux()
+	s.registerAPI(mux)
+	mux.HandleFunc("GET /_admin/notifications", s.adminGetNotifications)
+	mux.HandleFunc("GET /_admin/logs", s.adminGetLogs)
+	mux.HandleFunc("GET /_admin/faults", s.adminGetFaults)
+	mux.HandleFunc("POST /_admin/faults", s.adminPostFaults)
+	mux.HandleFunc("DELETE /_admin/faults", s.adminDeleteFaults)
+	mux.HandleFunc("POST /_admin/dispatches", s.adminPostDispatches)
+	mux.HandleFunc("POST /_admin/reset", s.adminPostReset)
+	s.handler = mux
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	defer func() {
+		slog.Info("Request.", "method", r.Method, "path", r.URL.Path, "status", recorder.status, "duration", time.Since(start).Round(time.Millisecond))
+	}()
+
+	if !strings.HasPrefix(r.URL.Path, "/_admin/") {
+		if fault, ok := s.takeFault(r); ok {
+			if delay, err := time.ParseDuration(fault.Delay); err == nil {
+				select {
+				case <-time.After(delay):
+				case <-r.Context().Done():
+					return
+				}
+			}
+			if fault.Status != 0 {
+				message := fault.Message
+				if message == "" {
+					message = "injected fault"
+				}
+				writeError(recorder, fault.Status, message)
+				return
+			}
+		}
+	}
+	s.handler.ServeHTTP(recorder, r)
+}
+
+// reset restores the state from the fixtures.
+func (s *Server) reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.dispatches = append([]firstdue.GetDispatchesResponseDispatch{}, s.config.Fixtures.Dispatches...)
+	s.hydrants = append([]firstdue.Hydrant{}, s.config.Fixtures.Hydrants...)
+	s.flowTests = append([]firstdue.HydrantFlowTest{}, s.config.Fixtures.FlowTests...)
+	s.occupancies = append([]firstdue.Occupancy{}, s.config.Fixtures.Occupancies...)
+	s.inspections = append([]firstdue.Inspection{}, s.config.Fixtures.Inspections...)
+	s.notifications = nil
+	s.logs = nil
+	s.faults = nil
+	s.nextID = 1000
+	for _, dispatch := range s.dispatches {
+		if uint64(dispatch.ID) >= s.nextID {
+			s.nextID = uint64(dispatch.ID) + 1
+		}
+	}
+	for _, hydrant := range s.hydrants {
+		s.nextID = max(s.nextID, hydrant.ID+1)
+	}
+	for _, flowTest := range s.flowTests {
+		s.nextID = max(s.nextID, flowTest.ID+1)
+	}
+	for _, occupancy := range s.occupancies {
+		s.nextID = max(s.nextID, occupancy.ID+1)
+		for _, address := range occupancy.Addresses {
+			s.nextID = max(s.nextID, address.ID+1)
+		}
+		for _, contact := range occupancy.Contacts {
+			s.nextID = max(s.nextID, contact.ID+1)
+		}
+		for _, hazard := range occupancy.Hazards {
+			s.nextID = max(s.nextID, hazard.ID+1)
+		}
+	}
+	for _, inspection := range s.inspections {
+		s.nextID = max(s.nextID, inspection.ID+1)
+		for _, violation := range inspection.Violations {
+			s.nextID = max(s.nextID, violation.ID+1)
+		}
+	}
+	for _, record := range s.config.Fixtures.Notifications {
+		notification := &storedNotification{Notification: record.Notification}
+		notification.Notification.ID = s.newID()
+		for _, apparatus := range record.Apparatuses {
+			notification.Apparatuses = append(notification.Apparatuses, storedApparatus{ID: s.newID(), NfirsNotificationApparatus: apparatus})
+		}
+		s.notifications = append(s.notifications, notification)
+	}
+}
+
+// newID returns a new ID; the caller must hold the lock.
+func (s *Server) newID() uint64 {
+	id := s.nextID
+	s.nextID++
+	return id
+}
+
+// takeFault returns the first fault that matches the request, using up one of its count.
+func (s *Server) takeFault(r *http.Request) (Fault, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, fault := range s.faults {
+		if fault.Method != "" && !strings.EqualFold(fault.Method, r.Method) {
+			continue
+		}
+		if !strings.HasPrefix(r.URL.Path, fault.PathPrefix) {
+			continue
+		}
+		if fault.Count > 0 {
+			s.faults[i].Count--
+			if s.faults[i].Count == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return fault, true
+	}
+	return Fault{}, false
+}
+
+// AddFault adds a fault.
+func (s *Server) AddFault(fault Fault) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults = append(s.faults, fault)
+}
+
+// Notifications returns the notifications that the server holds.
+func (s *Server) Notifications() []firstdue.NfirsNotificationRecord {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var records []firstdue.NfirsNotificationRecord
+	for _, notification := range s.notifications {
+		record := firstdue.NfirsNotificationRecord{Notification: notification.Notification}
+		for _, apparatus := range notification.Apparatuses {
+			record.Apparatuses = append(record.Apparatuses, apparatus.NfirsNotificationApparatus)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// AddDispatch adds a dispatch, assigning it an ID and creation time if it does not have them.
+func (s *Server) AddDispatch(dispatch firstdue.GetDispatchesResponseDispatch) firstdue.GetDispatchesResponseDispatch {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if dispatch.ID == 0 {
+		dispatch.ID = int(s.newID())
+	}
+	if dispatch.CreatedAt.IsZero() {
+		dispatch.CreatedAt = firstdue.Timestamp(time.Now().UTC().Truncate(time.Second))
+	}
+	s.dispatches = append(s.dispatches, dispatch)
+	return dispatch
+}
+
+// syntheticIncidents are the kinds of dispatches that GenerateDispatch picks from.
+var syntheticIncidents = []struct {
+	Type             string
+	IncidentTypeCode string
+	Message          string
+	UseName          string
+}{
+	{"FIRE", "111", "STRUCTURE FIRE", "Suppression"},
+	{"FIRE", "131", "VEHICLE FIRE", "Suppression"},
+	{"FIRE", "143", "BRUSH FIRE", "Suppression"},
+	{"EMS", "321", "MEDICAL EMERGENCY", "EMS"},
+	{"EMS", "322", "MOTOR VEHICLE ACCIDENT WITH INJURIES", "EMS"},
+	{"SERVICE", "553", "PUBLIC SERVICE", ""},
+	{"ALARM", "745", "FIRE ALARM ACTIVATION", "Suppression"},
+}
+
+// syntheticStreets are the streets that GenerateDispatch picks from.
+var syntheticStreets = []string{"MAIN ST", "OAK AVE", "MAPLE DR", "WASHINGTON BLVD", "PARK LN", "RIVER RD", "HIGHLAND AVE"}
+
+// GenerateDispatch adds a random dispatch that uses the apparatuses from the fixtures.
+func (s *Server) GenerateDispatch() firstdue.GetDispatchesResponseDispatch {
+	s.mutex.Lock()
+	incident := syntheticIncidents[s.random.Intn(len(syntheticIncidents))]
+	var unitCodes []string
+	for _, apparatus := range s.config.Fixtures.Apparatuses {
+		if apparatus.UnitCode == nil || *apparatus.UnitCode == "" {
+			continue
+		}
+		if apparatus.UseName == incident.UseName || s.random.Intn(4) == 0 {
+			unitCodes = append(unitCodes, *apparatus.UnitCode)
+		}
+	}
+	dispatch := firstdue.GetDispatchesResponseDispatch{
+		Type:             incident.Type,
+		Message:          incident.Message,
+		Address:          fmt.Sprintf("%d %s", 100+s.random.Intn(9900), syntheticStreets[s.random.Intn(len(syntheticStreets))]),
+		City:             s.config.City,
+		StateCode:        s.config.StateCode,
+		Latitude:         s.config.Latitude + (s.random.Float64()-0.5)*0.1,
+		Longitude:        s.config.Longitude + (s.random.Float64()-0.5)*0.1,
+		UnitCodes:        unitCodes,
+		IncidentTypeCode: incident.IncidentTypeCode,
+		StatusCode:       "open",
+		XrefID:           fmt.Sprintf("CAD-%06d", s.random.Intn(1000000)),
+	}
+	s.mutex.Unlock()
+	return s.AddDispatch(dispatch)
+}
+
+// GenerateDispatches adds a synthetic dispatch at every interval until the context is canceled.
+func (s *Server) GenerateDispatches(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			dispatch := s.GenerateDispatch()
+			slog.Info("Generated a dispatch.", "id", dispatch.ID, "message", dispatch.Message, "units", dispatch.UnitCodes)
+		}
+	}
+}
+
+// statusRecorder remembers the status of a response for logging.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// writeJSON writes the value as a JSON response.
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(value)
+}
+
+// writeError writes an error response in the format that the API uses.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, firstdue.ErrorResponse{Code: status, Message: message})
+}
+
+// writeFieldError writes a validation error response for a single field.
+func writeFieldError(w http.ResponseWriter, field string, message string) {
+	response := firstdue.ErrorResponse{Code: http.StatusUnprocessableEntity, Message: "Validation failed"}
+	response.Errors = append(response.Errors, struct {
+		Field   string `json:"field"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}{Field: field, Code: "invalid", Message: message})
+	writeJSON(w, http.StatusUnprocessableEntity, response)
+}
+
+func (s *Server) adminGetNotifications(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	notifications := s.notifications
+	if notifications == nil {
+		notifications = []*storedNotification{}
+	}
+	writeJSON(w, http.StatusOK, notifications)
+}
+
+func (s *Server) adminGetLogs(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	logs := s.logs
+	if logs == nil {
+		logs = []LogEntry{}
+	}
+	writeJSON(w, http.StatusOK, logs)
+}
+
+func (s *Server) adminGetFaults(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	faults := s.faults
+	if faults == nil {
+		faults = []Fault{}
+	}
+	writeJSON(w, http.StatusOK, faults)
+}
+
+func (s *Server) adminPostFaults(w http.ResponseWriter, r *http.Request) {
+	var fault Fault
+	err := json.NewDecoder(r.Body).Decode(&fault)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if fault.Delay != "" {
+		if _, err := time.ParseDuration(fault.Delay); err != nil {
+			writeFieldError(w, "delay", err.Error())
+			return
+		}
+	}
+	if fault.Status == 0 && fault.Delay == "" {
+		writeFieldError(w, "status", "a fault needs a status, a delay, or both")
+		return
+	}
+	s.AddFault(fault)
+	writeJSON(w, http.StatusCreated, fault)
+}
+
+func (s *Server) adminDeleteFaults(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults = nil
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) adminPostDispatches(w http.ResponseWriter, r *http.Request) {
+	var dispatch firstdue.GetDispatchesResponseDispatch
+	err := json.NewDecoder(r.Body).Decode(&dispatch)
+	switch {
+	case errors.Is(err, io.EOF):
+		dispatch = s.GenerateDispatch()
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	default:
+		dispatch = s.AddDispatch(dispatch)
+	}
+	writeJSON(w, http.StatusCreated, dispatch)
+}
+
+func (s *Server) adminPostReset(w http.ResponseWriter, r *http.Request) {
+	s.reset()
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,207 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// call sends a request to the server with a bearer token and decodes the JSON response into output, if it is set.
+func call(t *testing.T, s *Server, method string, path string, body string, output any) int {
+	t.Helper()
+	r := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	r.Header.Set("Authorization", "Bearer test")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if output != nil && w.Body.Len() > 0 {
+		if err := json.Unmarshal(w.Body.Bytes(), output); err != nil {
+			t.Fatalf("%s %s: %v: %s", method, path, err, w.Body.String())
+		}
+	}
+	return w.Code
+}
+
+// expect checks the status of a request.
+func expect(t *testing.T, s *Server, method string, path string, body string, status int) {
+	t.Helper()
+	if got := call(t, s, method, path, body, nil); got != status {
+		t.Errorf("%s %s: got status %d, want %d", method, path, got, status)
+	}
+}
+
+func newTestServer() *Server {
+	return NewServer(Config{Fixtures: DefaultFixtures(), Seed: 1})
+}
+
+func TestNotifications(t *testing.T) {
+	s := newTestServer()
+
+	var created firstdue.PostNfirsNotificationsResponse
+	if status := call(t, s, http.MethodPost, "/v1/nfirs-notifications", `{"dispatch_number": "26-1", "address": "1 MAIN ST"}`, &created); status != http.StatusCreated || created.ID == 0 {
+		t.Fatalf("got status %d and ID %d", status, created.ID)
+	}
+	path := "/v1/nfirs-notifications/" + strconv.FormatUint(uint64(created.ID), 10)
+	expect(t, s, http.MethodPost, "/v1/nfirs-notifications", `{"dispatch_number": "26-1"}`, http.StatusUnprocessableEntity)
+	expect(t, s, http.MethodPost, "/v1/nfirs-notifications", `{"address": "2 MAIN ST"}`, http.StatusUnprocessableEntity)
+	expect(t, s, http.MethodPost, "/v1/nfirs-notifications", `{`, http.StatusBadRequest)
+
+	var notification firstdue.NfirsNotification
+	if status := call(t, s, http.MethodGet, "/v1/nfirs-notifications/dispatch-number/26-1", "", &notification); status != http.StatusOK || notification.ID != uint64(created.ID) {
+		t.Errorf("got status %d and %+v", status, notification)
+	}
+
+	// An update keeps the ID and, if it is not given, the dispatch number.
+	if status := call(t, s, http.MethodPut, path, `{"address": "3 MAIN ST", "id": 1}`, &notification); status != http.StatusOK {
+		t.Errorf("got status %d", status)
+	}
+	if notification.ID != uint64(created.ID) || notification.DispatchNumber != "26-1" || notification.Address != "3 MAIN ST" {
+		t.Errorf("unexpected notification: %+v", notification)
+	}
+
+	var apparatus firstdue.PostNfirsNotificationsIDApparatusesResponse
+	if status := call(t, s, http.MethodPost, path+"/apparatuses", `{"unit_code": "E1"}`, &apparatus); status != http.StatusCreated {
+		t.Errorf("got status %d", status)
+	}
+	expect(t, s, http.MethodPost, "/v1/nfirs-notifications/number/26-1/apparatuses", `{"unit_code": "L1"}`, http.StatusCreated)
+	expect(t, s, http.MethodPost, path+"/apparatuses", `{"unit_code": "e1"}`, http.StatusUnprocessableEntity)
+	expect(t, s, http.MethodPost, path+"/apparatuses", `{}`, http.StatusUnprocessableEntity)
+	expect(t, s, http.MethodPut, "/v1/nfirs-notifications/number/26-1/apparatuses/code/l1", `{"is_aid": true}`, http.StatusOK)
+	expect(t, s, http.MethodPut, path+"/apparatuses/999999", `{}`, http.StatusNotFound)
+	expect(t, s, http.MethodDelete, path+"/apparatuses/"+strconv.FormatUint(uint64(apparatus.ID), 10), "", http.StatusNoContent)
+	expect(t, s, http.MethodDelete, path+"/apparatuses/"+strconv.FormatUint(uint64(apparatus.ID), 10), "", http.StatusNotFound)
+
+	records := s.Notifications()
+	if len(records) != 1 || len(records[0].Apparatuses) != 1 || records[0].Apparatuses[0] != (firstdue.NfirsNotificationApparatus{UnitCode: "L1", IsAid: true}) {
+		t.Errorf("unexpected notifications: %+v", records)
+	}
+
+	expect(t, s, http.MethodDelete, "/v1/nfirs-notifications/number/26-1", "", http.StatusNoContent)
+	expect(t, s, http.MethodGet, path, "", http.StatusNotFound)
+	expect(t, s, http.MethodDelete, path, "", http.StatusNotFound)
+	if records := s.Notifications(); len(records) != 0 {
+		t.Errorf("unexpected notifications: %+v", records)
+	}
+
+	// Every API request needs a token.
+	r := httptest.NewRequest(http.MethodGet, "/v1/stations", nil)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d without a token", w.Code)
+	}
+}
+
+func TestAdmin(t *testing.T) {
+	s := newTestServer()
+	fixtures := DefaultFixtures()
+
+	var token firstdue.PostAuthTokenResponse
+	if status := call(t, s, http.MethodPost, "/v1/auth/token", `{"email": "user@example.com", "password": "x"}`, &token); status != http.StatusOK || token.AccessToken == "" {
+		t.Errorf("got status %d and %+v", status, token)
+	}
+	expect(t, s, http.MethodPost, "/v1/auth/token", `{"password": "x"}`, http.StatusUnprocessableEntity)
+
+	// A dispatch can be given, or generated when the body is empty.
+	var dispatch firstdue.GetDispatchesResponseDispatch
+	if status := call(t, s, http.MethodPost, "/_admin/dispatches", `{"type": "FIRE", "address": "1 MAIN ST"}`, &dispatch); status != http.StatusCreated || dispatch.ID == 0 || dispatch.CreatedAt.IsZero() {
+		t.Errorf("got status %d and %+v", status, dispatch)
+	}
+	if status := call(t, s, http.MethodPost, "/_admin/dispatches", "", &dispatch); status != http.StatusCreated || dispatch.City != "WASHINGTON" || dispatch.StateCode != "DC" || dispatch.Address == "" {
+		t.Errorf("got status %d and %+v", status, dispatch)
+	}
+	var dispatches []firstdue.GetDispatchesResponseDispatch
+	call(t, s, http.MethodGet, "/v1/dispatches?per_page=100", "", &dispatches)
+	if len(dispatches) != len(fixtures.Dispatches)+2 {
+		t.Errorf("got %d dispatches, want %d", len(dispatches), len(fixtures.Dispatches)+2)
+	}
+
+	expect(t, s, http.MethodPost, "/v1/nfirs-notifications", `{"dispatch_number": "26-1"}`, http.StatusCreated)
+	expect(t, s, http.MethodPost, "/v1/logs", `{"message": "hello", "level_code": "info", "category": "test"}`, http.StatusCreated)
+	expect(t, s, http.MethodPost, "/_admin/faults", `{"path": "/v1/hydrants", "status": 503}`, http.StatusCreated)
+
+	var notifications []storedNotification
+	call(t, s, http.MethodGet, "/_admin/notifications", "", &notifications)
+	if len(notifications) != len(fixtures.Notifications)+1 {
+		t.Errorf("got %d notifications", len(notifications))
+	}
+	var logs []LogEntry
+	call(t, s, http.MethodGet, "/_admin/logs", "", &logs)
+	if len(logs) != 1 || logs[0].Message != "hello" || logs[0].Category != "test" {
+		t.Errorf("unexpected logs: %+v", logs)
+	}
+
+	// A reset restores the fixtures and forgets everything else.
+	expect(t, s, http.MethodPost, "/_admin/reset", "", http.StatusNoContent)
+	call(t, s, http.MethodGet, "/v1/dispatches?per_page=100", "", &dispatches)
+	call(t, s, http.MethodGet, "/_admin/notifications", "", &notifications)
+	call(t, s, http.MethodGet, "/_admin/logs", "", &logs)
+	var faults []Fault
+	call(t, s, http.MethodGet, "/_admin/faults", "", &faults)
+	if len(dispatches) != len(fixtures.Dispatches) || len(notifications) != len(fixtures.Notifications) || len(logs) != 0 || len(faults) != 0 {
+		t.Errorf("got %d dispatches, %d notifications, %d logs, and %d faults after a reset", len(dispatches), len(notifications), len(logs), len(faults))
+	}
+	expect(t, s, http.MethodGet, "/v1/hydrants", "", http.StatusOK)
+}
+
+func TestFaults(t *testing.T) {
+	s := newTestServer()
+
+	// A fault with a status.
+	s.AddFault(Fault{PathPrefix: "/v1/stations", Status: http.StatusServiceUnavailable})
+	var response firstdue.ErrorResponse
+	if status := call(t, s, http.MethodGet, "/v1/stations", "", &response); status != http.StatusServiceUnavailable || response.Message != "injected fault" {
+		t.Errorf("got status %d and %+v", status, response)
+	}
+	expect(t, s, http.MethodGet, "/v1/stations?page=2", "", http.StatusServiceUnavailable)
+	expect(t, s, http.MethodGet, "/v1/apparatuses", "", http.StatusOK)
+
+	// The admin API is never affected.
+	expect(t, s, http.MethodPost, "/_admin/faults", `{"path": "/", "status": 500}`, http.StatusCreated)
+	expect(t, s, http.MethodGet, "/_admin/logs", "", http.StatusOK)
+	expect(t, s, http.MethodGet, "/v1/apparatuses", "", http.StatusInternalServerError)
+	var faults []Fault
+	call(t, s, http.MethodGet, "/_admin/faults", "", &faults)
+	if len(faults) != 2 {
+		t.Errorf("got %d faults, want 2", len(faults))
+	}
+	expect(t, s, http.MethodDelete, "/_admin/faults", "", http.StatusNoContent)
+	expect(t, s, http.MethodGet, "/v1/stations", "", http.StatusOK)
+
+	// A fault for one method, with a count and a message.
+	s.AddFault(Fault{Method: "post", PathPrefix: "/v1/nfirs-notifications", Status: http.StatusTooManyRequests, Message: "slow down", Count: 2})
+	expect(t, s, http.MethodGet, "/v1/nfirs-notifications/1", "", http.StatusNotFound)
+	for range 2 {
+		if status := call(t, s, http.MethodPost, "/v1/nfirs-notifications", `{"dispatch_number": "26-1"}`, &response); status != http.StatusTooManyRequests || response.Message != "slow down" {
+			t.Errorf("got status %d and %+v", status, response)
+		}
+	}
+	expect(t, s, http.MethodPost, "/v1/nfirs-notifications", `{"dispatch_number": "26-1"}`, http.StatusCreated)
+
+	// A fault with only a delay.
+	s.AddFault(Fault{PathPrefix: "/v1/stations", Delay: "50ms", Count: 1})
+	start := time.Now()
+	expect(t, s, http.MethodGet, "/v1/stations", "", http.StatusOK)
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("the request took %s, want at least 50ms", elapsed)
+	}
+	start = time.Now()
+	expect(t, s, http.MethodGet, "/v1/stations", "", http.StatusOK)
+	if elapsed := time.Since(start); elapsed >= 50*time.Millisecond {
+		t.Errorf("the request took %s after the fault was used up", elapsed)
+	}
+
+	// A fault with a delay and a status.
+	s.AddFault(Fault{PathPrefix: "/v1/stations", Delay: "10ms", Status: http.StatusGatewayTimeout, Count: 1})
+	expect(t, s, http.MethodGet, "/v1/stations", "", http.StatusGatewayTimeout)
+
+	// Invalid faults.
+	expect(t, s, http.MethodPost, "/_admin/faults", `{"path": "/v1/stations"}`, http.StatusUnprocessableEntity)
+	expect(t, s, http.MethodPost, "/_admin/faults", `{"path": "/v1/stations", "delay": "soon"}`, http.StatusUnprocessableEntity)
+	expect(t, s, http.MethodPost, "/_admin/faults", `{`, http.StatusBadRequest)
+}
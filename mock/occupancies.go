@@ -0,0 +1,131 @@
+package mock
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// occupancyHandler handles a request for a single occupancy; the caller holds the lock.
+type occupancyHandler func(w http.ResponseWriter, r *http.Request, index int)
+
+// byOccupancyID finds the occupancy by the "id" path parameter.
+func (s *Server) byOccupancyID(next occupancyHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.ParseUint(r.PathValue("id"), 10, 64)
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		for i, occupancy := range s.occupancies {
+			if occupancy.ID == id {
+				next(w, r, i)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "Occupancy not found.")
+	}
+}
+
+func (s *Server) getOccupancies(w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeFieldError(w, "since", "Since must be an RFC 3339 time.")
+			return
+		}
+	}
+	occupancyType := r.URL.Query().Get("occupancy_type")
+
+	s.mutex.Lock()
+	var occupancies []firstdue.Occupancy
+	for _, occupancy := range s.occupancies {
+		if !matchesName(r, occupancy.Name) {
+			continue
+		}
+		if occupancyType != "" && occupancy.OccupancyType != occupancyType {
+			continue
+		}
+		if time.Time(occupancy.UpdatedAt).Before(since) {
+			continue
+		}
+		occupancies = append(occupancies, occupancy)
+	}
+	s.mutex.Unlock()
+	writeJSON(w, http.StatusOK, firstdue.GetOccupanciesResponse{List: page(r, occupancies), Total: len(occupancies)})
+}
+
+func (s *Server) getOccupancy(w http.ResponseWriter, r *http.Request, index int) {
+	writeJSON(w, http.StatusOK, s.occupancies[index])
+}
+
+func (s *Server) postOccupancy(w http.ResponseWriter, r *http.Request) {
+	var input firstdue.Occupancy
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.Name == "" {
+		writeFieldError(w, "name", "Name cannot be blank.")
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := firstdue.Timestamp(time.Now().UTC().Truncate(time.Second))
+	input.ID = s.newID()
+	input.CreatedAt = now
+	input.UpdatedAt = now
+	s.assignOccupancyIDs(&input)
+	s.occupancies = append(s.occupancies, input)
+	writeJSON(w, http.StatusCreated, firstdue.PostOccupanciesResponse{ID: firstdue.StringUint64(input.ID)})
+}
+
+func (s *Server) putOccupancy(w http.ResponseWriter, r *http.Request, index int) {
+	var input firstdue.Occupancy
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	existing := s.occupancies[index]
+	input.ID = existing.ID
+	if input.Name == "" {
+		input.Name = existing.Name
+	}
+	input.CreatedAt = existing.CreatedAt
+	input.UpdatedAt = firstdue.Timestamp(time.Now().UTC().Truncate(time.Second))
+	s.assignOccupancyIDs(&input)
+	s.occupancies[index] = input
+	writeJSON(w, http.StatusOK, input)
+}
+
+func (s *Server) deleteOccupancy(w http.ResponseWriter, r *http.Request, index int) {
+	s.occupancies = append(s.occupancies[:index], s.occupancies[index+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// assignOccupancyIDs gives an ID to each address, contact, and hazard that does not have one, and clears the
+// addresses' partial locations; the caller must hold the lock.
+func (s *Server) assignOccupancyIDs(occupancy *firstdue.Occupancy) {
+	for i := range occupancy.Addresses {
+		if occupancy.Addresses[i].ID == 0 {
+			occupancy.Addresses[i].ID = s.newID()
+		}
+		clearLocation(&occupancy.Addresses[i].Latitude, &occupancy.Addresses[i].Longitude)
+	}
+	for i := range occupancy.Contacts {
+		if occupancy.Contacts[i].ID == 0 {
+			occupancy.Contacts[i].ID = s.newID()
+		}
+	}
+	for i := range occupancy.Hazards {
+		if occupancy.Hazards[i].ID == 0 {
+			occupancy.Hazards[i].ID = s.newID()
+		}
+	}
+}
@@ -0,0 +1,284 @@
+// Package nfirs converts NFIRS notifications into incident records modeled on the NFIRS 5.0 modules.
+//
+// The records use this package's own caret-delimited interchange format.  It borrows the NFIRS 5.0 module names,
+// code values, and date formats, but its record types ("BI" and "AP") and field positions are not taken from the
+// NFIRS 5.0 Data Transfer Standard, so a file cannot be submitted to a state program as is.  It is meant for moving
+// drafts into a records management system that can map it.
+//
+// A file is a sequence of records, one per line, with fields delimited by a caret ("^").  Each record starts with
+// the record type and transaction type, followed by the key that identifies the incident (the FDID state, FDID,
+// incident date, station, incident number, and exposure number), followed by the fields of its module.
+//
+// Only the Basic Module (NFIRS-1) and the Apparatus or Resources Module (NFIRS-9) are covered, and only the fields
+// that can be derived from the data that is sent to First Due are filled in.
+package nfirs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+// Record types.  These are specific to this package's format.
+const (
+	RecordTypeBasic     = "BI" // Basic Module (NFIRS-1).
+	RecordTypeApparatus = "AP" // Apparatus or Resources Module (NFIRS-9).
+)
+
+const (
+	TransactionTypeAdd    = "A"
+	TransactionTypeChange = "C"
+	TransactionTypeDelete = "D"
+)
+
+const (
+	FieldDelimiter  = "^"
+	RecordDelimiter = "\r\n"
+)
+
+// dateLayout and dateTimeLayout are the NFIRS 5.0 date and date/time formats.
+const (
+	dateLayout     = "01022006"
+	dateTimeLayout = "010220061504"
+)
+
+// Aid given or received codes, as in the NFIRS 5.0 Basic Module.
+const (
+	AidMutualReceived    = "1"
+	AidAutomaticReceived = "2"
+	AidMutualGiven       = "3"
+	AidAutomaticGiven    = "4"
+	AidOtherGiven        = "5"
+	AidNone              = "N"
+)
+
+// Key identifies an incident; it is repeated at the start of every record.
+type Key struct {
+	TransactionType string
+	FDIDState       string    // 2 characters.
+	FDID            string    // 5 characters.
+	IncidentDate    time.Time // Formatted as MMDDYYYY.
+	Station         string    // Up to 3 characters.
+	IncidentNumber  string    // Up to 7 digits.
+	ExposureNumber  int       // 0 for the original incident.
+}
+
+// BasicModule is the Basic Module (NFIRS-1) portion of an incident.
+type BasicModule struct {
+	Key
+	IncidentType       string    // 3 digits.
+	AidGivenOrReceived string    // One of the Aid* constants.
+	AlarmAt            time.Time // Formatted as MMDDYYYYHHMM.
+	ArrivalAt          time.Time
+	ControlledAt       time.Time
+	LastUnitClearedAt  time.Time
+	Shift              string // 1 character.
+	Alarms             int
+	District           string // Up to 3 characters.
+	StreetNumber       string // Up to 8 characters.
+	StreetPrefix       string // Up to 2 characters.
+	StreetName         string // Up to 30 characters.
+	StreetType         string // Up to 4 characters.
+	StreetSuffix       string // Up to 2 characters.
+	Apartment          string // Up to 15 characters.
+	City               string // Up to 20 characters.
+	State              string // 2 characters.
+	ZipCode            string // Up to 9 digits.
+	CrossStreet        string // Up to 30 characters.
+}
+
+// ApparatusModule is a single apparatus from the Apparatus or Resources Module (NFIRS-9).
+type ApparatusModule struct {
+	Key
+	ApparatusID string // Up to 5 characters.
+	DispatchAt  time.Time
+	ArrivalAt   time.Time
+	ClearAt     time.Time
+	Canceled    bool // True if the apparatus was canceled before it arrived.
+}
+
+// Incident is a single incident with all of its modules.
+type Incident struct {
+	Basic       BasicModule
+	Apparatuses []ApparatusModule
+}
+
+// Options control how a notification is converted into an incident.
+type Options struct {
+	FDIDState       string         // The state of the fire department; if empty, the notification's state is used.
+	FDID            string         // The fire department ID.
+	Location        *time.Location // The time zone for all dates and times; if nil, UTC is used.
+	TransactionType string         // If empty, TransactionTypeAdd is used.
+}
+
+// FromNotification converts a notification and its apparatuses into an incident.
+func FromNotification(record firstdue.NfirsNotificationRecord, options Options) (Incident, error) {
+	var incident Incident
+
+	location := options.Location
+	if location == nil {
+		location = time.UTC
+	}
+	// NFIRS times only go down to the minute.
+	localTime := func(t firstdue.Timestamp) time.Time {
+		if t.IsZero() {
+			return time.Time{}
+		}
+		return time.Time(t).In(location).Truncate(time.Minute)
+	}
+
+	notification := record.Notification
+	key := Key{
+		TransactionType: options.TransactionType,
+		FDIDState:       strings.ToUpper(options.FDIDState),
+		FDID:            options.FDID,
+		IncidentDate:    localDate(localTime(notification.AlarmAt)),
+		Station:         truncate(deref(notification.Station), 3),
+		IncidentNumber:  digits(notification.IncidentNumber),
+	}
+	if key.TransactionType == "" {
+		key.TransactionType = TransactionTypeAdd
+	}
+	if key.FDIDState == "" {
+		key.FDIDState = strings.ToUpper(notification.StateCode)
+	}
+	if len(key.FDIDState) != 2 {
+		return incident, fmt.Errorf("invalid FDID state: %q", key.FDIDState)
+	}
+	if key.FDID == "" || len(key.FDID) > 5 {
+		return incident, fmt.Errorf("invalid FDID: %q", key.FDID)
+	}
+	if key.IncidentDate.IsZero() {
+		return incident, fmt.Errorf("missing alarm time")
+	}
+	// The incident number is not shortened, since two incident numbers could then end up with the same key.
+	if key.IncidentNumber == "" || len(key.IncidentNumber) > 7 {
+		return incident, fmt.Errorf("invalid incident number: %q: it must have from 1 to 7 digits", notification.IncidentNumber)
+	}
+
+	incident.Basic = BasicModule{
+		Key:                key,
+		IncidentType:       incidentType(notification.DispatchIncidentTypeCode),
+		AidGivenOrReceived: aidCode(notification.AidTypeCode),
+		AlarmAt:            localTime(notification.AlarmAt),
+		Shift:              truncate(strings.ToUpper(deref(notification.ShiftName)), 1),
+		Alarms:             notification.Alarms,
+		District:           truncate(deref(notification.Zone), 3),
+		StreetNumber:       truncate(deref(notification.HouseNum), 8),
+		StreetPrefix:       truncate(strings.ToUpper(deref(notification.PrefixDirection)), 2),
+		StreetName:         truncate(strings.ToUpper(deref(notification.StreetName)), 30),
+		StreetType:         truncate(strings.ToUpper(deref(notification.StreetType)), 4),
+		StreetSuffix:       truncate(strings.ToUpper(deref(notification.SuffixDirection)), 2),
+		Apartment:          truncate(strings.ToUpper(deref(notification.Unit)), 15),
+		City:               truncate(strings.ToUpper(notification.City), 20),
+		State:              truncate(strings.ToUpper(notification.StateCode), 2),
+		ZipCode:            truncate(digits(deref(notification.ZipCode)), 9),
+		CrossStreet:        truncate(strings.ToUpper(notification.CrossStreets), 30),
+	}
+	if notification.ControlledAt != nil {
+		incident.Basic.ControlledAt = localTime(*notification.ControlledAt)
+	}
+	if incident.Basic.StreetName == "" {
+		// Fall back to the free-form address when the address was not broken down into its parts.
+		incident.Basic.StreetName = truncate(strings.ToUpper(notification.Address), 30)
+	}
+
+	seen := map[string]string{}
+	for _, apparatus := range record.Apparatuses {
+		// The apparatus ID is not shortened either, since two units could then end up with the same ID.
+		apparatusID := clean(strings.ToUpper(apparatus.UnitCode))
+		switch {
+		case apparatusID == "":
+			return incident, fmt.Errorf("apparatus is missing its unit code")
+		case len([]rune(apparatusID)) > 5:
+			return incident, fmt.Errorf("invalid unit code: %q: it must have at most 5 characters", apparatus.UnitCode)
+		case seen[apparatusID] != "":
+			return incident, fmt.Errorf("unit codes %q and %q are the same apparatus ID", seen[apparatusID], apparatus.UnitCode)
+		}
+		seen[apparatusID] = apparatus.UnitCode
+		module := ApparatusModule{
+			Key:         key,
+			ApparatusID: apparatusID,
+			DispatchAt:  localTime(apparatus.DispatchAt),
+			ArrivalAt:   localTime(apparatus.ArriveAt),
+			ClearAt:     localTime(apparatus.ClearAt),
+			Canceled:    !apparatus.CanceledAt.IsZero() && apparatus.ArriveAt.IsZero(),
+		}
+		incident.Apparatuses = append(incident.Apparatuses, module)
+
+		// The basic module wants the first arrival and the last clear time across all of the units.
+		if !module.ArrivalAt.IsZero() && (incident.Basic.ArrivalAt.IsZero() || module.ArrivalAt.Before(incident.Basic.ArrivalAt)) {
+			incident.Basic.ArrivalAt = module.ArrivalAt
+		}
+		if module.ClearAt.After(incident.Basic.LastUnitClearedAt) {
+			incident.Basic.LastUnitClearedAt = module.ClearAt
+		}
+	}
+
+	return incident, nil
+}
+
+// localDate returns midnight of the day of the given time.
+func localDate(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// incidentType returns the dispatch incident type if it is already an NFIRS incident type code.
+//
+// CAD incident types that are not three-digit NFIRS codes are left blank so that they can be coded later.
+func incidentType(code string) string {
+	code = strings.TrimSpace(code)
+	if len(code) == 3 && digits(code) == code {
+		return code
+	}
+	return ""
+}
+
+// aidCode maps a First Due aid type code to the NFIRS aid given or received code.
+//
+// The First Due API does not document its aid type codes; this assumes that they use the NFIRS numbering (1 through
+// 5), which matches the values seen so far.  Anything else is reported as no aid.
+func aidCode(code *int) string {
+	if code == nil || *code < 1 || *code > 5 {
+		return AidNone
+	}
+	return fmt.Sprintf("%d", *code)
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return strings.TrimSpace(*value)
+}
+
+func digits(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, value)
+}
+
+// truncate cleans the value and limits it to the given number of characters.
+func truncate(value string, length int) string {
+	runes := []rune(clean(value))
+	if len(runes) > length {
+		runes = runes[:length]
+	}
+	return string(runes)
+}
+
+// clean removes the delimiters, since they cannot be escaped, and trims the value.
+func clean(value string) string {
+	value = strings.NewReplacer(FieldDelimiter, "", "\r", " ", "\n", " ").Replace(value)
+	return strings.TrimSpace(value)
+}
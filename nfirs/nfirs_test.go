@@ -0,0 +1,209 @@
+package nfirs
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tekkamanendless/firstdue"
+)
+
+func timestamp(value string) firstdue.Timestamp {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return firstdue.Timestamp(t)
+}
+
+func text(value string) *string {
+	return &value
+}
+
+// roundTrip writes the incidents and reads them back.
+func roundTrip(t *testing.T, incidents ...Incident) (string, []Incident) {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer := NewWriter(&buffer)
+	for _, incident := range incidents {
+		if err := writer.Write(incident); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	parsed, err := NewReader(strings.NewReader(buffer.String()), time.UTC).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v\n%s", err, buffer.String())
+	}
+	return buffer.String(), parsed
+}
+
+func TestRoundTrip(t *testing.T) {
+	aid := 3
+	record := firstdue.NfirsNotificationRecord{
+		Notification: firstdue.NfirsNotification{
+			DispatchNumber:           "F2400123",
+			IncidentNumber:           "24-00123",
+			DispatchIncidentTypeCode: "111",
+			AlarmAt:                  timestamp("2024-03-05T14:07:30Z"),
+			Alarms:                   2,
+			Address:                  "123 N Main St",
+			HouseNum:                 text("123"),
+			PrefixDirection:          text("n"),
+			StreetName:               text("Main"),
+			StreetType:               text("St"),
+			Unit:                     text("Apt 2"),
+			City:                     "Springfield",
+			StateCode:                "il",
+			ZipCode:                  text("62701-1234"),
+			CrossStreets:             "E Washington St",
+			Station:                  text("1"),
+			ShiftName:                text("b"),
+			AidTypeCode:              &aid,
+		},
+		Apparatuses: []firstdue.NfirsNotificationApparatus{
+			{UnitCode: "e1", DispatchAt: timestamp("2024-03-05T14:08:00Z"), ArriveAt: timestamp("2024-03-05T14:13:00Z"), ClearAt: timestamp("2024-03-05T15:40:00Z")},
+			{UnitCode: "L1", DispatchAt: timestamp("2024-03-05T14:08:00Z"), ArriveAt: timestamp("2024-03-05T14:11:00Z"), ClearAt: timestamp("2024-03-05T15:55:00Z")},
+			{UnitCode: "M1", DispatchAt: timestamp("2024-03-05T14:08:00Z"), CanceledAt: timestamp("2024-03-05T14:10:00Z")},
+		},
+	}
+	incident, err := FromNotification(record, Options{FDID: "12345"})
+	if err != nil {
+		t.Fatalf("FromNotification: %v", err)
+	}
+
+	output, parsed := roundTrip(t, incident)
+	if len(parsed) != 1 || !reflect.DeepEqual(parsed[0], incident) {
+		t.Fatalf("round trip mismatch:\nwrote %+v\nread  %+v", incident, parsed)
+	}
+
+	lines := strings.Split(output, RecordDelimiter)
+	if len(lines) != 5 || lines[4] != "" {
+		t.Fatalf("expected 4 CRLF-terminated records, got %q", output)
+	}
+	if want := "BI^A^IL^12345^03052024^1^2400123^000^111^3^030520241407^030520241411^^030520241555^B^2^^123^N^MAIN^ST^^APT 2^SPRINGFIELD^IL^627011234^E WASHINGTON ST"; lines[0] != want {
+		t.Errorf("basic record:\n got %s\nwant %s", lines[0], want)
+	}
+	if want := "AP^A^IL^12345^03052024^1^2400123^000^M1^030520241408^^^Y"; lines[3] != want {
+		t.Errorf("canceled apparatus record:\n got %s\nwant %s", lines[3], want)
+	}
+}
+
+func TestRoundTripMultipleIncidentsWithLF(t *testing.T) {
+	first := Incident{Basic: BasicModule{Key: Key{TransactionType: TransactionTypeAdd, FDIDState: "IL", FDID: "12345", IncidentDate: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), IncidentNumber: "1"}}}
+	second := first
+	second.Basic.Key.IncidentNumber = "2"
+	second.Apparatuses = []ApparatusModule{{Key: second.Basic.Key, ApparatusID: "E1"}}
+
+	output, _ := roundTrip(t, first, second)
+	parsed, err := NewReader(strings.NewReader(strings.ReplaceAll(output, "\r\n", "\n")), nil).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if !reflect.DeepEqual(parsed, []Incident{first, second}) {
+		t.Fatalf("round trip mismatch:\n%+v", parsed)
+	}
+}
+
+func TestCaretsInValues(t *testing.T) {
+	record := firstdue.NfirsNotificationRecord{
+		Notification: firstdue.NfirsNotification{
+			IncidentNumber: "77",
+			AlarmAt:        timestamp("2024-03-05T14:07:30Z"),
+			Address:        "1^2 Main^St",
+			CrossStreets:   "Oak\r\nElm^",
+			City:           "Spring^field",
+			StateCode:      "IL",
+		},
+		Apparatuses: []firstdue.NfirsNotificationApparatus{{UnitCode: "E^1"}},
+	}
+	incident, err := FromNotification(record, Options{FDID: "12345"})
+	if err != nil {
+		t.Fatalf("FromNotification: %v", err)
+	}
+	if incident.Basic.StreetName != "12 MAINST" || incident.Basic.CrossStreet != "OAK  ELM" || incident.Basic.City != "SPRINGFIELD" {
+		t.Errorf("delimiters were not removed: %+v", incident.Basic)
+	}
+	_, parsed := roundTrip(t, incident)
+	if !reflect.DeepEqual(parsed, []Incident{incident}) {
+		t.Fatalf("round trip mismatch:\n%+v", parsed)
+	}
+
+	// A module that is built by hand is checked rather than silently corrupted.
+	incident.Basic.StreetName = "A^B"
+	if err := NewWriter(&bytes.Buffer{}).Write(incident); err == nil {
+		t.Errorf("expected an error for a caret inside a value")
+	}
+}
+
+func TestNilPointers(t *testing.T) {
+	record := firstdue.NfirsNotificationRecord{
+		Notification: firstdue.NfirsNotification{
+			IncidentNumber: "5",
+			AlarmAt:        timestamp("2024-03-05T23:30:00Z"),
+			Address:        "9 Elm St",
+			StateCode:      "IL",
+		},
+	}
+	location, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	incident, err := FromNotification(record, Options{FDID: "12345", Location: location})
+	if err != nil {
+		t.Fatalf("FromNotification: %v", err)
+	}
+	if incident.Basic.AidGivenOrReceived != AidNone || incident.Basic.StreetName != "9 ELM ST" || incident.Basic.Station != "" {
+		t.Errorf("unexpected defaults: %+v", incident.Basic)
+	}
+	var buffer bytes.Buffer
+	if err := NewWriter(&buffer).Write(incident); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	parsed, err := NewReader(&buffer, location).ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if len(parsed) != 1 || !parsed[0].Basic.AlarmAt.Equal(incident.Basic.AlarmAt) || parsed[0].Basic.IncidentDate.Day() != 5 {
+		t.Fatalf("round trip mismatch:\n%+v", parsed)
+	}
+}
+
+func TestInvalidKeys(t *testing.T) {
+	for name, record := range map[string]firstdue.NfirsNotificationRecord{
+		"long incident number": {Notification: firstdue.NfirsNotification{IncidentNumber: "2024-000123"}},
+		"no incident number":   {Notification: firstdue.NfirsNotification{IncidentNumber: "ABC"}},
+		"long unit code":       {Apparatuses: []firstdue.NfirsNotificationApparatus{{UnitCode: "ENGINE1"}}},
+		"duplicate unit code":  {Apparatuses: []firstdue.NfirsNotificationApparatus{{UnitCode: "E1"}, {UnitCode: " e1"}}},
+		"empty unit code":      {Apparatuses: []firstdue.NfirsNotificationApparatus{{UnitCode: "^"}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if record.Notification.IncidentNumber == "" {
+				record.Notification.IncidentNumber = "1"
+			}
+			record.Notification.AlarmAt = timestamp("2024-03-05T14:07:30Z")
+			record.Notification.StateCode = "IL"
+			if _, err := FromNotification(record, Options{FDID: "12345"}); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	for name, input := range map[string]string{
+		"apparatus first":    "AP^A^IL^12345^03052024^^1^000^E1^^^^N\r\n",
+		"short record":       "BI^A^IL^12345\r\n",
+		"bad date":           "BI^A^IL^12345^13452024^^1^000" + strings.Repeat("^", 19) + "\r\n",
+		"foreign apparatus":  "BI^A^IL^12345^03052024^^1^000" + strings.Repeat("^", 19) + "\r\nAP^A^IL^12345^03052024^^2^000^E1^^^^N\r\n",
+		"bad canceled flag":  "BI^A^IL^12345^03052024^^1^000" + strings.Repeat("^", 19) + "\r\nAP^A^IL^12345^03052024^^1^000^E1^^^^X\r\n",
+		"unknown recordtype": "BI^A^IL^12345^03052024^^1^000" + strings.Repeat("^", 19) + "\r\nZZ^A\r\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewReader(strings.NewReader(input), nil).ReadAll(); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package nfirs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Number of fields in each kind of record, including the key.
+const (
+	keyFieldCount       = 8
+	basicFieldCount     = keyFieldCount + 19
+	apparatusFieldCount = keyFieldCount + 5
+)
+
+// Writer writes incidents as records in the format described in the package documentation.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w: w,
+	}
+}
+
+// Write writes the basic module record of the incident, followed by a record for each of its apparatuses.
+func (w *Writer) Write(incident Incident) error {
+	basic := incident.Basic
+	fields := append(keyFields(RecordTypeBasic, basic.Key),
+		basic.IncidentType,
+		basic.AidGivenOrReceived,
+		formatDateTime(basic.AlarmAt),
+		formatDateTime(basic.ArrivalAt),
+		formatDateTime(basic.ControlledAt),
+		formatDateTime(basic.LastUnitClearedAt),
+		basic.Shift,
+		strconv.Itoa(basic.Alarms),
+		basic.District,
+		basic.StreetNumber,
+		basic.StreetPrefix,
+		basic.StreetName,
+		basic.StreetType,
+		basic.StreetSuffix,
+		basic.Apartment,
+		basic.City,
+		basic.State,
+		basic.ZipCode,
+		basic.CrossStreet,
+	)
+	if err := w.writeRecord(fields); err != nil {
+		return err
+	}
+
+	for _, apparatus := range incident.Apparatuses {
+		canceled := "N"
+		if apparatus.Canceled {
+			canceled = "Y"
+		}
+		fields := append(keyFields(RecordTypeApparatus, apparatus.Key),
+			apparatus.ApparatusID,
+			formatDateTime(apparatus.DispatchAt),
+			formatDateTime(apparatus.ArrivalAt),
+			formatDateTime(apparatus.ClearAt),
+			canceled,
+		)
+		if err := w.writeRecord(fields); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *Writer) writeRecord(fields []string) error {
+	for i, field := range fields {
+		if strings.ContainsAny(field, FieldDelimiter+RecordDelimiter) {
+			return fmt.Errorf("field %d contains a delimiter: %q", i+1, field)
+		}
+	}
+	_, err := io.WriteString(w.w, strings.Join(fields, FieldDelimiter)+RecordDelimiter)
+	return err
+}
+
+func keyFields(recordType string, key Key) []string {
+	return []string{
+		recordType,
+		key.TransactionType,
+		key.FDIDState,
+		key.FDID,
+		formatDate(key.IncidentDate),
+		key.Station,
+		key.IncidentNumber,
+		fmt.Sprintf("%03d", key.ExposureNumber),
+	}
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(dateLayout)
+}
+
+func formatDateTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(dateTimeLayout)
+}
+
+// Reader reads incidents from records in the format described in the package documentation.
+type Reader struct {
+	scanner  *bufio.Scanner
+	location *time.Location
+	line     int
+	pending  []string // A basic module record that was read ahead while looking for the end of the previous incident.
+}
+
+// NewReader returns a reader that reads from r.
+//
+// Dates and times are interpreted in the given location; if nil, UTC is used.
+func NewReader(r io.Reader, location *time.Location) *Reader {
+	if location == nil {
+		location = time.UTC
+	}
+	return &Reader{
+		scanner:  bufio.NewScanner(r),
+		location: location,
+	}
+}
+
+// Read returns the next incident, or io.EOF if there are no more.
+//
+// Each incident starts with a basic module record, and every apparatus record that follows it belongs to it.
+func (r *Reader) Read() (Incident, error) {
+	var incident Incident
+
+	fields := r.pending
+	r.pending = nil
+	if fields == nil {
+		var err error
+		fields, err = r.readRecord()
+		if err != nil {
+			return incident, err
+		}
+	}
+	if fields[0] != RecordTypeBasic {
+		return incident, fmt.Errorf("line %d: expected a %s record, not %q", r.line, RecordTypeBasic, fields[0])
+	}
+	basic, err := r.parseBasic(fields)
+	if err != nil {
+		return incident, fmt.Errorf("line %d: %w", r.line, err)
+	}
+	incident.Basic = basic
+
+	for {
+		fields, err := r.readRecord()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return incident, err
+		}
+		if fields[0] == RecordTypeBasic {
+			r.pending = fields
+			break
+		}
+		if fields[0] != RecordTypeApparatus {
+			return incident, fmt.Errorf("line %d: unsupported record type: %q", r.line, fields[0])
+		}
+		apparatus, err := r.parseApparatus(fields)
+		if err != nil {
+			return incident, fmt.Errorf("line %d: %w", r.line, err)
+		}
+		if apparatus.Key != basic.Key {
+			return incident, fmt.Errorf("line %d: apparatus record does not belong to the incident", r.line)
+		}
+		incident.Apparatuses = append(incident.Apparatuses, apparatus)
+	}
+	return incident, nil
+}
+
+// ReadAll reads all of the remaining incidents.
+func (r *Reader) ReadAll() ([]Incident, error) {
+	var incidents []Incident
+	for {
+		incident, err := r.Read()
+		if err == io.EOF {
+			return incidents, nil
+		}
+		if err != nil {
+			return incidents, err
+		}
+		incidents = append(incidents, incident)
+	}
+}
+
+// readRecord returns the fields of the next non-blank record.
+func (r *Reader) readRecord() ([]string, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimRight(r.scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		return strings.Split(line, FieldDelimiter), nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func (r *Reader) parseKey(fields []string) (Key, error) {
+	var key Key
+	var err error
+	key.TransactionType = fields[1]
+	key.FDIDState = fields[2]
+	key.FDID = fields[3]
+	key.IncidentDate, err = r.parseTime(dateLayout, fields[4])
+	if err != nil {
+		return key, fmt.Errorf("invalid incident date: %w", err)
+	}
+	key.Station = fields[5]
+	key.IncidentNumber = fields[6]
+	key.ExposureNumber, err = strconv.Atoi(fields[7])
+	if err != nil {
+		return key, fmt.Errorf("invalid exposure number: %w", err)
+	}
+	return key, nil
+}
+
+func (r *Reader) parseBasic(fields []string) (BasicModule, error) {
+	var basic BasicModule
+	if len(fields) != basicFieldCount {
+		return basic, fmt.Errorf("expected %d fields, found %d", basicFieldCount, len(fields))
+	}
+	var err error
+	basic.Key, err = r.parseKey(fields)
+	if err != nil {
+		return basic, err
+	}
+	fields = fields[keyFieldCount:]
+	basic.IncidentType = fields[0]
+	basic.AidGivenOrReceived = fields[1]
+	for i, target := range []*time.Time{&basic.AlarmAt, &basic.ArrivalAt, &basic.ControlledAt, &basic.LastUnitClearedAt} {
+		*target, err = r.parseTime(dateTimeLayout, fields[2+i])
+		if err != nil {
+			return basic, fmt.Errorf("invalid date/time in field %d: %w", keyFieldCount+3+i, err)
+		}
+	}
+	basic.Shift = fields[6]
+	if fields[7] != "" {
+		basic.Alarms, err = strconv.Atoi(fields[7])
+		if err != nil {
+			return basic, fmt.Errorf("invalid number of alarms: %w", err)
+		}
+	}
+	basic.District = fields[8]
+	basic.StreetNumber = fields[9]
+	basic.StreetPrefix = fields[10]
+	basic.StreetName = fields[11]
+	basic.StreetType = fields[12]
+	basic.StreetSuffix = fields[13]
+	basic.Apartment = fields[14]
+	basic.City = fields[15]
+	basic.State = fields[16]
+	basic.ZipCode = fields[17]
+	basic.CrossStreet = fields[18]
+	return basic, nil
+}
+
+func (r *Reader) parseApparatus(fields []string) (ApparatusModule, error) {
+	var apparatus ApparatusModule
+	if len(fields) != apparatusFieldCount {
+		return apparatus, fmt.Errorf("expected %d fields, found %d", apparatusFieldCount, len(fields))
+	}
+	var err error
+	apparatus.Key, err = r.parseKey(fields)
+	if err != nil {
+		return apparatus, err
+	}
+	fields = fields[keyFieldCount:]
+	apparatus.ApparatusID = fields[0]
+	for i, target := range []*time.Time{&apparatus.DispatchAt, &apparatus.ArrivalAt, &apparatus.ClearAt} {
+		*target, err = r.parseTime(dateTimeLayout, fields[1+i])
+		if err != nil {
+			return apparatus, fmt.Errorf("invalid date/time in field %d: %w", keyFieldCount+2+i, err)
+		}
+	}
+	switch fields[4] {
+	case "Y":
+		apparatus.Canceled = true
+	case "N", "":
+	default:
+		return apparatus, fmt.Errorf("invalid canceled flag: %q", fields[4])
+	}
+	return apparatus, nil
+}
+
+func (r *Reader) parseTime(layout string, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation(layout, value, r.location)
+}
@@ -0,0 +1,96 @@
+package firstdue
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// ErrTotalMismatch is returned by the paging iterators when the number of items collected does not match the
+// total reported by the API.
+var ErrTotalMismatch = errors.New("number of items does not match the total")
+
+// paginate iterates over every item of every page, starting at page 1.
+//
+// The fetch function returns the items on the given page and the total number of items across all pages.
+// Iteration stops at the first empty page or once the total has been reached, and then the number of items is
+// checked against the total.  As with paginateUntilEmpty, a page whose first item is the same as the previous page's
+// is an error, so an API that ignores the page cannot make up the total with copies.
+func paginate[T any](fetch func(page int) (list []T, total int, err error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		var previous T
+		count := 0
+		for page := 1; ; page++ {
+			list, total, err := fetch(page)
+			if err != nil {
+				yield(zero, fmt.Errorf("page %d: %w", page, err))
+				return
+			}
+			if page > 1 && len(list) > 0 && reflect.DeepEqual(list[0], previous) {
+				yield(zero, fmt.Errorf("page %d: the page repeats the previous page", page))
+				return
+			}
+			if len(list) > 0 {
+				previous = list[0]
+			}
+			for _, item := range list {
+				count++
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(list) == 0 || count >= total {
+				if count != total {
+					yield(zero, fmt.Errorf("%w: collected %d, expected %d", ErrTotalMismatch, count, total))
+				}
+				return
+			}
+		}
+	}
+}
+
+// paginateUntilEmpty iterates over every item of every page, starting at page 1, for the endpoints that do not
+// report a total.
+//
+// Iteration stops at the first empty page.  To avoid looping forever on an API that ignores the page, a page whose
+// first item is the same as the previous page's is an error.
+func paginateUntilEmpty[T any, K comparable](fetch func(page int) ([]T, error), key func(T) K) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		var previous K
+		for page := 1; ; page++ {
+			list, err := fetch(page)
+			if err != nil {
+				yield(zero, fmt.Errorf("page %d: %w", page, err))
+				return
+			}
+			if len(list) == 0 {
+				return
+			}
+			if page > 1 && key(list[0]) == previous {
+				yield(zero, fmt.Errorf("page %d: the page repeats the previous page", page))
+				return
+			}
+			previous = key(list[0])
+			for _, item := range list {
+				if !yield(item, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// collect gathers every item from a paging iterator, stopping at the first error.
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
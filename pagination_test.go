@@ -0,0 +1,121 @@
+package firstdue
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakePages serves the items in pages of the given size, as an API would.
+type fakePages struct {
+	items      []int
+	size       int
+	total      int   // The total to report.
+	emptyPage  int   // If set, this page is empty.
+	ignorePage bool  // If set, the first page is always returned.
+	failPage   int   // If set, fetching this page fails.
+	fetched    []int // The pages that were fetched.
+}
+
+func (f *fakePages) fetch(page int) ([]int, int, error) {
+	f.fetched = append(f.fetched, page)
+	if page == f.failPage {
+		return nil, 0, errors.New("server error")
+	}
+	if page == f.emptyPage {
+		return nil, f.total, nil
+	}
+	if f.ignorePage {
+		page = 1
+	}
+	start := min((page-1)*f.size, len(f.items))
+	end := min(start+f.size, len(f.items))
+	return f.items[start:end], f.total, nil
+}
+
+func (f *fakePages) fetchWithoutTotal(page int) ([]int, error) {
+	list, _, err := f.fetch(page)
+	return list, err
+}
+
+// numbers returns the numbers from 1 to n.
+func numbers(n int) []int {
+	var items []int
+	for i := 1; i <= n; i++ {
+		items = append(items, i)
+	}
+	return items
+}
+
+func TestPaginate(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		pages   fakePages
+		count   int    // The number of items collected.
+		fetched []int  // The pages fetched.
+		err     string // The expected error, if any.
+	}{
+		{"total matches", fakePages{items: numbers(45), size: 20, total: 45}, 45, []int{1, 2, 3}, ""},
+		{"total matches a full page", fakePages{items: numbers(40), size: 20, total: 40}, 40, []int{1, 2}, ""},
+		{"nothing", fakePages{size: 20}, 0, []int{1}, ""},
+		{"short total", fakePages{items: numbers(45), size: 20, total: 30}, 40, []int{1, 2}, "number of items does not match the total: collected 40, expected 30"},
+		{"long total", fakePages{items: numbers(45), size: 20, total: 50}, 45, []int{1, 2, 3, 4}, "number of items does not match the total: collected 45, expected 50"},
+		{"empty page before the total", fakePages{items: numbers(45), size: 20, total: 45, emptyPage: 2}, 20, []int{1, 2}, "number of items does not match the total: collected 20, expected 45"},
+		{"page ignored", fakePages{items: numbers(45), size: 20, total: 40, ignorePage: true}, 20, []int{1, 2}, "page 2: the page repeats the previous page"},
+		{"error", fakePages{items: numbers(45), size: 20, total: 45, failPage: 2}, 20, []int{1, 2}, "page 2: server error"},
+	} {
+		items, err := collect(paginate(test.pages.fetch))
+		if test.err == "" && err != nil || test.err != "" && (err == nil || err.Error() != test.err) {
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.err)
+		}
+		if strings.HasPrefix(test.err, "number of items") && !errors.Is(err, ErrTotalMismatch) {
+			t.Errorf("%s: the error does not wrap ErrTotalMismatch", test.name)
+		}
+		if len(items) != test.count || (test.err == "" && !reflect.DeepEqual(items, test.pages.items[:test.count])) {
+			t.Errorf("%s: got %d items, want %d", test.name, len(items), test.count)
+		}
+		if !reflect.DeepEqual(test.pages.fetched, test.fetched) {
+			t.Errorf("%s: fetched pages %v, want %v", test.name, test.pages.fetched, test.fetched)
+		}
+	}
+
+	// Stopping early fetches no more pages.
+	pages := fakePages{items: numbers(45), size: 20, total: 45}
+	for item := range paginate(pages.fetch) {
+		if item == 5 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(pages.fetched, []int{1}) {
+		t.Errorf("fetched pages %v after stopping early", pages.fetched)
+	}
+}
+
+func TestPaginateUntilEmpty(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		pages   fakePages
+		count   int
+		fetched []int
+		err     string
+	}{
+		{"several pages", fakePages{items: numbers(45), size: 20}, 45, []int{1, 2, 3, 4}, ""},
+		{"full pages", fakePages{items: numbers(40), size: 20}, 40, []int{1, 2, 3}, ""},
+		{"nothing", fakePages{size: 20}, 0, []int{1}, ""},
+		{"empty page", fakePages{items: numbers(45), size: 20, emptyPage: 2}, 20, []int{1, 2}, ""},
+		{"page ignored", fakePages{items: numbers(45), size: 20, ignorePage: true}, 20, []int{1, 2}, "page 2: the page repeats the previous page"},
+		{"error", fakePages{items: numbers(45), size: 20, failPage: 3}, 40, []int{1, 2, 3}, "page 3: server error"},
+	} {
+		items, err := collect(paginateUntilEmpty(test.pages.fetchWithoutTotal, func(item int) int { return item }))
+		if test.err == "" && err != nil || test.err != "" && (err == nil || err.Error() != test.err) {
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.err)
+		}
+		if len(items) != test.count || !reflect.DeepEqual(items, test.pages.items[:test.count]) {
+			t.Errorf("%s: got %d items, want %d", test.name, len(items), test.count)
+		}
+		if !reflect.DeepEqual(test.pages.fetched, test.fetched) {
+			t.Errorf("%s: fetched pages %v, want %v", test.name, test.pages.fetched, test.fetched)
+		}
+	}
+}
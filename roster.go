@@ -0,0 +1,230 @@
+package firstdue
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Roster is a cached index of the apparatuses and stations for an account.
+//
+// Dispatches reference units by their unit codes, so the roster makes it possible to resolve those codes back to
+// the apparatuses that First Due knows about.  All lookups are case-insensitive.
+//
+// A Roster is safe for concurrent use.
+type Roster struct {
+	client *Client
+
+	mutex              sync.RWMutex
+	loadedAt           time.Time
+	apparatuses        []GetApparatusesResponseApparatus
+	stations           []GetStationsResponseStation
+	apparatusUnitCodes map[string]int // Maps a normalized unit code to an index in apparatuses, or -1 if it is ambiguous.
+	apparatusUUIDs     map[string]int // Maps a normalized UUID to an index in apparatuses, or -1 if it is ambiguous.
+	apparatusNames     map[string]int // Maps a normalized name to an index in apparatuses, or -1 if it is ambiguous.
+	stationUUIDs       map[string]int // Maps a normalized UUID to an index in stations, or -1 if it is ambiguous.
+	stationNames       map[string]int // Maps a normalized name to an index in stations, or -1 if it is ambiguous.
+}
+
+// NewRoster returns a new, empty roster that will be loaded using the given client.
+//
+// Call Refresh to load it, or Run to keep it up to date.
+func NewRoster(client *Client) *Roster {
+	return &Roster{
+		client: client,
+	}
+}
+
+// Refresh loads the apparatuses and stations and rebuilds the indexes.
+//
+// If loading fails, the previous contents of the roster are kept.
+func (r *Roster) Refresh(ctx context.Context) error {
+	apparatuses, err := r.client.GetAllApparatuses(ctx, GetApparatusesRequest{})
+	if err != nil {
+		return fmt.Errorf("roster: %w", err)
+	}
+	stations, err := r.client.GetAllStations(ctx, GetStationsRequest{})
+	if err != nil {
+		return fmt.Errorf("roster: %w", err)
+	}
+	r.Load(apparatuses, stations)
+	return nil
+}
+
+// Load replaces the contents of the roster with the given apparatuses and stations.
+//
+// A key that is shared by more than one apparatus or station, such as two apparatuses with the unit codes "E1" and
+// "e1", is ambiguous: it is logged and left out of its index, so looking it up finds nothing.
+func (r *Roster) Load(apparatuses []GetApparatusesResponseApparatus, stations []GetStationsResponseStation) {
+	apparatusUnitCodes := map[string]int{}
+	apparatusUUIDs := map[string]int{}
+	apparatusNames := map[string]int{}
+	for i, apparatus := range apparatuses {
+		if apparatus.UnitCode != nil {
+			addRosterKey(apparatusUnitCodes, "apparatus unit code", *apparatus.UnitCode, i)
+		}
+		addRosterKey(apparatusUUIDs, "apparatus UUID", apparatus.UUID, i)
+		addRosterKey(apparatusNames, "apparatus name", apparatus.Name, i)
+	}
+	stationUUIDs := map[string]int{}
+	stationNames := map[string]int{}
+	for i, station := range stations {
+		addRosterKey(stationUUIDs, "station UUID", station.UUID, i)
+		addRosterKey(stationNames, "station name", station.Name, i)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.loadedAt = time.Now()
+	r.apparatuses = apparatuses
+	r.stations = stations
+	r.apparatusUnitCodes = apparatusUnitCodes
+	r.apparatusUUIDs = apparatusUUIDs
+	r.apparatusNames = apparatusNames
+	r.stationUUIDs = stationUUIDs
+	r.stationNames = stationNames
+}
+
+// Run refreshes the roster immediately and then at the given interval until the context is canceled.
+//
+// Refresh failures are logged; the roster keeps serving the last successfully-loaded data.
+func (r *Roster) Run(ctx context.Context, interval time.Duration) {
+	if err := r.Refresh(ctx); err != nil {
+		slog.WarnContext(ctx, "Could not refresh the roster.", "error", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Refresh(ctx); err != nil {
+				slog.WarnContext(ctx, "Could not refresh the roster.", "error", err)
+			}
+		}
+	}
+}
+
+// LoadedAt returns the time of the last successful load, or the zero time if the roster has never been loaded.
+func (r *Roster) LoadedAt() time.Time {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.loadedAt
+}
+
+// Apparatuses returns a copy of all of the apparatuses in the roster.
+func (r *Roster) Apparatuses() []GetApparatusesResponseApparatus {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return append([]GetApparatusesResponseApparatus(nil), r.apparatuses...)
+}
+
+// Stations returns a copy of all of the stations in the roster.
+func (r *Roster) Stations() []GetStationsResponseStation {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return append([]GetStationsResponseStation(nil), r.stations...)
+}
+
+// ApparatusByUnitCode returns the apparatus with the given unit code.
+func (r *Roster) ApparatusByUnitCode(unitCode string) (GetApparatusesResponseApparatus, bool) {
+	return r.lookupApparatus(&r.apparatusUnitCodes, unitCode)
+}
+
+// ApparatusByUUID returns the apparatus with the given UUID.
+func (r *Roster) ApparatusByUUID(uuid string) (GetApparatusesResponseApparatus, bool) {
+	return r.lookupApparatus(&r.apparatusUUIDs, uuid)
+}
+
+// ApparatusByName returns the apparatus with the given name.
+func (r *Roster) ApparatusByName(name string) (GetApparatusesResponseApparatus, bool) {
+	return r.lookupApparatus(&r.apparatusNames, name)
+}
+
+// StationByUUID returns the station with the given UUID.
+func (r *Roster) StationByUUID(uuid string) (GetStationsResponseStation, bool) {
+	return r.lookupStation(&r.stationUUIDs, uuid)
+}
+
+// StationByName returns the station with the given name.
+func (r *Roster) StationByName(name string) (GetStationsResponseStation, bool) {
+	return r.lookupStation(&r.stationNames, name)
+}
+
+// UnmappedUnitCodes returns the unit codes that do not map to any apparatus in the roster.
+//
+// This is typically called with the `UnitCodes` of a dispatch.
+func (r *Roster) UnmappedUnitCodes(unitCodes []string) []string {
+	var unmapped []string
+	for _, unitCode := range unitCodes {
+		if _, ok := r.ApparatusByUnitCode(unitCode); !ok {
+			unmapped = append(unmapped, unitCode)
+		}
+	}
+	return unmapped
+}
+
+// NotificationApparatus returns an apparatus entry for an NFIRS notification, and whether the unit code maps to an
+// apparatus in the roster.
+//
+// If it does, then the apparatus's own unit code is used so that the casing matches what First Due expects.
+// Otherwise, the entry has the trimmed unit code as given; the caller decides what to do with it, for example marking
+// it as aid from another department or leaving it out.
+func (r *Roster) NotificationApparatus(unitCode string) (NfirsNotificationApparatus, bool) {
+	apparatus, ok := r.ApparatusByUnitCode(unitCode)
+	if !ok {
+		return NfirsNotificationApparatus{
+			UnitCode: strings.TrimSpace(unitCode),
+		}, false
+	}
+	return NfirsNotificationApparatus{
+		UnitCode: *apparatus.UnitCode,
+	}, true
+}
+
+func (r *Roster) lookupApparatus(index *map[string]int, key string) (GetApparatusesResponseApparatus, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	i, ok := (*index)[normalizeRosterKey(key)]
+	if !ok || i < 0 {
+		return GetApparatusesResponseApparatus{}, false
+	}
+	return r.apparatuses[i], true
+}
+
+func (r *Roster) lookupStation(index *map[string]int, key string) (GetStationsResponseStation, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	i, ok := (*index)[normalizeRosterKey(key)]
+	if !ok || i < 0 {
+		return GetStationsResponseStation{}, false
+	}
+	return r.stations[i], true
+}
+
+// addRosterKey adds a key to an index.  A key that is already in the index is ambiguous, so it is marked with -1.
+func addRosterKey(index map[string]int, kind string, key string, i int) {
+	key = normalizeRosterKey(key)
+	if key == "" {
+		return
+	}
+	previous, exists := index[key]
+	if !exists {
+		index[key] = i
+		return
+	}
+	if previous >= 0 {
+		slog.Warn("The roster has more than one entry with the same key, so the key is ambiguous and cannot be looked up.", "index", kind, "key", key)
+	}
+	index[key] = -1
+}
+
+// normalizeRosterKey returns the canonical form of a key for the roster indexes.
+func normalizeRosterKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
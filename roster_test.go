@@ -0,0 +1,97 @@
+package firstdue_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/tekkamanendless/firstdue"
+	"github.com/tekkamanendless/firstdue/mock"
+)
+
+func TestRosterRefresh(t *testing.T) {
+	server := httptest.NewServer(mock.NewServer(mock.Config{Fixtures: mock.DefaultFixtures(), Seed: 1}))
+	defer server.Close()
+	roster := firstdue.NewRoster(firstdue.NewClient(firstdue.WithBaseURL(server.URL), firstdue.WithToken("test")))
+	if !roster.LoadedAt().IsZero() {
+		t.Errorf("a new roster should not be loaded")
+	}
+	if err := roster.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if roster.LoadedAt().IsZero() || len(roster.Apparatuses()) != 5 || len(roster.Stations()) != 2 {
+		t.Fatalf("got %d apparatuses and %d stations", len(roster.Apparatuses()), len(roster.Stations()))
+	}
+	if apparatus, ok := roster.ApparatusByUnitCode("bc1"); !ok || apparatus.Name != "Battalion 1" {
+		t.Errorf("got %+v, %t", apparatus, ok)
+	}
+}
+
+func TestRosterLookups(t *testing.T) {
+	unitCode := func(value string) *string { return &value }
+	roster := firstdue.NewRoster(nil)
+	roster.Load([]firstdue.GetApparatusesResponseApparatus{
+		{UUID: "A-1", Name: "Engine 1", UnitCode: unitCode("E1")},
+		{UUID: "A-2", Name: "Ladder 2", UnitCode: unitCode(" L2 ")},
+		{UUID: "A-3", Name: "Medic 3", UnitCode: unitCode("M3")},
+		{UUID: "A-4", Name: "Medic 3 (reserve)", UnitCode: unitCode("m3")}, // The same unit code as A-3.
+		{UUID: "A-5", Name: "Utility", UnitCode: nil},
+		{UUID: "A-6", Name: "Engine 1", UnitCode: unitCode("")}, // The same name as A-1.
+	}, []firstdue.GetStationsResponseStation{
+		{UUID: "S-1", Name: "Station 1"},
+		{UUID: "S-2", Name: "Station 2"},
+	})
+
+	for _, test := range []struct {
+		name   string
+		lookup func() (firstdue.GetApparatusesResponseApparatus, bool)
+		want   string // The UUID, or empty if nothing should be found.
+	}{
+		{"unit code", func() (firstdue.GetApparatusesResponseApparatus, bool) { return roster.ApparatusByUnitCode("E1") }, "A-1"},
+		{"unit code case", func() (firstdue.GetApparatusesResponseApparatus, bool) { return roster.ApparatusByUnitCode(" e1") }, "A-1"},
+		{"unit code padded", func() (firstdue.GetApparatusesResponseApparatus, bool) { return roster.ApparatusByUnitCode("l2") }, "A-2"},
+		{"duplicate unit code", func() (firstdue.GetApparatusesResponseApparatus, bool) { return roster.ApparatusByUnitCode("M3") }, ""},
+		{"unknown unit code", func() (firstdue.GetApparatusesResponseApparatus, bool) { return roster.ApparatusByUnitCode("X9") }, ""},
+		{"empty unit code", func() (firstdue.GetApparatusesResponseApparatus, bool) { return roster.ApparatusByUnitCode("") }, ""},
+		{"UUID", func() (firstdue.GetApparatusesResponseApparatus, bool) { return roster.ApparatusByUUID("a-5") }, "A-5"},
+		{"name", func() (firstdue.GetApparatusesResponseApparatus, bool) { return roster.ApparatusByName("medic 3") }, "A-3"},
+		{"duplicate name", func() (firstdue.GetApparatusesResponseApparatus, bool) { return roster.ApparatusByName("Engine 1") }, ""},
+	} {
+		apparatus, ok := test.lookup()
+		if ok != (test.want != "") || apparatus.UUID != test.want {
+			t.Errorf("%s: got %q, %t; want %q", test.name, apparatus.UUID, ok, test.want)
+		}
+	}
+
+	if station, ok := roster.StationByName("STATION 2"); !ok || station.UUID != "S-2" {
+		t.Errorf("got %+v, %t", station, ok)
+	}
+	if station, ok := roster.StationByUUID("s-1"); !ok || station.Name != "Station 1" {
+		t.Errorf("got %+v, %t", station, ok)
+	}
+	if _, ok := roster.StationByName("Station 3"); ok {
+		t.Errorf("found an unknown station")
+	}
+
+	if got, want := roster.UnmappedUnitCodes([]string{"E1", "m3", "X9", "L2"}), []string{"m3", "X9"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got unmapped unit codes %q, want %q", got, want)
+	}
+}
+
+func TestRosterNotificationApparatus(t *testing.T) {
+	unitCode := "E1"
+	roster := firstdue.NewRoster(nil)
+	roster.Load([]firstdue.GetApparatusesResponseApparatus{{UUID: "A-1", UnitCode: &unitCode}}, nil)
+
+	// A known unit gets the roster's unit code.
+	apparatus, ok := roster.NotificationApparatus(" e1 ")
+	if !ok || apparatus != (firstdue.NfirsNotificationApparatus{UnitCode: "E1"}) {
+		t.Errorf("got %+v, %t", apparatus, ok)
+	}
+	// An unknown unit is left for the caller to decide about.
+	apparatus, ok = roster.NotificationApparatus(" MA7 ")
+	if ok || apparatus != (firstdue.NfirsNotificationApparatus{UnitCode: "MA7"}) {
+		t.Errorf("got %+v, %t", apparatus, ok)
+	}
+}
@@ -1,18 +1,29 @@
 package firstdue
 
 import (
+	"bytes"
+	"encoding"
 	"encoding/json"
+	"fmt"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/go-querystring/query"
 )
 
+// Timestamp is a time that is encoded as RFC 3339.
+//
+// The zero time is encoded as "", and "" or null decode as the zero time, so that a zero timestamp survives a round
+// trip through JSON.
 type Timestamp time.Time
 
 var _ json.Marshaler = (*Timestamp)(nil)
 var _ json.Unmarshaler = (*Timestamp)(nil)
 var _ query.Encoder = (*Timestamp)(nil)
+var _ encoding.TextMarshaler = (*Timestamp)(nil)
+var _ encoding.TextUnmarshaler = (*Timestamp)(nil)
 
 // IsZero returns true if the timestamp is the zero value.
 //
@@ -35,11 +46,18 @@ func (t Timestamp) MarshalJSON() ([]byte, error) {
 	return json.Marshal(s)
 }
 
+// UnmarshalJSON parses an RFC 3339 timestamp.
+//
+// An empty string or null is the zero value, which is how MarshalJSON encodes it.
 func (t *Timestamp) UnmarshalJSON(data []byte) error {
 	var s string
 	if err := json.Unmarshal(data, &s); err != nil {
 		return err
 	}
+	if s == "" {
+		*t = Timestamp{}
+		return nil
+	}
 	parsed, err := time.Parse(time.RFC3339, s)
 	if err != nil {
 		return err
@@ -48,6 +66,30 @@ func (t *Timestamp) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalText formats the timestamp as RFC 3339.
+//
+// This allows a timestamp to be used with text-based formats, such as YAML and command-line flags.
+func (t Timestamp) MarshalText() ([]byte, error) {
+	if t.IsZero() {
+		return []byte{}, nil
+	}
+	return []byte(time.Time(t).Format(time.RFC3339)), nil
+}
+
+// UnmarshalText parses an RFC 3339 timestamp.
+func (t *Timestamp) UnmarshalText(data []byte) error {
+	if len(data) == 0 {
+		*t = Timestamp{}
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339, string(data))
+	if err != nil {
+		return err
+	}
+	*t = Timestamp(parsed)
+	return nil
+}
+
 type ErrorResponse struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
@@ -57,3 +99,75 @@ type ErrorResponse struct {
 		Message string `json:"message"`
 	} `json:"errors,omitempty"`
 }
+
+// FlexibleFloat64 is a number that the API may encode either as a JSON number or as a string.
+//
+// It decodes a number, a string holding a JSON number, or an empty string (which leaves the value unchanged), and it always
+// encodes as a JSON number.  Use a pointer when a missing value, which the API sends as null, must be told from zero.
+//
+// An empty string is not null, though: it decodes into a pointer as a non-nil zero.  Callers that care, such as for
+// coordinates, should treat zero as missing.
+type FlexibleFloat64 float64
+
+var _ json.Marshaler = (*FlexibleFloat64)(nil)
+var _ json.Unmarshaler = (*FlexibleFloat64)(nil)
+
+func (f FlexibleFloat64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(float64(f))
+}
+
+func (f *FlexibleFloat64) UnmarshalJSON(data []byte) error {
+	s, err := flexibleNumber(data)
+	if err != nil || s == "" {
+		return err
+	}
+	// Parse the text as a JSON number; strconv.ParseFloat would also accept "NaN", "Inf", and hex floats like "0x1p-2".
+	var v float64
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return fmt.Errorf("invalid number %q", s)
+	}
+	*f = FlexibleFloat64(v)
+	return nil
+}
+
+// FlexibleInt64 is an integer that the API may encode either as a JSON number or as a string.
+//
+// It decodes like FlexibleFloat64, but the number must be whole.
+type FlexibleInt64 int64
+
+var _ json.Marshaler = (*FlexibleInt64)(nil)
+var _ json.Unmarshaler = (*FlexibleInt64)(nil)
+
+func (n FlexibleInt64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int64(n))
+}
+
+func (n *FlexibleInt64) UnmarshalJSON(data []byte) error {
+	s, err := flexibleNumber(data)
+	if err != nil || s == "" {
+		return err
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", s)
+	}
+	*n = FlexibleInt64(v)
+	return nil
+}
+
+// flexibleNumber returns the text of a number that is encoded either as a JSON number or as a string.  It returns ""
+// for null or an empty string.
+func flexibleNumber(data []byte) (string, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return "", nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(s), nil
+	}
+	return string(data), nil
+}
@@ -0,0 +1,68 @@
+package firstdue
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestFlexibleFloat64(t *testing.T) {
+	for input, want := range map[string]float64{
+		`12.5`:     12.5,
+		`"12.5"`:   12.5,
+		`" -3e2 "`: -300,
+		`"0"`:      0,
+	} {
+		var f FlexibleFloat64
+		if err := json.Unmarshal([]byte(input), &f); err != nil || float64(f) != want {
+			t.Errorf("%s: got %v (%v), want %v", input, f, err, want)
+		}
+	}
+	for _, input := range []string{`"NaN"`, `"nan"`, `"Inf"`, `"-Infinity"`, `"0x1p-2"`, `"1_000"`, `"1e400"`, `"12abc"`, `true`} {
+		var f FlexibleFloat64
+		if err := json.Unmarshal([]byte(input), &f); err == nil {
+			t.Errorf("%s: expected an error, got %v", input, f)
+		}
+	}
+}
+
+func TestFlexibleInt64(t *testing.T) {
+	for input, want := range map[string]int64{`42`: 42, `"42"`: 42, `"-7"`: -7} {
+		var n FlexibleInt64
+		if err := json.Unmarshal([]byte(input), &n); err != nil || int64(n) != want {
+			t.Errorf("%s: got %v (%v), want %v", input, n, err, want)
+		}
+	}
+	for _, input := range []string{`4.5`, `"4.5"`, `"0x10"`, `"1_000"`} {
+		var n FlexibleInt64
+		if err := json.Unmarshal([]byte(input), &n); err == nil {
+			t.Errorf("%s: expected an error, got %v", input, n)
+		}
+	}
+}
+
+func TestTimestampJSON(t *testing.T) {
+	var zero struct {
+		At Timestamp `json:"at"`
+	}
+	contents, err := json.Marshal(zero)
+	if err != nil || string(contents) != `{"at":""}` {
+		t.Fatalf("got %s (%v)", contents, err)
+	}
+	for _, input := range []string{`{"at":""}`, `{"at":null}`} {
+		zero.At = Timestamp(time.Now())
+		if err := json.Unmarshal([]byte(input), &zero); err != nil || !zero.At.IsZero() {
+			t.Errorf("%s: got %v (%v), want the zero time", input, time.Time(zero.At), err)
+		}
+	}
+
+	var ts Timestamp
+	if err := json.Unmarshal([]byte(`"2026-10-19T08:30:00-05:00"`), &ts); err != nil || !time.Time(ts).Equal(time.Date(2026, 10, 19, 13, 30, 0, 0, time.UTC)) {
+		t.Errorf("got %v (%v)", time.Time(ts), err)
+	}
+	for _, input := range []string{`" "`, `"2026-10-19"`, `"10/19/2026 08:30"`, `0`} {
+		if err := json.Unmarshal([]byte(input), &ts); err == nil {
+			t.Errorf("%s: expected an error, got %v", input, time.Time(ts))
+		}
+	}
+}
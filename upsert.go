@@ -0,0 +1,61 @@
+package firstdue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tekkamanendless/httperror"
+)
+
+// Upsert results.
+const (
+	UpsertCreated = "created"
+	UpsertUpdated = "updated"
+)
+
+// UpsertNfirsNotification creates the notification and its apparatuses, or updates them if a notification with
+// the same dispatch number already exists.
+//
+// Apparatuses are matched by unit code; an apparatus that is not on the existing notification is added.  Apparatuses
+// that are only on the existing notification are left alone.  The result is one of the Upsert* constants.
+func (c *Client) UpsertNfirsNotification(ctx context.Context, record NfirsNotificationRecord) (string, error) {
+	notification := record.Notification
+	notification.ID = 0
+	if notification.DispatchNumber == "" {
+		return "", fmt.Errorf("upsertnfirsnotification: the dispatch number is required")
+	}
+
+	existing, err := c.GetNfirsNotificationsDispatchNumberID(ctx, notification.DispatchNumber, GetNfirsNotificationsDispatchNumberIDRequest{})
+	if err != nil && !errors.Is(err, httperror.ErrStatusNotFound) {
+		return "", fmt.Errorf("upsertnfirsnotification: %w", err)
+	}
+	if err != nil {
+		output, err := c.PostNfirsNotifications(ctx, PostNfirsNotificationsRequest(notification))
+		if err != nil {
+			return "", fmt.Errorf("upsertnfirsnotification: %w", err)
+		}
+		for _, apparatus := range record.Apparatuses {
+			_, err := c.PostNfirsNotificationsIDApparatuses(ctx, uint64(output.ID), PostNfirsNotificationsIDApparatusesRequest(apparatus))
+			if err != nil {
+				return "", fmt.Errorf("upsertnfirsnotification: apparatus %s: %w", apparatus.UnitCode, err)
+			}
+		}
+		return UpsertCreated, nil
+	}
+
+	err = c.PutNfirsNotificationsID(ctx, existing.ID, PutNfirsNotificationsIDRequest(notification))
+	if err != nil {
+		return "", fmt.Errorf("upsertnfirsnotification: %w", err)
+	}
+	for _, apparatus := range record.Apparatuses {
+		err := c.PutNfirsNotificationsNumberIDApparatusesCodeID(ctx, notification.DispatchNumber, apparatus.UnitCode, PutNfirsNotificationsNumberIDApparatusesCodeIDRequest(apparatus))
+		if errors.Is(err, httperror.ErrStatusNotFound) {
+			_, err = c.PostNfirsNotificationsIDApparatuses(ctx, existing.ID, PostNfirsNotificationsIDApparatusesRequest(apparatus))
+		}
+		if err != nil {
+			return "", fmt.Errorf("upsertnfirsnotification: apparatus %s: %w", apparatus.UnitCode, err)
+		}
+	}
+	return UpsertUpdated, nil
+}